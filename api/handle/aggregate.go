@@ -0,0 +1,70 @@
+package handle
+
+import (
+	"errors"
+
+	"github.com/Madou-Shinni/gin-quickstart/common"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type AggregateHandle struct {
+	s *service.AggregateService
+}
+
+func NewAggregateHandle() *AggregateHandle {
+	return &AggregateHandle{s: service.NewAggregateService()}
+}
+
+// Aggregate 聚合统计
+// @Tags     Aggregate
+// @Summary  聚合统计
+// @Description 用于看板等场景，只能统计 service.RegisterAggregate 中注册的资源和字段，结果缓存1分钟，最多返回1000组
+// @Description 过滤条件与资源列表接口的查询参数一致，指标的key为 count、sum_字段，按时间分组的key为 bucket
+// @Description 可选资源：data_import(分组 status,category 指标 count,success_count,failure_count)、sys_user(分组 status,department,default_role)、sys_login_log(分组 login_type,result)、sys_operation_log(分组 method,path,code,user_id 指标 latency)
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    resource path     string true "资源名"
+// @Param    data     query    domain.AggregateReq true "聚合统计"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /aggregate/{resource} [get]
+func (cl *AggregateHandle) Aggregate(c *gin.Context) {
+	var req domain.AggregateReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	search, err := service.AggregateSearch(req.Resource)
+	if err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+		return
+	}
+	if err = c.ShouldBindQuery(search); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	rid, _ := common.GetRoleIdFromCtx(c)
+	res, err := cl.s.Aggregate(c.Request.Context(), rid, req, search)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrorAggregateDenied):
+			response.Error(c, constant.CODE_NO_PERMISSIONS, err.Error())
+		case errors.Is(err, service.ErrorAggregateNotSupported), errors.Is(err, service.ErrorAggregateField), errors.Is(err, service.ErrorAggregateMetric):
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+		default:
+			response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		}
+		return
+	}
+
+	response.Success(c, res)
+}
@@ -0,0 +1,134 @@
+package handle
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// exportContentTypes 导出文件的 Content-Type
+var exportContentTypes = map[string]string{
+	".xlsx": constant.ContentTypeXlsx,
+	".csv":  "text/csv; charset=utf-8",
+}
+
+type DataExportHandle struct {
+	s *service.DataExportService
+}
+
+func NewDataExportHandle() *DataExportHandle {
+	return &DataExportHandle{s: service.NewDataExportService()}
+}
+
+// dataExportHandle 列表接口的导出
+var dataExportHandle = NewDataExportHandle()
+
+// exportList 列表接口带 export 参数时调用，resource 为 service.RegisterExport 中注册的列表，search 为绑定后的查询结构体指针
+// 数据量小时直接返回文件，超过 export.async-threshold 时返回异步导出记录
+func exportList(c *gin.Context, resource string, search interface{}) {
+	res, err := dataExportHandle.s.Export(c.Request.Context(), resource, search, func(fileName string) io.Writer {
+		return &attachmentWriter{c: c, fileName: fileName}
+	})
+	if err != nil {
+		c.Error(err)
+		if c.Writer.Written() {
+			// 已经开始写入文件，无法再返回错误信息
+			return
+		}
+		if errors.Is(err, service.ErrorExportNotSupported) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrorExportNoActor) {
+			response.Error(c, constant.CODE_NO_PERMISSIONS, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+	if res != nil {
+		response.Success(c, res)
+	}
+}
+
+// attachmentWriter 第一次写入时才设置下载文件的响应头，写入之前查询失败时正常返回错误信息
+type attachmentWriter struct {
+	c        *gin.Context
+	fileName string
+	started  bool
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(w.fileName))
+		w.c.Header("Content-Type", exportContentTypes[filepath.Ext(w.fileName)])
+	}
+	return w.c.Writer.Write(p)
+}
+
+// List 查询当前用户的导出记录
+// @Tags     DataExport
+// @Summary  查询导出记录
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data query     domain.PageDataExportSearch true "查询导出记录"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /dataExport/list [get]
+func (cl *DataExportHandle) List(c *gin.Context) {
+	var dataExport domain.PageDataExportSearch
+	if err := c.ShouldBindQuery(&dataExport); err != nil {
+		c.Error(err)
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	res, err := cl.s.List(c.Request.Context(), dataExport)
+
+	if err != nil {
+		c.Error(err)
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
+
+// Download 下载导出的文件
+// @Tags     DataExport
+// @Summary  下载导出的文件
+// @Description 只能下载当前用户导出成功的文件
+// @Produce  application/octet-stream
+// @Security ApiKeyAuth
+// @Param    id path     int true "导出记录id"
+// @Success  200  {file} file "导出的文件"
+// @Router   /dataExport/{id}/download [get]
+func (cl *DataExportHandle) Download(c *gin.Context) {
+	var dataExport domain.DataExport
+	if err := c.ShouldBindUri(&dataExport); err != nil {
+		c.Error(err)
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	res, err := cl.s.Download(c.Request.Context(), dataExport)
+	if err != nil {
+		c.Error(err)
+		if errors.Is(err, service.ErrorExportNotFinished) {
+			response.Error(c, constant.CODE_FIND_FAILED, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	c.FileAttachment(res.FileUrl, res.FileName)
+}
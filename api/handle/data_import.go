@@ -2,6 +2,7 @@ package handle
 
 import (
 	"errors"
+	"github.com/Madou-Shinni/gin-quickstart/common"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
@@ -160,6 +161,8 @@ func (cl *DataImportHandle) Find(c *gin.Context) {
 // List 查询DataImport列表
 // @Tags     DataImport
 // @Summary  查询DataImport列表
+// @Description 排序(orderBy)可选字段：id、createdAt、status、category，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
@@ -173,6 +176,10 @@ func (cl *DataImportHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if dataImport.Export != "" {
+		exportList(c, "data_import", &dataImport)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), dataImport)
 
@@ -214,6 +221,7 @@ func (cl *DataImportHandle) Template(c *gin.Context) {
 // Import 导入数据
 // @Tags     DataImport
 // @Summary  导入数据
+// @Description file_url 为 /file/upload 上传 xlsx 文件返回的路径，由异步任务逐行解析，结果见导入记录
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
@@ -227,9 +235,14 @@ func (cl *DataImportHandle) Import(c *gin.Context) {
 		return
 	}
 
-	res, err := cl.s.Import(c.Request.Context(), DataImport)
+	rid, _ := common.GetRoleIdFromCtx(c)
+	res, err := cl.s.Import(c.Request.Context(), rid, DataImport)
 
 	if err != nil {
+		if errors.Is(err, service.ErrorImportDenied) {
+			response.Error(c, constant.CODE_NO_PERMISSIONS, err.Error())
+			return
+		}
 		response.Error(c, constant.CODE_ADD_FAILED, err.Error())
 		return
 	}
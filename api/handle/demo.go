@@ -5,6 +5,7 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
@@ -117,6 +118,15 @@ func (cl *DemoHandle) Update(c *gin.Context) {
 
 	if err := cl.s.Update(c.Request.Context(), demo); err != nil {
 		c.Error(err)
+		var conflict *model.VersionConflictError
+		if errors.As(err, &conflict) {
+			response.Conflict(c, conflict.Current)
+			return
+		}
+		if errors.Is(err, model.ErrMissingVersion) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
 		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
 		return
 	}
@@ -154,6 +164,9 @@ func (cl *DemoHandle) Find(c *gin.Context) {
 // List 查询Demo列表
 // @Tags     Demo
 // @Summary  查询Demo列表
+// @Description 排序(orderBy)可选字段：id、createdAt、updatedAt、name、age、birth_day，字段前加 - 为倒序
+// @Description 关键词(keyword)搜索字段：name
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Param    data query     domain.PageDemoSearch true "查询Demo列表"
@@ -166,6 +179,10 @@ func (cl *DemoHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if demo.Export != "" {
+		exportList(c, "demo", &demo)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), demo)
 
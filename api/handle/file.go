@@ -6,6 +6,7 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/snowflake"
@@ -219,6 +220,10 @@ func (cl *FileHandle) Update(c *gin.Context) {
 	}
 
 	if err := cl.s.Update(c.Request.Context(), file); err != nil {
+		if policy.IsInvalid(err) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
 		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
 		return
 	}
@@ -254,6 +259,8 @@ func (cl *FileHandle) Find(c *gin.Context) {
 // List 查询File列表
 // @Tags     File
 // @Summary  查询File列表
+// @Description 排序(orderBy)可选字段：id、fileName，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Param    data query     domain.File true "查询File列表"
@@ -265,6 +272,10 @@ func (cl *FileHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if file.Export != "" {
+		exportList(c, "file", &file)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), file)
 
@@ -1,65 +1,60 @@
 package handle
 
 import (
-	"fmt"
+	"errors"
+
+	"github.com/Madou-Shinni/gin-quickstart/common"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
-	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/gin-gonic/gin"
-	"strings"
 )
 
-type NoPageReq struct {
-	Table              string `json:"table" form:"table" uri:"table"`                                        // 表名
-	Keyword            string `json:"keyword" form:"keyword" uri:"keyword"`                                  // 关键词
-	Select             string `json:"select" form:"select" uri:"select"`                                     // 查询字段 逗号隔开
-	FindConditionsCols string `json:"findConditionsCols" form:"findConditionsCols" uri:"findConditionsCols"` // 查询条件字段 逗号隔开
+type NoPageHandle struct {
+	s *service.NoPageService
+}
+
+func NewNoPageHandle() *NoPageHandle {
+	return &NoPageHandle{s: service.NewNoPageService()}
 }
 
-// NoPage 不分页查询
+// List 不分页查询
 // @Tags     NoPage
-// @Summary  查询NoPage
+// @Summary  不分页查询
+// @Description 用于下拉框等场景，只能查询 service.RegisterNoPage 中注册的资源和字段，最多返回1000条
+// @Description 可选资源：demo(id,name)、sys_user(id,account,nick_name,department)、sys_role(id,parent_id,role_name)、sys_menu(id,parent_id,name)
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
-// @Param    data query     NoPageReq true "不分页查询"
+// @Param    table path     string true "资源名"
+// @Param    data  query    domain.NoPageReq true "不分页查询"
 // @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
 // @Router   /noPage/{table} [get]
-func NoPage(c *gin.Context) {
-	var (
-		req     NoPageReq
-		selects = []string{"id", "name"}
-		ctx     = c.Request.Context()
-		count   int64
-		list    = make([]map[string]interface{}, 0)
-	)
-	if err := c.ShouldBindQuery(&req); err != nil {
-		c.Error(err)
-		response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+func (cl *NoPageHandle) List(c *gin.Context) {
+	var req domain.NoPageReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
-
-	db := global.DB.WithContext(ctx).Table(req.Table)
-
-	req.Table = c.Param("table")
-	if req.Select != "" {
-		selects = strings.Split(req.Select, ",")
-	}
-	if req.FindConditionsCols != "" {
-		slices := strings.Split(req.FindConditionsCols, ",")
-		for _, v := range slices {
-			db = db.Where(fmt.Sprintf("%s LIKE ?", v), "%"+req.Keyword+"%")
-		}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
 	}
 
-	err := db.Table(req.Table).Select(selects).Count(&count).Find(&list).Error
+	rid, _ := common.GetRoleIdFromCtx(c)
+	res, err := cl.s.List(c.Request.Context(), rid, req)
 	if err != nil {
-		response.Error(c, constant.CODE_ERR_BUSY, err.Error())
+		switch {
+		case errors.Is(err, service.ErrorNoPageDenied):
+			response.Error(c, constant.CODE_NO_PERMISSIONS, err.Error())
+		case errors.Is(err, service.ErrorNoPageNotSupported), errors.Is(err, service.ErrorNoPageField):
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+		default:
+			response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		}
 		return
 	}
 
-	response.Success(c, response.PageResponse{
-		Total: count,
-		List:  list,
-	})
+	response.Success(c, res)
 }
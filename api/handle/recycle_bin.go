@@ -0,0 +1,131 @@
+package handle
+
+import (
+	"errors"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type RecycleBinHandle struct {
+	s *service.RecycleBinService
+}
+
+func NewRecycleBinHandle() *RecycleBinHandle {
+	return &RecycleBinHandle{s: service.NewRecycleBinService()}
+}
+
+// List 查询回收站
+// @Tags     RecycleBin
+// @Summary  查询回收站
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    domain path     string true "表名 demo、sys_user、sys_role、sys_menu、data_import"
+// @Param    data   query    request.PageSearch false "分页"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /recycleBin/{domain}/list [get]
+func (cl *RecycleBinHandle) List(c *gin.Context) {
+	var page domain.PageRecycleBinSearch
+	if err := c.ShouldBindUri(&page); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if err := c.ShouldBindQuery(&page.PageSearch); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	res, err := cl.s.List(c.Request.Context(), page)
+	if err != nil {
+		if errors.Is(err, service.ErrorRecycleBinNotSupported) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
+
+// Restore 恢复回收站中的数据
+// @Tags     RecycleBin
+// @Summary  恢复回收站中的数据
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    domain path     string true "表名 demo、sys_user、sys_role、sys_menu、data_import"
+// @Param    data   body     domain.RecycleBinReq true "数据id"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /recycleBin/{domain}/restore [put]
+func (cl *RecycleBinHandle) Restore(c *gin.Context) {
+	req, ok := bindRecycleBinReq(c)
+	if !ok {
+		return
+	}
+
+	count, err := cl.s.Restore(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrorRecycleBinNotSupported) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, count)
+}
+
+// Purge 彻底删除回收站中的数据
+// @Tags     RecycleBin
+// @Summary  彻底删除回收站中的数据
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    domain path     string true "表名 demo、sys_user、sys_role、sys_menu、data_import"
+// @Param    data   body     domain.RecycleBinReq true "数据id"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /recycleBin/{domain}/purge [delete]
+func (cl *RecycleBinHandle) Purge(c *gin.Context) {
+	req, ok := bindRecycleBinReq(c)
+	if !ok {
+		return
+	}
+
+	count, err := cl.s.Purge(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrorRecycleBinNotSupported) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_DELETE_FAILED, constant.CODE_DELETE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, count)
+}
+
+func bindRecycleBinReq(c *gin.Context) (domain.RecycleBinReq, bool) {
+	var req domain.RecycleBinReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return req, false
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var errs validator.ValidationErrors
+		if errors.As(err, &errs) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, tools.TransErrs(errs))
+			return req, false
+		}
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return req, false
+	}
+	return req, true
+}
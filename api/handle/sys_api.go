@@ -5,6 +5,7 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
@@ -114,6 +115,10 @@ func (cl *SysApiHandle) Update(c *gin.Context) {
 	}
 
 	if err := cl.s.Update(c.Request.Context(), sysApi); err != nil {
+		if policy.IsInvalid(err) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
 		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
 		return
 	}
@@ -150,6 +155,8 @@ func (cl *SysApiHandle) Find(c *gin.Context) {
 // List 查询SysApi列表
 // @Tags     SysApi
 // @Summary  查询SysApi列表
+// @Description 排序(orderBy)可选字段：id、createdAt、name、method、path，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
@@ -162,6 +169,10 @@ func (cl *SysApiHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if sysApi.Export != "" {
+		exportList(c, "sys_api", &sysApi)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), sysApi)
 
@@ -98,30 +98,6 @@ func (cl *SysCasbinHandle) DeleteByIds(c *gin.Context) {
 	response.Success(c)
 }
 
-// Update 修改SysCasbin
-// @Tags     SysCasbin
-// @Summary  修改SysCasbin
-// @accept   application/json
-// @Produce  application/json
-// @Security ApiKeyAuth
-// @Param    data body     domain.SysCasbin true "修改SysCasbin"
-// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
-// @Router   /sysCasbin [put]
-func (cl *SysCasbinHandle) Update(c *gin.Context) {
-	var sysCasbin map[string]interface{}
-	if err := c.ShouldBindJSON(&sysCasbin); err != nil {
-		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
-		return
-	}
-
-	if err := cl.s.Update(c.Request.Context(), sysCasbin); err != nil {
-		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
-		return
-	}
-
-	response.Success(c)
-}
-
 // Find 查询SysCasbin
 // @Tags     SysCasbin
 // @Summary  查询SysCasbin
@@ -0,0 +1,59 @@
+package handle
+
+import (
+	"errors"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type SysChangeHistoryHandle struct {
+	s *service.SysChangeHistoryService
+}
+
+func NewSysChangeHistoryHandle() *SysChangeHistoryHandle {
+	return &SysChangeHistoryHandle{s: service.NewSysChangeHistoryService()}
+}
+
+// List 查询记录的变更历史
+// @Tags     SysChangeHistory
+// @Summary  查询记录的变更历史
+// @Description 导出(export)：xlsx、csv，导出该记录全部的变更历史，数量较多时异步导出并返回导出记录
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    table path     string true "表名 demo、sys_user"
+// @Param    id    path     string true "记录id"
+// @Param    data  query    request.PageSearch false "分页"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /history/{table}/{id} [get]
+func (cl *SysChangeHistoryHandle) List(c *gin.Context) {
+	var page domain.PageSysChangeHistorySearch
+	if err := c.ShouldBindUri(&page); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if err := c.ShouldBindQuery(&page.PageSearch); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if page.Export != "" {
+		exportList(c, "sys_change_history", &page)
+		return
+	}
+
+	res, err := cl.s.List(c.Request.Context(), page)
+	if err != nil {
+		if errors.Is(err, service.ErrorHistoryNotSupported) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
@@ -0,0 +1,57 @@
+package handle
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type SysLoginLogHandle struct {
+	s *service.SysLoginLogService
+}
+
+func NewSysLoginLogHandle() *SysLoginLogHandle {
+	return &SysLoginLogHandle{s: service.NewSysLoginLogService()}
+}
+
+// List 分页查询SysLoginLog
+// @Tags     SysLoginLog
+// @Summary  分页查询SysLoginLog
+// @Description 排序(orderBy)可选字段：id、createdAt、account、result，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data query     domain.PageSysLoginLogSearch true "分页查询SysLoginLog"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /sysLoginLog/list [get]
+func (cl *SysLoginLogHandle) List(c *gin.Context) {
+	var sysLoginLog domain.PageSysLoginLogSearch
+	if err := c.ShouldBindQuery(&sysLoginLog); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if sysLoginLog.Export != "" {
+		exportList(c, "sys_login_log", &sysLoginLog)
+		return
+	}
+
+	res, err := cl.s.List(c.Request.Context(), sysLoginLog)
+
+	if err != nil {
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
+
+// loginClient 登录客户端信息，各登录方式(密码、短信、第三方、二次验证)的接口通过它填充 domain.LoginEvent
+func loginClient(c *gin.Context) domain.LoginClient {
+	return domain.LoginClient{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
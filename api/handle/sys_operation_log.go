@@ -0,0 +1,75 @@
+package handle
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type SysOperationLogHandle struct {
+	s *service.SysOperationLogService
+}
+
+func NewSysOperationLogHandle() *SysOperationLogHandle {
+	return &SysOperationLogHandle{s: service.NewSysOperationLogService()}
+}
+
+// Find 查询SysOperationLog
+// @Tags     SysOperationLog
+// @Summary  查询SysOperationLog
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    id path     uint true "查询SysOperationLog"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /sysOperationLog/{id} [get]
+func (cl *SysOperationLogHandle) Find(c *gin.Context) {
+	var sysOperationLog domain.SysOperationLog
+	if err := c.ShouldBindUri(&sysOperationLog); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	res, err := cl.s.Find(c.Request.Context(), sysOperationLog)
+
+	if err != nil {
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
+
+// List 分页查询SysOperationLog
+// @Tags     SysOperationLog
+// @Summary  分页查询SysOperationLog
+// @Description 排序(orderBy)可选字段：id、createdAt、code、latency，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data query     domain.PageSysOperationLogSearch true "分页查询SysOperationLog"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /sysOperationLog/list [get]
+func (cl *SysOperationLogHandle) List(c *gin.Context) {
+	var sysOperationLog domain.PageSysOperationLogSearch
+	if err := c.ShouldBindQuery(&sysOperationLog); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if sysOperationLog.Export != "" {
+		exportList(c, "sys_operation_log", &sysOperationLog)
+		return
+	}
+
+	res, err := cl.s.List(c.Request.Context(), sysOperationLog)
+
+	if err != nil {
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
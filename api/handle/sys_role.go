@@ -6,6 +6,8 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
@@ -115,6 +117,15 @@ func (cl *SysRoleHandle) Update(c *gin.Context) {
 	}
 
 	if err := cl.s.Update(c.Request.Context(), sysRole); err != nil {
+		if policy.IsInvalid(err) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		var conflict *model.VersionConflictError
+		if errors.As(err, &conflict) {
+			response.Conflict(c, conflict.Current)
+			return
+		}
 		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
 		return
 	}
@@ -151,6 +162,8 @@ func (cl *SysRoleHandle) Find(c *gin.Context) {
 // List 查询SysRole列表
 // @Tags     SysRole
 // @Summary  查询SysRole列表
+// @Description 排序(orderBy)可选字段：id、createdAt、role_name，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
@@ -163,6 +176,10 @@ func (cl *SysRoleHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if sysRole.Export != "" {
+		exportList(c, "sys_role", &sysRole)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), sysRole)
 
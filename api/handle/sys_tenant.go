@@ -0,0 +1,214 @@
+package handle
+
+import (
+	"errors"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SysTenantHandle struct {
+	s *service.SysTenantService
+}
+
+func NewSysTenantHandle() *SysTenantHandle {
+	return &SysTenantHandle{s: service.NewSysTenantService()}
+}
+
+// Add 创建SysTenant
+// @Tags     SysTenant
+// @Summary  创建SysTenant
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     domain.AddSysTenantReq true "创建SysTenant"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysTenant [post]
+func (cl *SysTenantHandle) Add(c *gin.Context) {
+	var req domain.AddSysTenantReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var errs validator.ValidationErrors
+		if errors.As(err, &errs) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, tools.TransErrs(errs))
+			return
+		}
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.Add(c.Request.Context(), req); err != nil {
+		if errors.Is(err, service.ErrorTenantExist) {
+			response.Error(c, constant.CODE_ADD_FAILED, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_ADD_FAILED, constant.CODE_ADD_FAILED.Msg())
+		return
+	}
+
+	response.Success(c)
+}
+
+// Delete 删除SysTenant
+// @Tags     SysTenant
+// @Summary  删除SysTenant
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     domain.SysTenant true "删除SysTenant"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysTenant [delete]
+func (cl *SysTenantHandle) Delete(c *gin.Context) {
+	var sysTenant domain.SysTenant
+	if err := c.ShouldBindJSON(&sysTenant); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.Delete(c.Request.Context(), sysTenant); err != nil {
+		response.Error(c, constant.CODE_DELETE_FAILED, constant.CODE_DELETE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c)
+}
+
+// DeleteByIds 批量删除SysTenant
+// @Tags     SysTenant
+// @Summary  批量删除SysTenant
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     request.Ids true "批量删除SysTenant"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysTenant/delete-batch [delete]
+func (cl *SysTenantHandle) DeleteByIds(c *gin.Context) {
+	var ids request.Ids
+	if err := c.ShouldBindJSON(&ids); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.DeleteByIds(c.Request.Context(), ids); err != nil {
+		response.Error(c, constant.CODE_DELETE_FAILED, constant.CODE_DELETE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c)
+}
+
+// Update 修改SysTenant(名称、套餐、状态、过期时间)
+// @Tags     SysTenant
+// @Summary  修改SysTenant
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     domain.SysTenant true "修改SysTenant"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysTenant [put]
+func (cl *SysTenantHandle) Update(c *gin.Context) {
+	var sysTenant map[string]interface{}
+	if err := c.ShouldBindJSON(&sysTenant); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.Update(c.Request.Context(), sysTenant); err != nil {
+		if policy.IsInvalid(err) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
+		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c)
+}
+
+// Provision 为租户分配独立数据库
+// @Tags     SysTenant
+// @Summary  分配租户独立数据库
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     domain.ProvisionSysTenantReq true "分配租户独立数据库"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysTenant/provision [put]
+func (cl *SysTenantHandle) Provision(c *gin.Context) {
+	var req domain.ProvisionSysTenantReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.Provision(c.Request.Context(), req); err != nil {
+		response.Error(c, constant.CODE_UPDATE_FAILED, err.Error())
+		return
+	}
+
+	response.Success(c)
+}
+
+// Find 查询SysTenant
+// @Tags     SysTenant
+// @Summary  查询SysTenant
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    id path     uint true "查询SysTenant"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /sysTenant/{id} [get]
+func (cl *SysTenantHandle) Find(c *gin.Context) {
+	var sysTenant domain.SysTenant
+	if err := c.ShouldBindUri(&sysTenant); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	res, err := cl.s.Find(c.Request.Context(), sysTenant)
+
+	if err != nil {
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
+
+// List 查询SysTenant列表
+// @Tags     SysTenant
+// @Summary  查询SysTenant列表
+// @Description 排序(orderBy)可选字段：id、createdAt、name、code、plan、status、expired_at，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data query     domain.PageSysTenantSearch true "查询SysTenant列表"
+// @Success  200  {string} string            "{"code":200,"msg":"查询成功","data":{}"}"
+// @Router   /sysTenant/list [get]
+func (cl *SysTenantHandle) List(c *gin.Context) {
+	var sysTenant domain.PageSysTenantSearch
+	if err := c.ShouldBindQuery(&sysTenant); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+	if sysTenant.Export != "" {
+		exportList(c, "sys_tenant", &sysTenant)
+		return
+	}
+
+	res, err := cl.s.List(c.Request.Context(), sysTenant)
+
+	if err != nil {
+		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
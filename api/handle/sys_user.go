@@ -2,11 +2,13 @@ package handle
 
 import (
 	"errors"
+
 	"github.com/Madou-Shinni/gin-quickstart/common"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
@@ -116,6 +118,10 @@ func (cl *SysUserHandle) Update(c *gin.Context) {
 	}
 
 	if err := cl.s.Update(c.Request.Context(), sysUser); err != nil {
+		if policy.IsInvalid(err) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, err.Error())
+			return
+		}
 		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
 		return
 	}
@@ -152,6 +158,9 @@ func (cl *SysUserHandle) Find(c *gin.Context) {
 // List 查询SysUser列表
 // @Tags     SysUser
 // @Summary  查询SysUser列表
+// @Description 排序(orderBy)可选字段：id、createdAt、account、nick_name、department、status、expired_at，字段前加 - 为倒序
+// @Description 关键词(keyword)搜索字段：account、nick_name
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
@@ -164,6 +173,10 @@ func (cl *SysUserHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if sysUser.Export != "" {
+		exportList(c, "sys_user", &sysUser)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), sysUser)
 
@@ -196,6 +209,8 @@ func (cl *SysUserHandle) Login(c *gin.Context) {
 		return
 	}
 
+	sysUser.LoginClient = loginClient(c)
+
 	res, err := cl.s.Login(c.Request.Context(), sysUser)
 	if err != nil {
 		response.Error(c, constant.CODE_FIND_FAILED, err.Error())
@@ -220,7 +235,7 @@ func (cl *SysUserHandle) Info(c *gin.Context) {
 		return
 	}
 
-	find, err := cl.s.Find(c.Request.Context(), domain.SysUser{Model: model.Model{ID: uid}})
+	find, err := cl.s.Find(c.Request.Context(), domain.SysUser{AuditModel: model.AuditModel{Model: model.Model{ID: uid}}})
 	if err != nil {
 		response.Error(c, constant.CODE_FIND_FAILED, constant.CODE_FIND_FAILED.Msg())
 		return
@@ -229,3 +244,81 @@ func (cl *SysUserHandle) Info(c *gin.Context) {
 	find.Password = ""
 	response.Success(c, find)
 }
+
+// Enable 批量启用SysUser
+// @Tags     SysUser
+// @Summary  批量启用SysUser
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     request.Ids true "批量启用SysUser"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysUser/enable-batch [put]
+func (cl *SysUserHandle) Enable(c *gin.Context) {
+	var ids request.Ids
+	if err := c.ShouldBindJSON(&ids); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.Enable(c.Request.Context(), ids); err != nil {
+		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c)
+}
+
+// Disable 批量禁用SysUser
+// @Tags     SysUser
+// @Summary  批量禁用SysUser
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     request.Ids true "批量禁用SysUser"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysUser/disable-batch [put]
+func (cl *SysUserHandle) Disable(c *gin.Context) {
+	var ids request.Ids
+	if err := c.ShouldBindJSON(&ids); err != nil {
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	if err := cl.s.Disable(c.Request.Context(), ids); err != nil {
+		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c)
+}
+
+// ResetPassword 批量重置SysUser密码
+// @Tags     SysUser
+// @Summary  批量重置SysUser密码
+// @accept   application/json
+// @Produce  application/json
+// @Security ApiKeyAuth
+// @Param    data body     domain.ResetPasswordReq true "批量重置SysUser密码"
+// @Success  200  {string} string            "{"code":200,"msg":"","data":{}"}"
+// @Router   /sysUser/reset-password-batch [put]
+func (cl *SysUserHandle) ResetPassword(c *gin.Context) {
+	var req domain.ResetPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var errs validator.ValidationErrors
+		if errors.As(err, &errs) {
+			response.Error(c, constant.CODE_INVALID_PARAMETER, tools.TransErrs(errs))
+			return
+		}
+		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
+		return
+	}
+
+	res, err := cl.s.ResetPassword(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, constant.CODE_UPDATE_FAILED, constant.CODE_UPDATE_FAILED.Msg())
+		return
+	}
+
+	response.Success(c, res)
+}
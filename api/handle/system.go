@@ -7,6 +7,7 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -22,9 +23,10 @@ var (
 		RoleName: "超级管理员",
 	}
 	defaultUser = domain.SysUser{
-		Account:  "admin",
-		Password: string(hashPwd),
-		NickName: "超级管理员",
+		Account:    "admin",
+		Password:   string(hashPwd),
+		NickName:   "超级管理员",
+		SuperAdmin: true, // 角色、权限、租户等平台管理只允许超级管理员访问
 		Roles: []domain.SysRole{
 			defaultRole,
 		},
@@ -53,12 +55,14 @@ func NewSystemHandle() *SystemHandle {
 func (cl *SystemHandle) Init(c *gin.Context) {
 	var err error
 	var count int64
-	global.DB.WithContext(c.Request.Context()).Model(&domain.SysRole{}).Count(&count)
+	// 初始化时还没有用户和租户，管理员是平台用户
+	ctx := gorm_plugin.WithTenantBypass(c.Request.Context())
+	global.DB.WithContext(ctx).Model(&domain.SysRole{}).Count(&count)
 	if count > 0 {
 		response.Error(c, constant.CODE_ADD_FAILED, "已完成初始化，请勿重复")
 		return
 	}
-	err = global.DB.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+	err = global.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 添加管理员
 		err = tx.Model(&domain.SysUser{}).Create(&defaultUser).Error
 		if err != nil {
@@ -71,7 +75,7 @@ func (cl *SystemHandle) Init(c *gin.Context) {
 			return err
 		}
 		// 添加管理员权限
-		err = casbinService.AddUserRoles(c.Request.Context(), domain.UserRolesReq{
+		err = casbinService.AddUserRoles(ctx, domain.UserRolesReq{
 			UserID: defaultUser.ID,
 			Roles:  []uint{defaultUser.DefaultRole},
 		})
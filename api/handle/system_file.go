@@ -253,6 +253,8 @@ func (cl *SystemFileHandle) Find(c *gin.Context) {
 // List 查询SystemFile列表
 // @Tags     SystemFile
 // @Summary  查询SystemFile列表
+// @Description 排序(orderBy)可选字段：id、file_name、size，字段前加 - 为倒序
+// @Description 导出(export)：xlsx、csv，按相同的查询条件导出全部数据，数量较多时异步导出并返回导出记录
 // @accept   application/json
 // @Produce  application/json
 // @Security ApiKeyAuth
@@ -266,6 +268,10 @@ func (cl *SystemFileHandle) List(c *gin.Context) {
 		response.Error(c, constant.CODE_INVALID_PARAMETER, constant.CODE_INVALID_PARAMETER.Msg())
 		return
 	}
+	if systemFile.Export != "" {
+		exportList(c, "system_file", &systemFile)
+		return
+	}
 
 	res, err := cl.s.List(c.Request.Context(), systemFile)
 
@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var aggregateHandle = handle.NewAggregateHandle()
+
+// 注册路由
+func AggregateRouterRegister(r *gin.RouterGroup) {
+	aggregateGroup := r.Group("aggregate")
+	{
+		aggregateGroup.GET("/:resource", aggregateHandle.Aggregate)
+	}
+}
@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var dataExportHandle = handle.NewDataExportHandle()
+
+// 注册路由
+func DataExportRouterRegister(r *gin.RouterGroup) {
+	dataExportGroup := r.Group("dataExport")
+	{
+		dataExportGroup.GET("/list", dataExportHandle.List)
+		dataExportGroup.GET("/:id/download", dataExportHandle.Download)
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 var fileHandle = handle.NewFileHandle()
 
-func FileRouterRegister(r *gin.Engine) {
+func FileRouterRegister(r *gin.RouterGroup) {
 	fileGroup := r.Group("file")
 	{
 		fileGroup.POST("", fileHandle.Add)
@@ -5,9 +5,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+var noPageHandle = handle.NewNoPageHandle()
+
+// 注册路由
 func NoPageRouterRegister(r *gin.RouterGroup) {
-	demoGroup := r.Group("noPage")
+	noPageGroup := r.Group("noPage")
 	{
-		demoGroup.GET("/:table", handle.NoPage)
+		noPageGroup.GET("/:table", noPageHandle.List)
 	}
 }
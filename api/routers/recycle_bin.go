@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var recycleBinHandle = handle.NewRecycleBinHandle()
+
+// 注册路由
+func RecycleBinRouterRegister(r *gin.RouterGroup) {
+	recycleBinGroup := r.Group("recycleBin")
+	{
+		recycleBinGroup.GET("/:domain/list", recycleBinHandle.List)
+		recycleBinGroup.PUT("/:domain/restore", recycleBinHandle.Restore)
+		recycleBinGroup.DELETE("/:domain/purge", recycleBinHandle.Purge)
+	}
+}
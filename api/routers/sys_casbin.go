@@ -16,6 +16,5 @@ func SysCasbinRouterRegister(r *gin.RouterGroup) {
 		sysCasbinGroup.DELETE("/delete-batch", sysCasbinHandle.DeleteByIds)
 		sysCasbinGroup.GET("/:id", sysCasbinHandle.Find)
 		sysCasbinGroup.GET("/list", sysCasbinHandle.List)
-		sysCasbinGroup.PUT("", sysCasbinHandle.Update)
 	}
 }
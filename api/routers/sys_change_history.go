@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var sysChangeHistoryHandle = handle.NewSysChangeHistoryHandle()
+
+// 注册路由
+func SysChangeHistoryRouterRegister(r *gin.RouterGroup) {
+	historyGroup := r.Group("history")
+	{
+		historyGroup.GET("/:table/:id", sysChangeHistoryHandle.List)
+	}
+}
@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var sysLoginLogHandle = handle.NewSysLoginLogHandle()
+
+// 注册路由
+func SysLoginLogRouterRegister(r *gin.RouterGroup) {
+	sysLoginLogGroup := r.Group("sysLoginLog")
+	{
+		sysLoginLogGroup.GET("/list", sysLoginLogHandle.List)
+	}
+}
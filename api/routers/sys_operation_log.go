@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var sysOperationLogHandle = handle.NewSysOperationLogHandle()
+
+// 注册路由
+func SysOperationLogRouterRegister(r *gin.RouterGroup) {
+	sysOperationLogGroup := r.Group("sysOperationLog")
+	{
+		sysOperationLogGroup.GET("/:id", sysOperationLogHandle.Find)
+		sysOperationLogGroup.GET("/list", sysOperationLogHandle.List)
+	}
+}
@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/api/handle"
+	"github.com/gin-gonic/gin"
+)
+
+var sysTenantHandle = handle.NewSysTenantHandle()
+
+// 注册路由
+// 租户管理只允许超级管理员(sys_user.super_admin)访问，路由组需要使用 middleware.SuperAdmin
+func SysTenantRouterRegister(r *gin.RouterGroup) {
+	sysTenantGroup := r.Group("sysTenant")
+	{
+		sysTenantGroup.POST("", sysTenantHandle.Add)
+		sysTenantGroup.DELETE("", sysTenantHandle.Delete)
+		sysTenantGroup.DELETE("/delete-batch", sysTenantHandle.DeleteByIds)
+		sysTenantGroup.GET("/:id", sysTenantHandle.Find)
+		sysTenantGroup.GET("/list", sysTenantHandle.List)
+		sysTenantGroup.PUT("", sysTenantHandle.Update)
+		sysTenantGroup.PUT("/provision", sysTenantHandle.Provision)
+	}
+}
@@ -10,7 +10,7 @@ var sysUserHandle = handle.NewSysUserHandle()
 
 // 注册路由
 func SysUserRouterRegister(r *gin.RouterGroup) {
-	sysUserGroup := r.Group("sysUser", middleware.JwtAuth(), middleware.CasbinHandler())
+	sysUserGroup := r.Group("sysUser", middleware.JwtAuth(), middleware.Tenant(), middleware.SharedDB(), middleware.CasbinHandler())
 	{
 		sysUserGroup.POST("", sysUserHandle.Add)
 		sysUserGroup.DELETE("", sysUserHandle.Delete)
@@ -19,6 +19,9 @@ func SysUserRouterRegister(r *gin.RouterGroup) {
 		sysUserGroup.GET("/list", sysUserHandle.List)
 		sysUserGroup.GET("/info", sysUserHandle.Info)
 		sysUserGroup.PUT("", sysUserHandle.Update)
+		sysUserGroup.PUT("/enable-batch", sysUserHandle.Enable)
+		sysUserGroup.PUT("/disable-batch", sysUserHandle.Disable)
+		sysUserGroup.PUT("/reset-password-batch", sysUserHandle.ResetPassword)
 	}
 
 	sysUserGroupNoAuth := r.Group("sysUser")
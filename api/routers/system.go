@@ -8,10 +8,15 @@ import (
 var systemHandle = handle.NewSystemHandle()
 
 // 注册路由
-func SystemRouterRegister(r *gin.RouterGroup) {
-	systemGroup := r.Group("system")
+// 初始化时还没有用户，不需要登录；监控只允许超级管理员访问
+func SystemRouterRegister(public, platform *gin.RouterGroup) {
+	systemGroup := public.Group("system")
 	{
 		systemGroup.POST("init", systemHandle.Init)
-		systemGroup.GET("monitor", systemHandle.MonitorState)
+	}
+
+	systemPlatformGroup := platform.Group("system")
+	{
+		systemPlatformGroup.GET("monitor", systemHandle.MonitorState)
 	}
 }
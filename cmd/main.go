@@ -24,6 +24,8 @@ import (
 //go:generate swag initialize --output ../docs
 
 func main() {
+	// 数据库迁移
+	initialize.Migrate()
 	// 启动服务(使用goroutine解决服务启动时程序阻塞问题)
 	go route.RunServer()
 	go job.RunConsumer()
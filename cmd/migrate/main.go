@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/initialize"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/migrations"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"github.com/spf13/pflag"
+)
+
+const usage = `数据库迁移
+使用方式：
+  go run cmd/migrate/main.go [-c configPath] up [n]    执行未执行的迁移，n为执行个数，默认全部
+  go run cmd/migrate/main.go [-c configPath] down [n]  回滚最近执行的迁移，n为回滚个数，默认1个
+  go run cmd/migrate/main.go [-c configPath] status    查看迁移状态
+  go run cmd/migrate/main.go [-c configPath] tenants up|down|status [n]  对所有租户的独立数据库执行
+  go run cmd/migrate/main.go create name               在 migrations/sql 下创建sql迁移文件`
+
+func main() {
+	// 配置文件参数在 initialize 中解析
+	args := pflag.Args()
+	if len(args) == 0 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "create":
+		if len(args) < 2 {
+			log.Fatalln("missing migration name")
+		}
+		files, err := migrate.Create(migrations.SQLDir, args[1])
+		if err != nil {
+			log.Fatalf("create failed: %v", err)
+		}
+		for _, file := range files {
+			log.Printf("created %s\n", file)
+		}
+	case "up", "down", "status":
+		m, err := initialize.NewMigrator()
+		if err != nil {
+			log.Fatalf("migrate init failed: %v", err)
+		}
+		if err = run(ctx, m, args); err != nil {
+			log.Fatalln(err)
+		}
+	case "tenants":
+		if len(args) < 2 {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		err := initialize.EachTenantMigrator(ctx, func(tenant domain.SysTenant, m *migrate.Migrator) error {
+			log.Printf("tenant %s\n", tenant.Code)
+			return run(ctx, m, args[1:])
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+}
+
+// run 执行 up、down、status
+func run(ctx context.Context, m *migrate.Migrator, args []string) error {
+	switch args[0] {
+	case "up", "down":
+		n, err := parseN(args)
+		if err != nil {
+			return err
+		}
+		var versions []string
+		if args[0] == "up" {
+			versions, err = m.Up(ctx, n)
+		} else {
+			versions, err = m.Down(ctx, n)
+		}
+		for _, version := range versions {
+			log.Printf("migrate %s %s\n", args[0], version)
+		}
+		if errors.Is(err, migrate.ErrNoChange) {
+			log.Println("no change")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("migrate %s failed: %w", args[0], err)
+		}
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate status failed: %w", err)
+		}
+		printStatus(status)
+	default:
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+	return nil
+}
+
+func parseN(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid n: %s", args[1])
+	}
+	return n, nil
+}
+
+func printStatus(status []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range status {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.DateTime)
+		}
+		if s.Missing {
+			appliedAt += " (missing)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Madou-Shinni/gin-quickstart/initialize"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/search"
+	"github.com/spf13/pflag"
+)
+
+const usage = `搜索引擎全量同步
+使用方式：
+  go run cmd/search/main.go [-c configPath] reindex [table...]    同步共享库和所有租户独立数据库中的数据，table为空时同步全部注册的表`
+
+const batchSize = 500
+
+func main() {
+	// 配置文件参数在 initialize 中解析
+	args := pflag.Args()
+	if len(args) == 0 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	if !search.Indexing() {
+		log.Println("当前搜索引擎不需要同步数据")
+		return
+	}
+
+	ctx := context.Background()
+	s := service.NewSearchService()
+	if args[0] != "reindex" {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	reindex(gorm_plugin.WithTenantBypass(ctx), s, "shared", args[1:])
+	// 租户独立数据库的数据同步到租户自己的索引
+	if _, err := global.DB.Tenants(); err == nil {
+		var tenants []domain.SysTenant
+		err = global.DB.WithContext(global.WithPrimary(ctx)).Model(&domain.SysTenant{}).
+			Where("dsn <> ?", "").Order("id").Find(&tenants).Error
+		if err != nil {
+			log.Fatalln(err)
+		}
+		for _, tenant := range tenants {
+			reindex(gorm_plugin.WithTenant(ctx, tenant.ID), s, "tenant "+tenant.Code, args[1:])
+		}
+	}
+	initialize.Close()
+}
+
+func reindex(ctx context.Context, s *service.SearchService, name string, tables []string) {
+	res, err := s.Reindex(ctx, tables, batchSize)
+	for table, count := range res {
+		log.Printf("%s %s reindex %d\n", name, table, count)
+	}
+	if err != nil {
+		log.Fatalf("%s reindex failed: %v", name, err)
+	}
+}
@@ -27,3 +27,20 @@ func GetRoleIdFromCtx(c *gin.Context) (uint, error) {
 
 	return uint(u.(float64)), nil
 }
+
+// GetTenantFromCtx 从上下文中获取用户租户，0为平台用户
+// 引入多租户之前签发的token没有租户，返回false
+func GetTenantFromCtx(c *gin.Context) (uint, bool) {
+	u, ok := c.Get(constants.CtxTenantKey)
+	if !ok {
+		return 0, false
+	}
+
+	id, ok := u.(float64)
+	return uint(id), ok
+}
+
+// IsSuperAdmin 用户是否是超级管理员，token中没有标记时为false
+func IsSuperAdmin(c *gin.Context) bool {
+	return c.GetBool(constants.CtxSuperAdminKey)
+}
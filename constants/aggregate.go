@@ -0,0 +1,14 @@
+package constants
+
+const (
+	// hour：小时，day：天，week：周，month：月，year：年
+	AggregateBucketHour  = "hour"
+	AggregateBucketDay   = "day"
+	AggregateBucketWeek  = "week"
+	AggregateBucketMonth = "month"
+	AggregateBucketYear  = "year"
+)
+
+const (
+	AggregateCacheKey = "aggregate:%d:%s:%s" // 统计结果缓存 租户id:资源名:查询条件摘要
+)
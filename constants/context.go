@@ -1,6 +1,8 @@
 package constants
 
 const (
-	CtxUserIdKey = "UserId" // 获取用户id上下文key
-	CtxRoleIdkEY = "RoleId" // 获取用户角色上下文key
+	CtxUserIdKey     = "UserId"     // 获取用户id上下文key
+	CtxRoleIdkEY     = "RoleId"     // 获取用户角色上下文key
+	CtxTenantKey     = "Tenant"     // 获取用户租户上下文key
+	CtxSuperAdminKey = "SuperAdmin" // 获取用户是否超级管理员上下文key
 )
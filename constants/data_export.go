@@ -0,0 +1,13 @@
+package constants
+
+const (
+	// exporting：导出中，success：导出成功，failed：导出失败
+	DataExportStatusExporting = "exporting"
+	DataExportStatusSuccess   = "success"
+	DataExportStatusFailed    = "failed"
+)
+
+const (
+	DataExportFormatXlsx = "xlsx"
+	DataExportFormatCsv  = "csv"
+)
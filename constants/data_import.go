@@ -8,5 +8,6 @@ const (
 )
 
 const (
-	DataImportCategoryDemo = "demo"
+	DataImportCategoryDemo    = "demo"
+	DataImportCategorySysUser = "sys_user"
 )
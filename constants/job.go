@@ -1,11 +1,16 @@
 package constants
 
 const (
-	QueueSms        = "queue:sms"
-	QueueDataImport = "queue:import"
+	QueueSms         = "queue:sms"
+	QueueDataImport  = "queue:import"
+	QueueAuditLog    = "queue:audit_log"
+	QueueSearchIndex = "queue:search_index"
+	QueueDataExport  = "queue:export"
 )
 
 const (
-	TaskTest    = "task:test"
-	TaskMonitor = "task:monitor"
+	TaskTest            = "task:test"
+	TaskMonitor         = "task:monitor"
+	TaskLoginLogCleanup = "task:login_log_cleanup"
+	TaskRecycleBinPurge = "task:recycle_bin_purge"
 )
@@ -0,0 +1,15 @@
+package constants
+
+const (
+	// password：账号密码，sms：短信验证码，oauth：第三方登录，2fa：二次验证
+	LoginTypePassword = "password"
+	LoginTypeSms      = "sms"
+	LoginTypeOAuth    = "oauth"
+	LoginType2FA      = "2fa"
+)
+
+const (
+	// success：成功，failed：失败
+	LoginResultSuccess = "success"
+	LoginResultFailed  = "failed"
+)
@@ -0,0 +1,19 @@
+package constants
+
+const (
+	// active：正常，disabled：禁用
+	SysTenantStatusActive   = "active"
+	SysTenantStatusDisabled = "disabled"
+)
+
+const (
+	// free：免费版，standard：标准版，enterprise：企业版
+	SysTenantPlanFree       = "free"
+	SysTenantPlanStandard   = "standard"
+	SysTenantPlanEnterprise = "enterprise"
+)
+
+const (
+	SysTenantCacheKey     = "sys_tenant:%d"      // 租户缓存
+	SysTenantCodeCacheKey = "sys_tenant:code:%s" // 租户编码对应的租户id
+)
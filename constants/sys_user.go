@@ -0,0 +1,13 @@
+package constants
+
+const (
+	// active：正常，disabled：禁用，locked：锁定(连续登录失败)
+	SysUserStatusActive   = "active"
+	SysUserStatusDisabled = "disabled"
+	SysUserStatusLocked   = "locked"
+)
+
+const (
+	SysUserStatusCacheKey = "sys_user:status:%d"       // 用户状态缓存
+	SysUserLoginFailedKey = "sys_user:login_failed:%d" // 连续登录失败次数
+)
@@ -8,16 +8,19 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/Madou-Shinni/gin-quickstart/constants"
 	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/search"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/message_queue"
+	"github.com/Madou-Shinni/go-logger"
 	"github.com/fsnotify/fsnotify"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"gorm.io/driver/mysql"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	gormlog "gorm.io/gorm/logger"
 )
@@ -27,11 +30,11 @@ var (
 )
 
 // 数据初始化
-// mysql、redis
+// 数据库、redis
 func init() {
 	flag.String("c", defaultConfigPath, "choose config file.")
 	ConfigInit()
-	MysqlInit(conf.Conf.MysqlConfig)
+	DatabaseInit(databaseConfig())
 	RedisInit(conf.Conf.RedisConfig)
 	ProducerInit(conf.Conf.AsynqConfig)
 }
@@ -87,46 +90,88 @@ func ConfigInit() {
 	})
 }
 
-// mysql连接初始化
-func MysqlInit(config *conf.MysqlConfig) {
-	// dsn := "root:123456@tcp(192.168.0.6:3306)/gin?charset=utf8mb4&parseTime=True&loc=Local"
-	dsn := fmt.Sprintf(
-		"%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local",
-		config.User, config.Password, config.Host, config.Port, config.DBName)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+// 数据库连接初始化
+func DatabaseInit(config *conf.DatabaseConfig) {
+	db, err := openDB(config)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	// 读写分离
+	if err = replicasInit(db, config); err != nil {
+		log.Println(err)
+	}
+
+	global.DB = global.NewData(db)
+	// 租户独立数据库
+	if tenantConfig := conf.Conf.TenantConfig; tenantConfig != nil && tenantConfig.Enable && tenantConfig.Database {
+		global.DB.UseTenantDBs(tenantDBsInit(db, config))
+		// 租户独立数据库的主键与共享库重复，使用租户自己的搜索索引
+		search.SetIndexName(searchIndexName)
+	}
+
+	//plugin := gorm_plugin.NewLogPlugin()
+	//plugin.Apply(global.DB)
+}
+
+// openDB 打开数据库并注册插件
+func openDB(config *conf.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := Dialector(config)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
 		QueryFields:                              true, // 打印sql
 		DisableForeignKeyConstraintWhenMigrating: true, // 禁用外键约束
 		//SkipDefaultTransaction: true, //禁用事务
 		Logger: gorm_plugin.NewGormLogger().LogMode(gormlog.Error),
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	sqlDB, _ := db.DB()
 	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	if config.Driver == conf.DriverSqlite && config.Path == sqliteMemory {
+		// 内存数据库每个连接都是独立的数据库
+		sqlDB.SetMaxOpenConns(1)
+	}
 
-	if err != nil {
+	// 变更历史
+	historyPlugin := gorm_plugin.NewHistoryPlugin(func(tx *gorm.DB, records []gorm_plugin.ChangeRecord) error {
+		histories := make([]domain.SysChangeHistory, 0, len(records))
+		for _, record := range records {
+			histories = append(histories, domain.NewSysChangeHistory(record))
+		}
+		return tx.Create(&histories).Error
+	})
+	if err = historyPlugin.Apply(db); err != nil {
+		log.Println(err)
+	}
+	// 创建人、修改人、删除人
+	if err = gorm_plugin.NewAuditPlugin().Apply(db); err != nil {
+		log.Println(err)
+	}
+	// 乐观锁
+	if err = gorm_plugin.NewVersionPlugin().Apply(db); err != nil {
+		log.Println(err)
+	}
+	// 租户隔离
+	tenantPlugin := gorm_plugin.NewTenantPlugin()
+	if tenantConfig := conf.Conf.TenantConfig; tenantConfig != nil && tenantConfig.Enable {
+		// 开启多租户时没有租户的操作需要显式跨租户
+		tenantPlugin = gorm_plugin.NewTenantPlugin(gorm_plugin.WithTenantRequired())
+	}
+	if err = tenantPlugin.Apply(db); err != nil {
+		log.Println(err)
+	}
+	// 搜索引擎同步
+	if err = gorm_plugin.NewSearchPlugin(searchNotify).Apply(db); err != nil {
 		log.Println(err)
-		return
 	}
 
-	// 自动迁移
-	db.AutoMigrate(
-		// 表
-		domain.Demo{},
-		domain.File{},
-		domain.SysUser{},
-		domain.SysRole{},
-		//domain.SysCasbin{},
-		domain.SysApi{},
-		domain.SysMenu{},
-		domain.DataImport{},
-		domain.SystemFile{},
-	)
-
-	global.DB = global.NewData(db)
-
-	//plugin := gorm_plugin.NewLogPlugin()
-	//plugin.Apply(global.DB)
+	return db, nil
 }
 
 // redis连接初始化
@@ -154,6 +199,34 @@ func ProducerInit(config *conf.AsynqConfig) {
 	global.Producer = client
 }
 
+// searchNotify 数据变更后投递搜索引擎同步任务，由 service.SearchService.Handle 处理
+// 在事务(global.DB.Tx)中时提交后再投递，事务回滚时不投递
+func searchNotify(ctx context.Context, table string, ids []string) error {
+	if global.Producer == nil {
+		return nil
+	}
+	payload := search.Task{
+		Table:    table,
+		IDs:      ids,
+		TenantID: gorm_plugin.TenantFromContext(ctx),
+		Shared:   global.IsShared(ctx),
+	}
+	global.AfterCommit(ctx, func() {
+		if err := global.Producer.NewTask(constants.QueueSearchIndex, payload); err != nil {
+			logger.Error("search notify", zap.Error(err), zap.Any("search.Task", payload))
+		}
+	})
+	return nil
+}
+
+// searchIndexName 租户独立数据库的数据使用 表名_tenant_租户 索引，共享库使用表名
+func searchIndexName(ctx context.Context, table string) string {
+	if tenantID, ok := global.DB.DedicatedTenant(ctx); ok {
+		return fmt.Sprintf("%s_tenant_%d", table, tenantID)
+	}
+	return table
+}
+
 // 释放资源
 func Close() {
 	if global.Rdb != nil {
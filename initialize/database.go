@@ -0,0 +1,99 @@
+package initialize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	sqliteMemory      = ":memory:"
+	defaultSqlitePath = "./data/gin-quickstart.db"
+)
+
+// databaseConfig 优先使用 database 配置，兼容旧的 mysql 配置
+// 都没有配置时(例如没有配置文件的单元测试)使用内存数据库，不在当前目录创建数据库文件
+func databaseConfig() *conf.DatabaseConfig {
+	if conf.Conf.DatabaseConfig != nil {
+		return conf.Conf.DatabaseConfig
+	}
+	if conf.Conf.MysqlConfig != nil {
+		config := *conf.Conf.MysqlConfig
+		config.Driver = conf.DriverMysql
+		return &config
+	}
+	return &conf.DatabaseConfig{Driver: conf.DriverSqlite, Path: sqliteMemory}
+}
+
+// Dialector 根据配置的驱动创建 gorm.Dialector
+func Dialector(config *conf.DatabaseConfig) (gorm.Dialector, error) {
+	switch config.Driver {
+	case "", conf.DriverMysql:
+		config.Driver = conf.DriverMysql
+		dsn := config.DSN
+		if dsn == "" {
+			// dsn := "root:123456@tcp(192.168.0.6:3306)/gin?charset=utf8mb4&parseTime=True&loc=Local"
+			dsn = fmt.Sprintf(
+				"%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local",
+				config.User, config.Password, config.Host, config.Port, config.DBName)
+		}
+		return mysql.Open(dsn), nil
+	case conf.DriverPostgres:
+		dsn := config.DSN
+		if dsn == "" {
+			dsn = fmt.Sprintf(
+				"host=%v port=%v user=%v password=%v dbname=%v sslmode=disable TimeZone=Asia/Shanghai",
+				config.Host, config.Port, config.User, config.Password, config.DBName)
+		}
+		return postgres.Open(dsn), nil
+	case conf.DriverSqlite:
+		if config.Path == "" {
+			config.Path = defaultSqlitePath
+		}
+		dsn := config.DSN
+		if dsn == "" {
+			dsn = config.Path
+			if config.Path != sqliteMemory {
+				if err := os.MkdirAll(filepath.Dir(config.Path), os.ModePerm); err != nil {
+					return nil, err
+				}
+				// 等待写锁，避免并发写入时返回 database is locked
+				dsn += "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+			}
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", config.Driver)
+	}
+}
+
+// replicasInit 注册从库，查询自动路由到从库，写入、事务和 global.WithPrimary 走主库
+func replicasInit(db *gorm.DB, config *conf.DatabaseConfig) error {
+	if len(config.Replicas) == 0 {
+		return nil
+	}
+	replicas := make([]gorm.Dialector, 0, len(config.Replicas))
+	for _, replica := range config.Replicas {
+		replica.Driver = config.Driver
+		dialector, err := Dialector(&replica)
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxIdleConns(config.MaxIdleConns).
+		SetMaxOpenConns(config.MaxOpenConns)
+	return db.Use(resolver)
+}
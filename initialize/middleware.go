@@ -0,0 +1,11 @@
+package initialize
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/middleware"
+)
+
+// 中间件依赖的服务，middleware 不直接依赖 internal/service 的实现
+func init() {
+	middleware.SetUserStatusChecker(service.NewSysUserService().CheckStatus)
+}
@@ -0,0 +1,94 @@
+package initialize
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/migrations"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+// NewMigrator 初始化共享库的数据库迁移，加载 migrations 目录下的迁移
+func NewMigrator() (*migrate.Migrator, error) {
+	// 迁移需要读取表结构，使用主库避免读到从库
+	return newMigrator(global.DB.WithContext(global.WithPrimary(context.Background())))
+}
+
+func newMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	var opts []migrate.Option
+	if config := conf.Conf.MigrateConfig; config != nil && config.LockTimeout > 0 {
+		opts = append(opts, migrate.WithLockTimeout(time.Duration(config.LockTimeout)*time.Second))
+	}
+	m := migrate.New(db, opts...)
+	if err := migrations.Load(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Migrate 启动时迁移数据库
+// 默认执行未执行的迁移，开发模式下使用 AutoMigrate 同步表结构
+func Migrate() {
+	config := conf.Conf.MigrateConfig
+	if config == nil {
+		config = &conf.MigrateConfig{OnStartup: true}
+	}
+
+	if config.OnStartup {
+		m, err := NewMigrator()
+		if err != nil {
+			log.Fatalf("migrate init failed: %v", err)
+		}
+		applied, err := m.Up(context.Background(), 0)
+		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			log.Fatalf("migrate up failed: %v", err)
+		}
+		for _, version := range applied {
+			log.Printf("migrate up %s\n", version)
+		}
+
+		// 租户独立数据库
+		err = EachTenantMigrator(context.Background(), func(tenant domain.SysTenant, m *migrate.Migrator) error {
+			applied, err := m.Up(context.Background(), 0)
+			for _, version := range applied {
+				log.Printf("migrate up %s (tenant %s)\n", version, tenant.Code)
+			}
+			if errors.Is(err, migrate.ErrNoChange) {
+				return nil
+			}
+			return err
+		})
+		if err != nil && !errors.Is(err, global.ErrTenantDBDisabled) {
+			log.Fatalf("migrate up failed: %v", err)
+		}
+	}
+
+	if config.AutoMigrate {
+		err := global.DB.WithContext(global.WithPrimary(context.Background())).AutoMigrate(
+			// 表
+			domain.Demo{},
+			domain.File{},
+			domain.SysUser{},
+			domain.SysRole{},
+			//domain.SysCasbin{},
+			domain.SysApi{},
+			domain.SysMenu{},
+			domain.DataImport{},
+			domain.SystemFile{},
+			domain.SysLoginLog{},
+			domain.SysOperationLog{},
+			domain.SysChangeHistory{},
+			domain.SysTenant{},
+			domain.DataExport{},
+		)
+		if err != nil {
+			log.Printf("auto migrate failed: %v\n", err)
+		}
+	}
+}
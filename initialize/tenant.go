@@ -0,0 +1,86 @@
+package initialize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// tenantDBsInit 租户独立数据库，连接字符串保存在共享库的 sys_tenant.dsn 中
+func tenantDBsInit(db *gorm.DB, config *conf.DatabaseConfig) *global.TenantDBs {
+	return global.NewTenantDBs(global.TenantDBConfig{
+		Lookup: func(ctx context.Context, tenantID uint) (string, error) {
+			var dsn []string
+			err := db.WithContext(ctx).Clauses(dbresolver.Write).Model(&domain.SysTenant{}).
+				Where("id = ?", tenantID).Limit(1).Pluck("dsn", &dsn).Error
+			if err != nil || len(dsn) == 0 {
+				return "", err
+			}
+			return dsn[0], nil
+		},
+		Open: func(dsn string) (*gorm.DB, error) {
+			return openDB(&conf.DatabaseConfig{
+				Driver:       config.Driver,
+				DSN:          dsn,
+				MaxIdleConns: config.MaxIdleConns,
+				MaxOpenConns: config.MaxOpenConns,
+			})
+		},
+		Migrate: func(ctx context.Context, db *gorm.DB) error {
+			m, err := newMigrator(db)
+			if err != nil {
+				return err
+			}
+			_, err = m.Up(ctx, 0)
+			if errors.Is(err, migrate.ErrNoChange) {
+				return nil
+			}
+			return err
+		},
+	})
+}
+
+// EachTenantMigrator 依次对有独立数据库的租户执行 fn
+func EachTenantMigrator(ctx context.Context, fn func(tenant domain.SysTenant, m *migrate.Migrator) error) error {
+	tenants, err := global.DB.Tenants()
+	if err != nil {
+		return err
+	}
+
+	var list []domain.SysTenant
+	err = global.DB.WithContext(global.WithPrimary(ctx)).Model(&domain.SysTenant{}).
+		Where("dsn <> ?", "").Order("id").Find(&list).Error
+	if err != nil {
+		return err
+	}
+
+	for _, tenant := range list {
+		if err = eachTenantMigrator(tenants, tenant, fn); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant.Code, err)
+		}
+	}
+	return nil
+}
+
+func eachTenantMigrator(tenants *global.TenantDBs, tenant domain.SysTenant, fn func(tenant domain.SysTenant, m *migrate.Migrator) error) error {
+	db, err := tenants.Open(tenant.DSN)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	return fn(tenant, m)
+}
@@ -3,14 +3,21 @@ package conf
 var Conf = new(ProfileInfo)
 
 type ProfileInfo struct {
-	*App           `mapstructure:"app"`
-	*MysqlConfig   `mapstructure:"mysql"`
-	*RedisConfig   `mapstructure:"redis"`
-	*JwtConfig     `mapstructure:"jwt"`
-	*UploadConfig  `mapstructure:"upload"`
-	*AsynqConfig   `mapstructure:"asynq"`
-	*SMSConfig     `mapstructure:"sms"`
-	*MonitorConfig `mapstructure:"monitor"`
+	*App              `mapstructure:"app"`
+	*DatabaseConfig   `mapstructure:"database"`
+	*MysqlConfig      `mapstructure:"mysql"` // Deprecated: 使用 database，没有配置 database 时使用
+	*RedisConfig      `mapstructure:"redis"`
+	*JwtConfig        `mapstructure:"jwt"`
+	*UploadConfig     `mapstructure:"upload"`
+	*AsynqConfig      `mapstructure:"asynq"`
+	*SMSConfig        `mapstructure:"sms"`
+	*MonitorConfig    `mapstructure:"monitor"`
+	*LoginLogConfig   `mapstructure:"login-log"`
+	*AuditConfig      `mapstructure:"audit"`
+	*RecycleBinConfig `mapstructure:"recycle-bin"`
+	*MigrateConfig    `mapstructure:"migrate"`
+	*TenantConfig     `mapstructure:"tenant"`
+	*ExportConfig     `mapstructure:"export"`
 }
 
 // 系统配置
@@ -21,8 +28,18 @@ type App struct {
 	LogFile    string `mapstructure:"log-file"`
 }
 
-// mysql配置
-type MysqlConfig struct {
+// 数据库驱动
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+// 数据库配置
+type DatabaseConfig struct {
+	Driver       string `mapstructure:"driver"` // 数据库驱动 mysql、postgres、sqlite，默认mysql
+	DSN          string `mapstructure:"dsn"`    // 连接字符串，不为空时忽略 host、port 等配置
+	Path         string `mapstructure:"path"`   // sqlite数据库文件路径，:memory: 为内存数据库
 	Host         string `mapstructure:"host"`
 	Port         int    `mapstructure:"port"`
 	User         string `mapstructure:"user"`
@@ -30,8 +47,14 @@ type MysqlConfig struct {
 	DBName       string `mapstructure:"dbname"`
 	MaxIdleConns int    `mapstructure:"max-idle-conns" json:"max-idle-conns" yaml:"max-idle-conns"` // 空闲中的最大连接数
 	MaxOpenConns int    `mapstructure:"max-open-conns" json:"max-open-conns" yaml:"max-open-conns"` // 打开到数据库的最大连接数
+	// 从库，配置后查询走从库，写入和事务走主库，驱动与主库一致
+	Replicas []DatabaseConfig `mapstructure:"replicas"`
 }
 
+// mysql配置
+// Deprecated: 使用 DatabaseConfig
+type MysqlConfig = DatabaseConfig
+
 // redis配置
 type RedisConfig struct {
 	Addr     string `mapstructure:"addr"`
@@ -68,10 +91,13 @@ type UploadConfig struct {
 
 // SMS配置
 type SMSConfig struct {
-	SmsServer       string `mapstructure:"sms_server"`
-	SmsSendPath     string `mapstructure:"sms_send_path"`
-	SmsToken        string `mapstructure:"sms_token"`
-	SmsVerifyExpire int    `mapstructure:"sms_verify_expire"`
+	SmsServer        string `mapstructure:"sms_server"`
+	SmsSendPath      string `mapstructure:"sms_send_path"`
+	SmsToken         string `mapstructure:"sms_token"`
+	SmsVerifyExpire  int    `mapstructure:"sms_verify_expire"`
+	SmsSignName      string `mapstructure:"sms_sign_name"`      // 短信签名
+	SmsCredentialTpl string `mapstructure:"sms_credential_tpl"` // 账号初始密码通知模板
+	SmsExportTpl     string `mapstructure:"sms_export_tpl"`     // 导出完成通知模板
 }
 
 type MonitorConfig struct {
@@ -86,3 +112,51 @@ type MonitorConfig struct {
 		StubTime int64 `mapstructure:"stub-time"`
 	} `mapstructure:"file"`
 }
+
+// LoginLogConfig 登录日志配置
+type LoginLogConfig struct {
+	// 保留天数，超过的日志会被定时清理，0则不清理
+	RetentionDays int `mapstructure:"retention-days"`
+	// 本地IP库文件(ip2region txt格式)，为空则不解析归属地
+	IPDBPath string `mapstructure:"ipdb-path"`
+}
+
+// AuditConfig 操作日志配置
+type AuditConfig struct {
+	// 是否开启
+	Enable bool `mapstructure:"enable"`
+	// 需要脱敏的字段(忽略大小写)
+	MaskFields []string `mapstructure:"mask-fields"`
+	// 请求体最大记录长度
+	MaxBodyLength int `mapstructure:"max-body-length"`
+	// 不记录的接口
+	SkipPaths []string `mapstructure:"skip-paths"`
+}
+
+// RecycleBinConfig 回收站配置
+type RecycleBinConfig struct {
+	// 保留天数，超过的数据会被定时彻底删除，0则不清理
+	RetentionDays int `mapstructure:"retention-days"`
+}
+
+// 数据库迁移配置
+type MigrateConfig struct {
+	OnStartup   bool `mapstructure:"on-startup"`   // 启动时执行未执行的迁移
+	AutoMigrate bool `mapstructure:"auto-migrate"` // 开发模式，启动时使用AutoMigrate同步表结构(不会删除、重命名列)
+	LockTimeout int  `mapstructure:"lock-timeout"` // 等待其他实例迁移完成的秒数
+}
+
+// 多租户配置
+type TenantConfig struct {
+	Enable       bool   `mapstructure:"enable"`
+	Header       string `mapstructure:"header"`        // 指定租户id的请求头，默认 X-Tenant-ID
+	BypassHeader string `mapstructure:"bypass-header"` // 超级管理员跨租户操作的请求头，值为true时生效，默认 X-Tenant-Bypass
+	Domain       string `mapstructure:"domain"`        // 主域名，配置后从子域名解析租户编码，例如 acme.example.com 的租户编码为 acme
+	Database     bool   `mapstructure:"database"`      // 租户可以使用独立数据库，业务数据保存在租户的数据库中
+}
+
+// 列表导出配置
+type ExportConfig struct {
+	AsyncThreshold int64  `mapstructure:"async-threshold"` // 超过该数量时异步导出，0则使用默认值
+	Dir            string `mapstructure:"dir"`             // 异步导出的文件目录
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"gorm.io/gorm"
+)
+
+// aggregateLimit 聚合统计最多返回的分组数
+const aggregateLimit = 1000
+
+// AggregateBucketKey 按时间分组时结果中时间的key
+const AggregateBucketKey = "bucket"
+
+// bucketFormats 各数据库按时间分组的格式，结果统一为 2006-01-02 15:00、2006-01-02、2006-W01、2006-01、2006
+var bucketFormats = map[string]map[string]string{
+	"mysql": {
+		constants.AggregateBucketHour:  "DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00')",
+		constants.AggregateBucketDay:   "DATE_FORMAT(%s, '%%Y-%%m-%%d')",
+		constants.AggregateBucketWeek:  "DATE_FORMAT(%s, '%%x-W%%v')",
+		constants.AggregateBucketMonth: "DATE_FORMAT(%s, '%%Y-%%m')",
+		constants.AggregateBucketYear:  "DATE_FORMAT(%s, '%%Y')",
+	},
+	"postgres": {
+		constants.AggregateBucketHour:  "TO_CHAR(%s, 'YYYY-MM-DD HH24:00')",
+		constants.AggregateBucketDay:   "TO_CHAR(%s, 'YYYY-MM-DD')",
+		constants.AggregateBucketWeek:  `TO_CHAR(%s, 'IYYY-"W"IW')`,
+		constants.AggregateBucketMonth: "TO_CHAR(%s, 'YYYY-MM')",
+		constants.AggregateBucketYear:  "TO_CHAR(%s, 'YYYY')",
+	},
+	"sqlite": {
+		constants.AggregateBucketHour:  "STRFTIME('%%Y-%%m-%%d %%H:00', %s)",
+		constants.AggregateBucketDay:   "STRFTIME('%%Y-%%m-%%d', %s)",
+		constants.AggregateBucketWeek:  "STRFTIME('%%Y-W%%W', %s)",
+		constants.AggregateBucketMonth: "STRFTIME('%%Y-%%m', %s)",
+		constants.AggregateBucketYear:  "STRFTIME('%%Y', %s)",
+	},
+}
+
+// AggregateRepo 通用的聚合统计，model 为领域模型的指针，例如 &domain.Demo{}
+type AggregateRepo struct {
+}
+
+// Aggregate 按 groupBy 字段和 timeField 的 bucket 时间粒度分组统计 metrics，字段为json名称
+// 返回的每一行以分组字段的json名称、bucket、指标名(count、sum_字段)为key，按分组排序，最多返回 aggregateLimit 组
+func (s *AggregateRepo) Aggregate(ctx context.Context, model interface{}, groupBy []string, metrics []domain.AggregateMetric, bucket, timeField string, fns ...func(db *gorm.DB) *gorm.DB) ([]map[string]interface{}, error) {
+	var (
+		rows    = make([]map[string]interface{}, 0)
+		selects []string
+		groups  []string
+	)
+	db := global.DB.WithContext(ctx)
+	fields, err := jsonFields(db, model)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &gorm.Statement{DB: db}
+
+	if bucket != "" {
+		formats, ok := bucketFormats[db.Dialector.Name()]
+		if !ok {
+			return nil, fmt.Errorf("bucket is not supported by %s", db.Dialector.Name())
+		}
+		format, ok := formats[bucket]
+		if !ok {
+			return nil, fmt.Errorf("unknown bucket %q", bucket)
+		}
+		field, ok := fields[timeField]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", timeField)
+		}
+		expr := fmt.Sprintf(format, stmt.Quote(field.DBName))
+		selects = append(selects, expr+" AS "+stmt.Quote(AggregateBucketKey))
+		groups = append(groups, expr)
+	}
+	columns, err := jsonColumns(fields, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range columns {
+		selects = append(selects, stmt.Quote(column)+" AS "+stmt.Quote(groupBy[i]))
+		groups = append(groups, stmt.Quote(column))
+	}
+	for _, metric := range metrics {
+		arg, name := "*", metric.Func
+		if metric.Field != "" {
+			field, ok := fields[metric.Field]
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q", metric.Field)
+			}
+			arg, name = stmt.Quote(field.DBName), metric.Func+"_"+metric.Field
+		}
+		switch metric.Func {
+		case "count", "sum", "avg", "min", "max":
+		default:
+			return nil, fmt.Errorf("unknown metric %q", metric.Func)
+		}
+		selects = append(selects, fmt.Sprintf("%s(%s) AS %s", strings.ToUpper(metric.Func), arg, stmt.Quote(name)))
+	}
+	if len(metrics) == 0 {
+		selects = append(selects, "COUNT(*) AS "+stmt.Quote("count"))
+	}
+
+	db = db.Model(model).Scopes(fns...).Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+	if err = db.Limit(aggregateLimit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,62 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"github.com/stretchr/testify/assert"
+)
+
+type aggregateOrder struct {
+	model.Model
+	Status string `json:"status" form:"status" filter:"eq"`
+	Amount int    `json:"amount"`
+}
+
+func TestAggregateRepo_Aggregate(t *testing.T) {
+	setupRepo(t)
+	ctx := context.Background()
+	db := global.DB.WithContext(ctx)
+	assert.NoError(t, db.AutoMigrate(&aggregateOrder{}))
+	day := func(d int) *model.LocalTime {
+		return &model.LocalTime{Time: time.Date(2026, 10, d, 10, 0, 0, 0, time.UTC)}
+	}
+	orders := []aggregateOrder{
+		{Model: model.Model{CreatedAt: day(1)}, Status: "paid", Amount: 10},
+		{Model: model.Model{CreatedAt: day(1)}, Status: "paid", Amount: 20},
+		{Model: model.Model{CreatedAt: day(1)}, Status: "refund", Amount: 5},
+		{Model: model.Model{CreatedAt: day(2)}, Status: "paid", Amount: 7},
+		{Model: model.Model{CreatedAt: day(2)}, Status: "paid", Amount: 100},
+	}
+	assert.NoError(t, db.Create(&orders).Error)
+	assert.NoError(t, db.Delete(&orders[4]).Error)
+
+	repo := &AggregateRepo{}
+	rows, err := repo.Aggregate(ctx, &aggregateOrder{}, nil, nil, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"count": int64(4)}}, rows)
+
+	metrics := []domain.AggregateMetric{{Func: "count"}, {Func: "sum", Field: "amount"}, {Func: "max", Field: "amount"}}
+	rows, err = repo.Aggregate(ctx, &aggregateOrder{}, []string{"status"}, metrics, "day", "createdAt")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"bucket": "2026-10-01", "status": "paid", "count": int64(2), "sum_amount": int64(30), "max_amount": int64(20)},
+		{"bucket": "2026-10-01", "status": "refund", "count": int64(1), "sum_amount": int64(5), "max_amount": int64(5)},
+		{"bucket": "2026-10-02", "status": "paid", "count": int64(1), "sum_amount": int64(7), "max_amount": int64(7)},
+	}, rows)
+
+	// 过滤条件
+	rows, err = repo.Aggregate(ctx, &aggregateOrder{}, nil, metrics[:2], "month", "createdAt", scopes.Filter(&aggregateOrder{Status: "paid"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"bucket": "2026-10", "count": int64(3), "sum_amount": int64(37)}}, rows)
+
+	_, err = repo.Aggregate(ctx, &aggregateOrder{}, []string{"status; DROP TABLE aggregate_orders"}, nil, "", "")
+	assert.Error(t, err)
+	_, err = repo.Aggregate(ctx, &aggregateOrder{}, nil, []domain.AggregateMetric{{Func: "group_concat", Field: "status"}}, "", "")
+	assert.Error(t, err)
+}
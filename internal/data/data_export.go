@@ -0,0 +1,29 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+)
+
+type DataExportRepo struct {
+	Repo[domain.DataExport]
+}
+
+func (s *DataExportRepo) Update(ctx context.Context, dataExport domain.DataExport) error {
+	if dataExport.ID == 0 {
+		return errors.New(fmt.Sprintf("missing %s.id", "dataExport"))
+	}
+	return global.DB.WithContext(ctx).Model(&dataExport).
+		Select("file_url", "status", "count", "reason").Updates(&dataExport).Error
+}
+
+// List 查询 createdBy 发起的导出
+func (s *DataExportRepo) List(ctx context.Context, page domain.PageDataExportSearch) ([]domain.DataExport, response.PageInfo, error) {
+	return s.Page(ctx, page.PageSearch, scopes.Where("created_by = ?", page.CreatedBy), scopes.Filter(page))
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// noPageLimit 不分页查询最多返回的条数
+const noPageLimit = 1000
+
+// NoPageRepo 不分页的通用查询，model 为领域模型的指针，例如 &domain.Demo{}
+type NoPageRepo struct {
+}
+
+// List 查询 selects 字段，keyword 不为空时按 searches 字段模糊搜索，字段为json名称
+// 返回的每一行以json名称为key，最多返回 noPageLimit 条
+func (s *NoPageRepo) List(ctx context.Context, model interface{}, selects, searches []string, keyword string, fns ...func(db *gorm.DB) *gorm.DB) ([]map[string]interface{}, int64, error) {
+	var (
+		count int64
+		rows  = make([]map[string]interface{}, 0)
+	)
+	db := global.DB.WithContext(ctx)
+	fields, err := jsonFields(db, model)
+	if err != nil {
+		return nil, 0, err
+	}
+	selectCols, err := jsonColumns(fields, selects)
+	if err != nil {
+		return nil, 0, err
+	}
+	searchCols, err := jsonColumns(fields, searches)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	db = db.Model(model).Scopes(fns...).Scopes(scopes.Search(keyword, searchCols...))
+	if err = db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err = db.Select(selectCols).Scopes(defaultOrder).Limit(noPageLimit).Find(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 列名转换为json名称
+	for i, row := range rows {
+		res := make(map[string]interface{}, len(selects))
+		for j, name := range selects {
+			res[name] = row[selectCols[j]]
+		}
+		rows[i] = res
+	}
+	return rows, count, nil
+}
+
+// jsonFields json名称到字段的映射
+func jsonFields(db *gorm.DB, model interface{}) (map[string]*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]*schema.Field, len(stmt.Schema.Fields))
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields, nil
+}
+
+// jsonColumns json名称对应的列名
+func jsonColumns(fields map[string]*schema.Field, names []string) ([]string, error) {
+	columns := make([]string, 0, len(names))
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		columns = append(columns, field.DBName)
+	}
+	return columns, nil
+}
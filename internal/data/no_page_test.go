@@ -0,0 +1,74 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoPageRepo_List(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+	for _, title := range []string{"go", "golang", "100%", "rust"} {
+		assert.NoError(t, repo.Create(ctx, &repoArticle{Title: title}))
+	}
+	deleted, err := repo.First(ctx, repoArticle{}, scopes.Where("title = ?", "golang"))
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete(ctx, deleted))
+
+	noPage := &NoPageRepo{}
+	list, count, err := noPage.List(ctx, &repoArticle{}, []string{"id", "title"}, []string{"title"}, "go")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, []map[string]interface{}{{"id": uint(1), "title": "go"}}, list)
+
+	// 通配符被转义，数据权限
+	list, _, err = noPage.List(ctx, &repoArticle{}, []string{"title"}, []string{"title"}, "%")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"title": "100%"}}, list)
+	_, count, err = noPage.List(ctx, &repoArticle{}, []string{"title"}, nil, "", scopes.Where("title <> ?", "rust"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, _, err = noPage.List(ctx, &repoArticle{}, []string{"id", "title; DROP TABLE repo_articles"}, nil, "")
+	assert.Error(t, err)
+}
+
+func TestSysUserRepo_DataScope(t *testing.T) {
+	setupRepo(t)
+	ctx := context.Background()
+	db := global.DB.WithContext(ctx)
+	assert.NoError(t, db.AutoMigrate(&domain.SysRole{}, &domain.SysUser{}))
+
+	// 角色 1 -> 2 -> 3，角色 4 与之无关
+	roles := []domain.SysRole{{RoleName: "a"}, {ParentID: 1, RoleName: "b"}, {ParentID: 2, RoleName: "c"}, {RoleName: "d"}}
+	assert.NoError(t, db.Create(&roles).Error)
+	users := []domain.SysUser{
+		{Account: "me", Password: "-", NickName: "me", Department: "研发", Roles: []domain.SysRole{roles[1]}},
+		{Account: "colleague", Password: "-", NickName: "colleague", Department: "研发", Roles: []domain.SysRole{roles[3]}},
+		{Account: "child", Password: "-", NickName: "child", Department: "销售", Roles: []domain.SysRole{roles[2]}},
+		{Account: "parent", Password: "-", NickName: "parent", Department: "销售", Roles: []domain.SysRole{roles[0]}},
+		{Account: "other", Password: "-", NickName: "other", Department: "销售", Roles: []domain.SysRole{roles[3]}},
+	}
+	assert.NoError(t, db.Create(&users).Error)
+
+	repo := &SysUserRepo{}
+	noPage := &NoPageRepo{}
+	scope, err := repo.DataScope(ctx, users[0].ID, roles[1].ID)
+	assert.NoError(t, err)
+	list, _, err := noPage.List(ctx, &domain.SysUser{}, []string{"account"}, nil, "", scope)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []map[string]interface{}{{"account": "me"}, {"account": "colleague"}, {"account": "child"}}, list)
+
+	// 没有部门时只按角色
+	assert.NoError(t, db.Model(&users[0]).Update("department", "").Error)
+	scope, err = repo.DataScope(ctx, users[0].ID, roles[1].ID)
+	assert.NoError(t, err)
+	list, _, err = noPage.List(ctx, &domain.SysUser{}, []string{"account"}, nil, "", scope)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []map[string]interface{}{{"account": "me"}, {"account": "child"}}, list)
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"gorm.io/gorm"
+)
+
+// RecycleBinRepo 软删除数据的通用操作，model 为领域模型的指针，例如 &domain.Demo{}
+type RecycleBinRepo struct {
+}
+
+func (s *RecycleBinRepo) deleted(ctx context.Context, model interface{}) *gorm.DB {
+	return global.DB.WithContext(ctx).Unscoped().Model(model).Where("deleted_at IS NOT NULL")
+}
+
+// List 分页查询已删除的数据，返回 []T
+func (s *RecycleBinRepo) List(ctx context.Context, model interface{}, page request.PageSearch) (interface{}, int64, error) {
+	var count int64
+	list := newSlice(model)
+
+	err := s.deleted(ctx, model).Count(&count).Scopes(scopes.Paginate(page)).Order("deleted_at desc").Find(list.Interface()).Error
+
+	return list.Elem().Interface(), count, err
+}
+
+// Restore 恢复已删除的数据
+func (s *RecycleBinRepo) Restore(ctx context.Context, model interface{}, ids []uint) (int64, error) {
+	res := s.deleted(ctx, model).Where("id IN ?", ids).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": 0})
+	return res.RowsAffected, res.Error
+}
+
+// Purge 彻底删除已删除的数据，同时删除多对多关联表中的数据
+func (s *RecycleBinRepo) Purge(ctx context.Context, model interface{}, ids []uint) (int64, error) {
+	return s.purge(ctx, model, func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN ?", ids)
+	})
+}
+
+// PurgeBefore 彻底删除 before 之前删除的数据
+func (s *RecycleBinRepo) PurgeBefore(ctx context.Context, model interface{}, before time.Time) (int64, error) {
+	return s.purge(ctx, model, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at < ?", before)
+	})
+}
+
+func (s *RecycleBinRepo) purge(ctx context.Context, model interface{}, where func(db *gorm.DB) *gorm.DB) (int64, error) {
+	var affected int64
+	err := global.DB.Tx(ctx, func(ctx context.Context) error {
+		list := newSlice(model)
+		if err := s.deleted(ctx, model).Scopes(where).Select("id").Find(list.Interface()).Error; err != nil {
+			return err
+		}
+		if list.Elem().Len() == 0 {
+			return nil
+		}
+
+		db := global.DB.WithContext(ctx).Unscoped()
+		if m2m := many2many(db, model); len(m2m) > 0 {
+			db = db.Select(m2m)
+		}
+		res := db.Delete(list.Interface())
+		affected = res.RowsAffected
+		return res.Error
+	})
+	return affected, err
+}
+
+// many2many 模型的多对多关联，彻底删除时需要一起删除关联表中的数据
+func many2many(db *gorm.DB, model interface{}) []string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(stmt.Schema.Relationships.Many2Many))
+	for _, rel := range stmt.Schema.Relationships.Many2Many {
+		names = append(names, rel.Name)
+	}
+	return names
+}
+
+func newSlice(model interface{}) reflect.Value {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+}
@@ -0,0 +1,135 @@
+package data
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/search"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repo 通用的增删改查，T 为领域模型，例如 Repo[domain.Demo]
+// 领域仓库嵌入后只需要实现特有的方法，查询条件、预加载等通过 scopes 传入
+// 所有操作都通过 global.DB.WithContext 执行，在 global.DB.Tx 中调用时使用事务，租户、变更历史等插件同样生效
+type Repo[T any] struct {
+}
+
+// DB 返回模型为 T 的会话
+func (r *Repo[T]) DB(ctx context.Context, fns ...func(db *gorm.DB) *gorm.DB) *gorm.DB {
+	return global.DB.WithContext(ctx).Model(new(T)).Scopes(fns...)
+}
+
+// Create 创建，成功后回填主键
+func (r *Repo[T]) Create(ctx context.Context, entity *T) error {
+	return global.DB.WithContext(ctx).Create(entity).Error
+}
+
+// Delete 根据主键删除，模型有 DeletedAt 时为软删除
+func (r *Repo[T]) Delete(ctx context.Context, entity T) error {
+	return global.DB.WithContext(ctx).Delete(&entity).Error
+}
+
+// DeleteByIds 根据主键批量删除
+func (r *Repo[T]) DeleteByIds(ctx context.Context, ids request.Ids) error {
+	return global.DB.WithContext(ctx).Delete(&[]T{}, ids.Ids).Error
+}
+
+// UpdateWith 根据更新策略校验请求后更新，请求中必须有主键
+func (r *Repo[T]) UpdateWith(ctx context.Context, p *policy.UpdatePolicy, values map[string]interface{}) error {
+	id, values, err := p.Apply(values)
+	if err != nil {
+		return err
+	}
+	db := global.DB.WithContext(ctx)
+	model, err := r.withID(db, id)
+	if err != nil {
+		return err
+	}
+	// 通过模型的主键更新，变更历史、乐观锁等插件需要从模型中获取主键
+	return db.Model(model).Updates(values).Error
+}
+
+// Find 根据主键查询
+func (r *Repo[T]) Find(ctx context.Context, entity T) (T, error) {
+	return r.First(ctx, entity)
+}
+
+// First 查询第一条，entity 的主键不为空时作为条件
+func (r *Repo[T]) First(ctx context.Context, entity T, fns ...func(db *gorm.DB) *gorm.DB) (T, error) {
+	err := r.DB(ctx, fns...).First(&entity).Error
+	return entity, err
+}
+
+// Page 分页查询，按请求中的 orderBy 排序(见 scopes.Sort)，没有指定排序时按主键倒序
+// 请求中有 keyset 或 cursor 时使用游标分页(见 scopes.Keyset)，总数按请求中的 total 统计
+// 模型实现 search.Searchable 时按请求中的 keyword 搜索
+func (r *Repo[T]) Page(ctx context.Context, page request.PageSearch, fns ...func(db *gorm.DB) *gorm.DB) ([]T, response.PageInfo, error) {
+	var (
+		list []T
+		info response.PageInfo
+		err  error
+	)
+	db := r.DB(ctx, fns...).Scopes(search.Scope(page.Keyword))
+	switch page.TotalMode() {
+	case request.TotalExact:
+		err = db.Count(&info.Total).Error
+	case request.TotalEstimate:
+		info.Total, info.TotalEstimated, err = scopes.EstimateCount(db)
+	}
+	if err != nil {
+		return nil, info, err
+	}
+
+	if !page.UseKeyset() {
+		err = db.Scopes(scopes.Paginate(page), scopes.Sort(page.OrderBy), defaultOrder).Find(&list).Error
+		return list, info, err
+	}
+
+	keyset := scopes.NewKeyset(page)
+	if err = db.Scopes(keyset.Scope).Find(&list).Error; err != nil {
+		return nil, info, err
+	}
+	if len(list) > keyset.Limit() {
+		list = list[:keyset.Limit()]
+		info.HasMore = true
+		info.NextCursor, err = keyset.Cursor(&list[len(list)-1])
+	}
+	return list, info, err
+}
+
+// withID 返回设置了主键的模型
+func (r *Repo[T]) withID(db *gorm.DB, id uint) (*T, error) {
+	model := new(T)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil, gorm.ErrPrimaryKeyRequired
+	}
+	if err := field.Set(db.Statement.Context, reflect.ValueOf(model).Elem(), id); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// defaultOrder 没有排序时按主键倒序，需要放在其他 scopes 之后
+func defaultOrder(db *gorm.DB) *gorm.DB {
+	if _, ok := db.Statement.Clauses["ORDER BY"]; ok {
+		return db
+	}
+	if err := db.Statement.Parse(db.Statement.Model); err != nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return db
+	}
+	return db.Order(clause.OrderByColumn{
+		Column: clause.Column{Table: clause.CurrentTable, Name: db.Statement.Schema.PrioritizedPrimaryField.DBName},
+		Desc:   true,
+	})
+}
@@ -0,0 +1,170 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type repoArticle struct {
+	model.Model
+	Title    string `json:"title"`
+	Comments []repoComment
+}
+
+func (repoArticle) SortFields() []string {
+	return []string{"id", "title"}
+}
+
+func (repoArticle) SearchFields() []string {
+	return []string{"title"}
+}
+
+type repoComment struct {
+	ID            uint
+	RepoArticleID uint
+	Body          string
+}
+
+var repoArticleUpdatePolicy = policy.NewUpdatePolicy("repoArticle",
+	policy.Field("title", policy.Validate("required")),
+)
+
+func setupRepo(t *testing.T) *Repo[repoArticle] {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	assert.NoError(t, db.AutoMigrate(&repoArticle{}, &repoComment{}))
+
+	old := global.DB
+	global.DB = global.NewData(db)
+	t.Cleanup(func() { global.DB = old })
+	return &Repo[repoArticle]{}
+}
+
+func TestRepo_CRUD(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+
+	article := repoArticle{Title: "a", Comments: []repoComment{{Body: "c1"}, {Body: "c2"}}}
+	assert.NoError(t, repo.Create(ctx, &article))
+	assert.NotZero(t, article.ID)
+
+	// 更新策略
+	assert.NoError(t, repo.UpdateWith(ctx, repoArticleUpdatePolicy, map[string]interface{}{"id": article.ID, "title": "b"}))
+	assert.ErrorIs(t, repo.UpdateWith(ctx, repoArticleUpdatePolicy, map[string]interface{}{"id": article.ID, "body": "x"}), policy.ErrFieldNotAllowed)
+
+	found, err := repo.Find(ctx, repoArticle{Model: model.Model{ID: article.ID}})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", found.Title)
+	assert.Empty(t, found.Comments)
+
+	// 预加载
+	found, err = repo.First(ctx, repoArticle{Model: model.Model{ID: article.ID}}, scopes.Preload("Comments"))
+	assert.NoError(t, err)
+	assert.Len(t, found.Comments, 2)
+
+	_, err = repo.First(ctx, repoArticle{}, scopes.Where("title = ?", "a"))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// 软删除
+	assert.NoError(t, repo.Delete(ctx, found))
+	_, err = repo.Find(ctx, repoArticle{Model: model.Model{ID: article.ID}})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	found, err = repo.First(ctx, repoArticle{Model: model.Model{ID: article.ID}}, scopes.OnlyDeleted())
+	assert.NoError(t, err)
+	assert.True(t, found.DeletedAt.Valid)
+}
+
+func TestRepo_Page(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, repo.Create(ctx, &repoArticle{Title: title, Comments: []repoComment{{Body: title}}}))
+	}
+	assert.NoError(t, repo.DeleteByIds(ctx, request.Ids{Ids: []int{5}}))
+
+	titles := func(list []repoArticle) []string {
+		res := make([]string, 0, len(list))
+		for _, article := range list {
+			res = append(res, article.Title)
+		}
+		return res
+	}
+
+	// 默认按主键倒序
+	list, info, err := repo.Page(ctx, request.PageSearch{PageNum: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), info.Total)
+	assert.Equal(t, []string{"d", "c"}, titles(list))
+
+	// 条件、排序、预加载
+	list, info, err = repo.Page(ctx, request.PageSearch{PageNum: 1, PageSize: 10, OrderBy: "title"},
+		scopes.Where("title IN ?", []string{"a", "c", "e"}), scopes.Preload("Comments"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), info.Total)
+	assert.Equal(t, []string{"a", "c"}, titles(list))
+	assert.Equal(t, "c", list[1].Comments[0].Body)
+
+	// 关键词搜索
+	list, info, err = repo.Page(ctx, request.PageSearch{PageNum: 1, PageSize: 10, Keyword: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), info.Total)
+	assert.Equal(t, []string{"b"}, titles(list))
+
+	_, _, err = repo.Page(ctx, request.PageSearch{OrderBy: "createdAt"})
+	assert.ErrorIs(t, err, scopes.ErrInvalidSort)
+
+	list, info, err = repo.Page(ctx, request.PageSearch{NoPage: true}, scopes.WithDeleted())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), info.Total)
+	assert.Len(t, list, 5)
+
+	// 游标分页，默认不统计总数
+	list, info, err = repo.Page(ctx, request.PageSearch{Keyset: true, PageSize: 3, OrderBy: "title"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, titles(list))
+	assert.True(t, info.HasMore)
+	assert.NotEmpty(t, info.NextCursor)
+	assert.Zero(t, info.Total)
+
+	list, info, err = repo.Page(ctx, request.PageSearch{Cursor: info.NextCursor, PageSize: 3, OrderBy: "title", Total: request.TotalExact})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d"}, titles(list))
+	assert.False(t, info.HasMore)
+	assert.Empty(t, info.NextCursor)
+	assert.Equal(t, int64(4), info.Total)
+
+	_, info, err = repo.Page(ctx, request.PageSearch{PageNum: 1, PageSize: 2, Total: request.TotalEstimate})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), info.Total)
+	assert.False(t, info.TotalEstimated)
+}
+
+func TestRepo_Tx(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+
+	err := global.DB.Tx(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, &repoArticle{Title: "a"}); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.EqualError(t, err, "rollback")
+
+	_, info, err := repo.Page(ctx, request.PageSearch{NoPage: true})
+	assert.NoError(t, err)
+	assert.Zero(t, info.Total)
+}
@@ -2,8 +2,6 @@ package data
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
@@ -25,19 +23,6 @@ func (s *SysCasbinRepo) DeleteByIds(ctx context.Context, ids request.Ids) error
 	return global.DB.WithContext(ctx).Delete(&[]domain.SysCasbin{}, ids.Ids).Error
 }
 
-func (s *SysCasbinRepo) Update(ctx context.Context, sysCasbin map[string]interface{}) error {
-	var columns []string
-	for key := range sysCasbin {
-		columns = append(columns, key)
-	}
-	if _, ok := sysCasbin["id"]; !ok {
-		// 不存在id
-		return errors.New(fmt.Sprintf("missing %s.id", "sysCasbin"))
-	}
-	model := domain.SysCasbin{}
-	return global.DB.WithContext(ctx).Model(&model).Select(columns).Updates(&sysCasbin).Error
-}
-
 func (s *SysCasbinRepo) Find(ctx context.Context, sysCasbin domain.SysCasbin) (domain.SysCasbin, error) {
 	db := global.DB.WithContext(ctx).Model(&domain.SysCasbin{})
 	// TODO：条件过滤
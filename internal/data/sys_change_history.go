@@ -0,0 +1,17 @@
+package data
+
+import (
+	"context"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+)
+
+type SysChangeHistoryRepo struct {
+	Repo[domain.SysChangeHistory]
+}
+
+func (s *SysChangeHistoryRepo) List(ctx context.Context, page domain.PageSysChangeHistorySearch) ([]domain.SysChangeHistory, response.PageInfo, error) {
+	return s.Page(ctx, page.PageSearch, scopes.Where("table_name = ? AND record_id = ?", page.Table, page.RecordID))
+}
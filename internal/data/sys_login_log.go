@@ -0,0 +1,25 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+)
+
+type SysLoginLogRepo struct {
+	Repo[domain.SysLoginLog]
+}
+
+func (s *SysLoginLogRepo) List(ctx context.Context, page domain.PageSysLoginLogSearch) ([]domain.SysLoginLog, response.PageInfo, error) {
+	return s.Page(ctx, page.PageSearch, scopes.Filter(page))
+}
+
+// DeleteBefore 物理删除 before 之前的日志
+func (s *SysLoginLogRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := global.DB.WithContext(ctx).Unscoped().Where("created_at < ?", before).Delete(&domain.SysLoginLog{})
+	return res.RowsAffected, res.Error
+}
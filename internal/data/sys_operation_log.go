@@ -0,0 +1,17 @@
+package data
+
+import (
+	"context"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+)
+
+type SysOperationLogRepo struct {
+	Repo[domain.SysOperationLog]
+}
+
+func (s *SysOperationLogRepo) List(ctx context.Context, page domain.PageSysOperationLogSearch) ([]domain.SysOperationLog, response.PageInfo, error) {
+	return s.Page(ctx, page.PageSearch, scopes.Filter(page))
+}
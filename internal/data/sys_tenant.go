@@ -0,0 +1,32 @@
+package data
+
+import (
+	"context"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+)
+
+type SysTenantRepo struct {
+	Repo[domain.SysTenant]
+}
+
+func (s *SysTenantRepo) Update(ctx context.Context, sysTenant map[string]interface{}) error {
+	return s.UpdateWith(ctx, domain.SysTenantUpdatePolicy, sysTenant)
+}
+
+// SetDSN 修改租户的独立数据库连接字符串
+func (s *SysTenantRepo) SetDSN(ctx context.Context, id uint, dsn string) error {
+	return global.DB.WithContext(ctx).Model(&domain.SysTenant{}).Where("id = ?", id).Update("dsn", dsn).Error
+}
+
+// FindByCode 根据编码查询租户
+func (s *SysTenantRepo) FindByCode(ctx context.Context, code string) (domain.SysTenant, error) {
+	return s.First(ctx, domain.SysTenant{}, scopes.Where("code = ?", code))
+}
+
+func (s *SysTenantRepo) List(ctx context.Context, page domain.PageSysTenantSearch) ([]domain.SysTenant, response.PageInfo, error) {
+	return s.Page(ctx, page.PageSearch, scopes.Filter(page))
+}
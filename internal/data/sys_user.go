@@ -2,66 +2,60 @@ package data
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"slices"
+
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
-	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
-	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/pagelimit"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"gorm.io/gorm"
 )
 
 type SysUserRepo struct {
-}
-
-func (s *SysUserRepo) Create(ctx context.Context, sysUser domain.SysUser) error {
-	return global.DB.WithContext(ctx).Create(&sysUser).Error
-}
-
-func (s *SysUserRepo) Delete(ctx context.Context, sysUser domain.SysUser) error {
-	return global.DB.WithContext(ctx).Delete(&sysUser).Error
-}
-
-func (s *SysUserRepo) DeleteByIds(ctx context.Context, ids request.Ids) error {
-	return global.DB.WithContext(ctx).Delete(&[]domain.SysUser{}, ids.Ids).Error
+	Repo[domain.SysUser]
 }
 
 func (s *SysUserRepo) Update(ctx context.Context, sysUser map[string]interface{}) error {
-	var columns []string
-	for key := range sysUser {
-		columns = append(columns, key)
-	}
-	if _, ok := sysUser["id"]; !ok {
-		// 不存在id
-		return errors.New(fmt.Sprintf("missing %s.id", "sysUser"))
-	}
-	model := domain.SysUser{}
-	model.ID = uint(sysUser["id"].(float64))
-	return global.DB.WithContext(ctx).Model(&model).Select(columns).Updates(&sysUser).Error
+	return s.UpdateWith(ctx, domain.SysUserUpdatePolicy, sysUser)
 }
 
 func (s *SysUserRepo) Find(ctx context.Context, sysUser domain.SysUser) (domain.SysUser, error) {
-	db := global.DB.WithContext(ctx).Model(&domain.SysUser{})
-	// TODO：条件过滤
-
-	res := db.Preload("Roles", "parent_id = ?", 0).First(&sysUser)
+	return s.First(ctx, sysUser, scopes.Preload("Roles", "parent_id = ?", 0))
+}
 
-	return sysUser, res.Error
+func (s *SysUserRepo) List(ctx context.Context, page domain.PageSysUserSearch) ([]domain.SysUser, response.PageInfo, error) {
+	return s.Page(ctx, page.PageSearch, scopes.Preload("Roles"), scopes.Filter(page))
 }
 
-func (s *SysUserRepo) List(ctx context.Context, page domain.PageSysUserSearch) ([]domain.SysUser, int64, error) {
-	var (
-		sysUserList []domain.SysUser
-		count       int64
-		err         error
-	)
-	// db
-	db := global.DB.WithContext(ctx).Model(&domain.SysUser{})
-	// page
-	offset, limit := pagelimit.OffsetLimit(page.PageNum, page.PageSize)
+// DataScope 用户的数据权限，只能查询和 actor 同部门的用户，以及拥有 roleID 或其子角色的用户
+func (s *SysUserRepo) DataScope(ctx context.Context, actor, roleID uint) (func(db *gorm.DB) *gorm.DB, error) {
+	db := global.DB.WithContext(ctx)
 
-	// TODO：条件过滤
+	var departments []string
+	err := db.Model(&domain.SysUser{}).Where("id = ?", actor).Pluck("department", &departments).Error
+	if err != nil {
+		return nil, err
+	}
 
-	err = db.Count(&count).Offset(offset).Limit(limit).Preload("Roles").Find(&sysUserList).Error
+	// 当前角色及其所有子角色
+	roleIDs := []uint{roleID}
+	for parents := roleIDs; len(parents) > 0; {
+		var children []uint
+		err = db.Model(&domain.SysRole{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error
+		if err != nil {
+			return nil, err
+		}
+		children = slices.DeleteFunc(children, func(id uint) bool { return slices.Contains(roleIDs, id) })
+		roleIDs = append(roleIDs, children...)
+		parents = children
+	}
 
-	return sysUserList, count, err
+	return func(db *gorm.DB) *gorm.DB {
+		users := db.Session(&gorm.Session{NewDB: true}).Table("sys_user_sys_role").
+			Select("sys_user_id").Where("sys_role_id IN ?", roleIDs)
+		if len(departments) == 0 || departments[0] == "" {
+			return db.Where("id IN (?)", users)
+		}
+		return db.Where("department = ? OR id IN (?)", departments[0], users)
+	}, nil
 }
@@ -0,0 +1,16 @@
+package domain
+
+// AggregateMetric 统计指标
+type AggregateMetric struct {
+	Func  string // 统计函数 count、sum、avg、min、max
+	Field string // 统计字段(json名称)，count 可以为空
+}
+
+// AggregateReq 聚合统计，用于看板等场景，过滤条件与资源列表接口的查询参数一致
+type AggregateReq struct {
+	Resource  string `json:"-" uri:"resource" binding:"required"`                                                                      // 资源名，需要在 service.RegisterAggregate 中注册
+	GroupBy   string `json:"groupBy" form:"groupBy"`                                                                                   // 分组字段(json名称) 逗号隔开
+	Metrics   string `json:"metrics" form:"metrics" example:"count,sum:success_count"`                                                 // 统计指标 逗号隔开，格式为 函数:字段，为空时统计数量
+	Bucket    string `json:"bucket" form:"bucket" binding:"omitempty,oneof=hour day week month year" enums:"hour,day,week,month,year"` // 按时间分组的粒度
+	TimeField string `json:"timeField" form:"timeField"`                                                                               // 按时间分组的字段(json名称)，为空时使用资源的第一个时间字段
+}
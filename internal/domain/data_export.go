@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"gorm.io/datatypes"
+)
+
+// DataExport 异步导出记录，数据量超过 export.async-threshold 时创建
+type DataExport struct {
+	model.AuditModel
+	model.TenantModel
+	Resource string         `gorm:"type:varchar(64);index;not null;" json:"resource" form:"resource" filter:"eq"`               // 导出的列表，见 service.RegisterExport
+	Format   string         `gorm:"type:varchar(8);not null;" json:"format"`                                                    // 文件格式 xlsx、csv
+	FileName string         `gorm:"type:varchar(128);not null;" json:"filename"`                                                // 下载的文件名
+	FileUrl  string         `gorm:"type:varchar(512);default:'';not null;" json:"-"`                                            // 文件路径，通过 /dataExport/{id}/download 下载
+	Status   string         `gorm:"type:varchar(16);index;default:exporting;not null;" json:"status" form:"status" filter:"eq"` // 状态 exporting：导出中，success：导出成功，failed：导出失败
+	Count    uint           `gorm:"type:int;default:0;not null;" json:"count"`                                                  // 导出数量
+	Reason   string         `gorm:"type:varchar(255);default:'';not null;" json:"reason"`                                       // 失败原因
+	Query    datatypes.JSON `gorm:"type:json" json:"-" swaggerignore:"true"`                                                    // 查询条件
+}
+
+type PageDataExportSearch struct {
+	DataExport
+	request.PageSearch
+}
+
+func (DataExport) TableName() string {
+	return "data_export"
+}
+
+// SortFields 允许排序的字段
+func (DataExport) SortFields() []string {
+	return []string{"id", "createdAt", "status"}
+}
@@ -1,7 +1,6 @@
 package domain
 
 import (
-	"encoding/json"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"gorm.io/datatypes"
@@ -13,17 +12,17 @@ type FailedReason struct {
 }
 
 type DataImport struct {
-	model.Model
-	FileName string `gorm:"type:varchar(64);not null;" json:"filename"`
+	model.AuditModel
+	model.TenantModel
+	FileName string `gorm:"type:varchar(64);not null;" json:"filename" excel:"文件名"`
 	FileUrl  string `gorm:"type:varchar(512);not null;" json:"file_url"`
 	// importing： 导入中，success：导入成功，导入失败：failed
-	Status        string                            `gorm:"type:varchar(16);index;default:importing;not null;" json:"status" form:"status"`
-	Category      string                            `gorm:"type:varchar(32);index;default:'';not null;" json:"category" form:"category"`
-	Count         uint                              `gorm:"type:int;default:0;not null;" json:"count"`
-	SuccessCount  uint                              `gorm:"type:int;default:0;not null;" json:"success_count"`
-	FailureCount  uint                              `gorm:"type:int;default:0;not null;" json:"failure_count"`
-	FailedReasons datatypes.JSONSlice[FailedReason] `gorm:"type:json" json:"failed_reasons"`    // 错误信息
-	Data          json.RawMessage                   `json:"data" gorm:"-" swaggerignore:"true"` // 导入数据
+	Status        string                            `gorm:"type:varchar(16);index;default:importing;not null;" json:"status" form:"status" filter:"eq" excel:"head:状态;select:importing=导入中,success=导入成功,failed=导入失败"`
+	Category      string                            `gorm:"type:varchar(32);index;default:'';not null;" json:"category" form:"category" filter:"eq" excel:"类型"`
+	Count         uint                              `gorm:"type:int;default:0;not null;" json:"count" excel:"总数"`
+	SuccessCount  uint                              `gorm:"type:int;default:0;not null;" json:"success_count" excel:"成功数"`
+	FailureCount  uint                              `gorm:"type:int;default:0;not null;" json:"failure_count" excel:"失败数"`
+	FailedReasons datatypes.JSONSlice[FailedReason] `gorm:"type:json" json:"failed_reasons"` // 错误信息
 }
 
 type PageDataImportSearch struct {
@@ -34,3 +33,8 @@ type PageDataImportSearch struct {
 func (DataImport) TableName() string {
 	return "data_import"
 }
+
+// SortFields 允许排序的字段
+func (DataImport) SortFields() []string {
+	return []string{"id", "createdAt", "status", "category"}
+}
@@ -7,19 +7,35 @@ import (
 )
 
 type Demo struct {
-	model.Model
-	Name     string                      `json:"name" form:"name"`
-	Age      int                         `json:"age"`
-	BirthDay *model.LocalTime            `json:"birth_day"`
-	Tags     datatypes.JSONSlice[string] `json:"tags"`
+	model.AuditModel
+	model.Version
+	Name     string                      `json:"name" form:"name" filter:"like" excel:"名称"`
+	Age      int                         `json:"age" excel:"年龄"`
+	BirthDay *model.LocalTime            `json:"birth_day" excel:"head:生日;type:date"`
+	Tags     datatypes.JSONSlice[string] `json:"tags" excel:"标签"`
 }
 
 type PageDemoSearch struct {
 	Demo
 	request.PageSearch
-	TagsQuery string `json:"tagsQuery" form:"tagsQuery"`
+	TagsQuery string `json:"tagsQuery" form:"tagsQuery" filter:"json_contains;column:tags"` // 标签，逗号分隔，需要包含全部标签
 }
 
 func (Demo) TableName() string {
 	return "demo"
 }
+
+// SortFields 允许排序的字段
+func (Demo) SortFields() []string {
+	return []string{"id", "createdAt", "updatedAt", "name", "age", "birth_day"}
+}
+
+// SearchFields 关键词搜索的字段
+func (Demo) SearchFields() []string {
+	return []string{"name"}
+}
+
+// HistoryMaskFields 记录变更历史
+func (Demo) HistoryMaskFields() []string {
+	return nil
+}
@@ -1,17 +1,20 @@
 package domain
 
-import "github.com/Madou-Shinni/gin-quickstart/pkg/request"
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+)
 
 type File struct {
-	ID           int64  `json:"id,omitempty" form:"id" gorm:"column:id;comment:主键;primarykey"`                          // 文件唯一标识
-	FileMd5      string `json:"fileMd5,omitempty" form:"fileMd5" gorm:"column:file_md5;comment:文件MD5"`                  // 文件MD5
-	FileSize     string `json:"fileSize,omitempty" form:"fileSize" gorm:"column:file_size;comment:文件大小"`                // 文件大小
-	FilePath     string `json:"filePath,omitempty" form:"filePath" gorm:"column:file_path;comment:文件路径"`                // 文件路径
-	FileName     string `json:"fileName,omitempty" form:"fileName" gorm:"column:file_name;comment:文件名"`                 // 文件名
-	TotalChunk   int    `json:"totalChunk,omitempty" form:"totalChunk" gorm:"column:total_chunk;comment:文件总分片数"`        // 文件总分片数
-	AlreadyChunk string `json:"alreadyChunk,omitempty" form:"alreadyChunk" gorm:"column:already_chunk;comment:已经上传的分片"` // 已经上传的分片
-	Index        int    `json:"index,omitempty" form:"index" gorm:"-"`                                                  // 当前分片
-	IsFinish     bool   `json:"isFinish,omitempty" form:"isFinish" gorm:"-"`                                            // 是否完成
+	ID           int64  `json:"id,omitempty" form:"id" gorm:"column:id;comment:主键;primarykey"`                                    // 文件唯一标识
+	FileMd5      string `json:"fileMd5,omitempty" form:"fileMd5" gorm:"column:file_md5;comment:文件MD5"`                            // 文件MD5
+	FileSize     string `json:"fileSize,omitempty" form:"fileSize" gorm:"column:file_size;comment:文件大小" excel:"文件大小"`             // 文件大小
+	FilePath     string `json:"filePath,omitempty" form:"filePath" gorm:"column:file_path;comment:文件路径" excel:"文件路径"`             // 文件路径
+	FileName     string `json:"fileName,omitempty" form:"fileName" gorm:"column:file_name;comment:文件名" filter:"like" excel:"文件名"` // 文件名
+	TotalChunk   int    `json:"totalChunk,omitempty" form:"totalChunk" gorm:"column:total_chunk;comment:文件总分片数"`                  // 文件总分片数
+	AlreadyChunk string `json:"alreadyChunk,omitempty" form:"alreadyChunk" gorm:"column:already_chunk;comment:已经上传的分片"`           // 已经上传的分片
+	Index        int    `json:"index,omitempty" form:"index" gorm:"-"`                                                            // 当前分片
+	IsFinish     bool   `json:"isFinish,omitempty" form:"isFinish" gorm:"-"`                                                      // 是否完成
 }
 
 type PageFileSearch struct {
@@ -22,3 +25,16 @@ type PageFileSearch struct {
 func (File) TableName() string {
 	return "file"
 }
+
+// SortFields 允许排序的字段
+func (File) SortFields() []string {
+	return []string{"id", "fileName"}
+}
+
+// FileUpdatePolicy 允许修改的字段
+var FileUpdatePolicy = policy.NewUpdatePolicy("file",
+	policy.Field("fileName", policy.Column("file_name"), policy.Validate("required,max=255")),
+	policy.Field("filePath", policy.Column("file_path")),
+	policy.Field("totalChunk", policy.Column("total_chunk"), policy.Validate("gte=0")),
+	policy.Field("alreadyChunk", policy.Column("already_chunk")),
+)
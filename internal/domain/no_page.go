@@ -0,0 +1,9 @@
+package domain
+
+// NoPageReq 不分页查询，用于下拉框等场景
+type NoPageReq struct {
+	Table              string `json:"-" uri:"table" binding:"required"`             // 资源名，需要在 service.RegisterNoPage 中注册
+	Keyword            string `json:"keyword" form:"keyword"`                       // 关键词
+	Select             string `json:"select" form:"select"`                         // 查询字段(json名称) 逗号隔开，为空时返回资源允许的全部字段
+	FindConditionsCols string `json:"findConditionsCols" form:"findConditionsCols"` // 搜索字段(json名称) 逗号隔开，为空时搜索资源允许的全部字段
+}
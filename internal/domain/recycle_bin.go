@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+)
+
+// RecycleBinItem 回收站中的一条记录
+type RecycleBinItem struct {
+	ID        uint             `json:"id"`
+	DeletedBy uint             `json:"deleted_by"` // 删除人id
+	Deleter   string           `json:"deleter"`    // 删除人昵称
+	DeletedAt *model.LocalTime `json:"deleted_at"` // 删除时间
+	Data      interface{}      `json:"data"`       // 被删除的数据，HistoryMaskFields 声明的列(例如密码)为空
+}
+
+type PageRecycleBinSearch struct {
+	Domain string `uri:"domain" binding:"required"` // 表名 demo、sys_user、sys_role、sys_menu、data_import
+	request.PageSearch
+}
+
+type RecycleBinReq struct {
+	Domain string `uri:"domain" binding:"required" json:"-"`
+	Ids    []uint `json:"ids" binding:"required,min=1"`
+}
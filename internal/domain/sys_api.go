@@ -2,16 +2,17 @@ package domain
 
 import (
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 )
 
 type SysApi struct {
-	ID        uint             `gorm:"primarykey" json:"id" form:"id" uri:"id"`
-	CreatedAt *model.LocalTime `json:"createdAt" form:"createdAt" swaggerignore:"true"`
+	ID        uint             `gorm:"primarykey" json:"id" form:"id" uri:"id" excel:"ID"`
+	CreatedAt *model.LocalTime `json:"createdAt" form:"createdAt" swaggerignore:"true" excel:"创建时间"`
 	UpdatedAt *model.LocalTime `json:"updatedAt" form:"updatedAt" swaggerignore:"true"`
-	Name      string           `json:"name" gorm:"name"`
-	Method    string           `json:"method" gorm:"index:idx_method_path,unique"`
-	Path      string           `json:"path" gorm:"index:idx_method_path,unique"`
+	Name      string           `json:"name" form:"name" gorm:"name" filter:"like" excel:"接口名称"`
+	Method    string           `json:"method" form:"method" gorm:"index:idx_method_path,unique" filter:"eq" excel:"请求方法"`
+	Path      string           `json:"path" form:"path" gorm:"index:idx_method_path,unique" filter:"like" excel:"请求路径"`
 }
 
 type PageSysApiSearch struct {
@@ -22,3 +23,15 @@ type PageSysApiSearch struct {
 func (SysApi) TableName() string {
 	return "sys_api"
 }
+
+// SortFields 允许排序的字段
+func (SysApi) SortFields() []string {
+	return []string{"id", "createdAt", "name", "method", "path"}
+}
+
+// SysApiUpdatePolicy 允许修改的字段
+var SysApiUpdatePolicy = policy.NewUpdatePolicy("sysApi",
+	policy.Field("name", policy.Validate("max=255")),
+	policy.Field("method", policy.Validate("oneof=GET POST PUT DELETE PATCH")),
+	policy.Field("path", policy.Validate("required,startswith=/")),
+)
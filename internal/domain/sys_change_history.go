@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"gorm.io/datatypes"
+)
+
+type SysChangeHistory struct {
+	ID        uint                                         `gorm:"primarykey" json:"id" excel:"ID"`
+	CreatedAt *model.LocalTime                             `json:"createdAt" excel:"变更时间"`
+	Table     string                                       `gorm:"column:table_name;size:64;index:idx_table_record;not null" json:"table_name" excel:"表名"`       // 表名
+	RecordID  string                                       `gorm:"size:64;index:idx_table_record;not null" json:"record_id" excel:"记录主键"`                        // 记录主键
+	Action    string                                       `gorm:"type:varchar(16);not null" json:"action" excel:"head:操作;select:create=新增,update=修改,delete=删除"` // create：新增，update：修改，delete：删除
+	Changes   datatypes.JSONSlice[gorm_plugin.FieldChange] `gorm:"type:json" json:"changes" excel:"字段变更"`                                                        // 字段变更
+	ActorID   uint                                         `gorm:"index" json:"actor_id" excel:"操作人id"`                                                          // 操作人id，0为系统
+	TraceID   string                                       `gorm:"size:64;index;default:''" json:"trace_id" excel:"链路id"`                                        // 链路id
+}
+
+type PageSysChangeHistorySearch struct {
+	Table    string `uri:"table" binding:"required"`
+	RecordID string `uri:"id" binding:"required"`
+	request.PageSearch
+}
+
+func (SysChangeHistory) TableName() string {
+	return "sys_change_history"
+}
+
+// NewSysChangeHistory 将变更记录转换为变更历史
+func NewSysChangeHistory(record gorm_plugin.ChangeRecord) SysChangeHistory {
+	return SysChangeHistory{
+		Table:    record.Table,
+		RecordID: record.RecordID,
+		Action:   record.Action,
+		Changes:  record.Changes,
+		ActorID:  record.Actor,
+		TraceID:  record.TraceID,
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+)
+
+type SysLoginLog struct {
+	model.Model
+	UserID    uint   `gorm:"column:user_id;index" json:"user_id" form:"user_id" filter:"eq"`                                                                                        // 用户id，账号不存在时为0
+	Account   string `gorm:"size:255;index;not null" json:"account" form:"account" filter:"eq" excel:"登录账号"`                                                                        // 登录账号
+	LoginType string `gorm:"type:varchar(16);index;not null" json:"login_type" form:"login_type" filter:"eq" excel:"head:登录方式;select:password=账号密码,sms=短信验证码,oauth=第三方登录,2fa=二次验证"` // 登录方式 password：账号密码，sms：短信验证码，oauth：第三方登录，2fa：二次验证
+	Result    string `gorm:"type:varchar(16);index;not null" json:"result" form:"result" filter:"eq" excel:"head:登录结果;select:success=成功,failed=失败"`                                 // 登录结果 success：成功，failed：失败
+	Reason    string `gorm:"size:255;default:''" json:"reason" excel:"失败原因"`                                                                                                        // 失败原因
+	IP        string `gorm:"size:64;index;default:''" json:"ip" form:"ip" filter:"eq" excel:"登录IP"`                                                                                 // 登录IP
+	Location  string `gorm:"size:128;default:''" json:"location" excel:"IP归属地"`                                                                                                     // IP归属地
+	UserAgent string `gorm:"size:512;default:''" json:"user_agent"`                                                                                                                 // User-Agent
+	Browser   string `gorm:"size:64;default:''" json:"browser" excel:"浏览器"`                                                                                                         // 浏览器
+	OS        string `gorm:"column:os;size:64;default:''" json:"os" excel:"操作系统"`                                                                                                   // 操作系统
+}
+
+type PageSysLoginLogSearch struct {
+	SysLoginLog
+	StartTime string `json:"start_time" form:"start_time" binding:"omitempty,datetime=2006-01-02 15:04:05" filter:"gte;column:created_at"` // 登录时间起
+	EndTime   string `json:"end_time" form:"end_time" binding:"omitempty,datetime=2006-01-02 15:04:05" filter:"lte;column:created_at"`     // 登录时间止
+	request.PageSearch
+}
+
+func (SysLoginLog) TableName() string {
+	return "sys_login_log"
+}
+
+// SortFields 允许排序的字段
+func (SysLoginLog) SortFields() []string {
+	return []string{"id", "createdAt", "account", "result"}
+}
+
+// LoginClient 登录客户端信息
+type LoginClient struct {
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// LoginEvent 登录事件，各登录方式(密码、短信、第三方、二次验证)统一通过它记录登录日志
+type LoginEvent struct {
+	LoginClient
+	UserID    uint
+	Account   string
+	LoginType string
+	Err       error // 为空则登录成功
+}
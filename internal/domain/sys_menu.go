@@ -2,15 +2,16 @@ package domain
 
 import (
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 )
 
 type SysMenu struct {
-	model.Model
-	Name        string    `gorm:"size:255;unique;not null" json:"name"` // 菜单名称
-	Icon        string    `gorm:"size:255;not null" json:"icon"`        // 图标
-	ParentID    uint      `gorm:"default:0" json:"parent_id"`           // 上层菜单
-	Description string    `gorm:"size:255;" json:"description"`         // 描述
+	model.AuditModel
+	Name        string    `gorm:"size:255;unique;not null" json:"name" form:"name" filter:"like" excel:"菜单名称"` // 菜单名称
+	Icon        string    `gorm:"size:255;not null" json:"icon" excel:"图标"`                                    // 图标
+	ParentID    uint      `gorm:"default:0" json:"parent_id"`                                                  // 上层菜单
+	Description string    `gorm:"size:255;" json:"description" excel:"描述"`                                     // 描述
 	Children    []SysMenu `gorm:"-" json:"children"`
 }
 
@@ -22,3 +23,16 @@ type PageSysMenuSearch struct {
 func (SysMenu) TableName() string {
 	return "sys_menu"
 }
+
+// SortFields 允许排序的字段
+func (SysMenu) SortFields() []string {
+	return []string{"id", "createdAt", "name"}
+}
+
+// SysMenuUpdatePolicy 允许修改的字段
+var SysMenuUpdatePolicy = policy.NewUpdatePolicy("sysMenu",
+	policy.Field("name", policy.Validate("required,max=255")),
+	policy.Field("icon", policy.Validate("max=255")),
+	policy.Field("parent_id", policy.Validate("gte=0")),
+	policy.Field("description", policy.Validate("max=255")),
+)
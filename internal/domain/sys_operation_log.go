@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+)
+
+type SysOperationLog struct {
+	model.Model
+	UserID    uint   `gorm:"column:user_id;index" json:"user_id" form:"user_id" filter:"eq" excel:"操作人id"`         // 操作人id，未登录为0
+	ApiName   string `gorm:"size:255;default:''" json:"api_name" form:"api_name" filter:"like" excel:"接口名称"`       // 接口名称，取自sys_api
+	Method    string `gorm:"type:varchar(16);index;not null" json:"method" form:"method" filter:"eq" excel:"请求方法"` // 请求方法
+	Path      string `gorm:"size:255;index;not null" json:"path" form:"path" filter:"eq" excel:"请求路径"`             // 请求路径
+	Route     string `gorm:"size:255;default:''" json:"route"`                                                     // 路由模板，例如 /sysUser/:id
+	Query     string `gorm:"type:text" json:"query"`                                                               // 查询参数(已脱敏)
+	Body      string `gorm:"type:text" json:"body"`                                                                // 请求体(已脱敏)
+	Status    int    `gorm:"type:int;default:0;not null" json:"status" excel:"http状态码"`                            // http状态码
+	Code      int    `gorm:"type:int;index;default:0;not null" json:"code" form:"code" filter:"eq" excel:"业务状态码"`  // 业务状态码
+	Msg       string `gorm:"size:255;default:''" json:"msg" excel:"返回信息"`                                          // 业务返回信息
+	Latency   int64  `gorm:"type:bigint;default:0;not null" json:"latency" excel:"耗时(毫秒)"`                         // 耗时(毫秒)
+	IP        string `gorm:"size:64;index;default:''" json:"ip" form:"ip" filter:"eq" excel:"请求IP"`                // 请求IP
+	UserAgent string `gorm:"size:512;default:''" json:"user_agent"`                                                // User-Agent
+}
+
+type PageSysOperationLogSearch struct {
+	SysOperationLog
+	StartTime string `json:"start_time" form:"start_time" binding:"omitempty,datetime=2006-01-02 15:04:05" filter:"gte;column:created_at"` // 操作时间起
+	EndTime   string `json:"end_time" form:"end_time" binding:"omitempty,datetime=2006-01-02 15:04:05" filter:"lte;column:created_at"`     // 操作时间止
+	request.PageSearch
+}
+
+func (SysOperationLog) TableName() string {
+	return "sys_operation_log"
+}
+
+// SortFields 允许排序的字段
+func (SysOperationLog) SortFields() []string {
+	return []string{"id", "createdAt", "code", "latency"}
+}
@@ -2,13 +2,15 @@ package domain
 
 import (
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 )
 
 type SysRole struct {
-	model.Model
+	model.AuditModel
+	model.Version
 	ParentID uint      `gorm:"column:parent_id" json:"parent_id"`
-	RoleName string    `gorm:"column:role_name" json:"role_name"`
+	RoleName string    `gorm:"column:role_name" json:"role_name" form:"role_name" filter:"like" excel:"角色名称"`
 	Menus    []SysMenu `gorm:"many2many:sys_role_sys_menu;" json:"menus"` // 菜单列表
 	Children []SysRole `gorm:"-" json:"children"`                         // 角色列表
 }
@@ -21,3 +23,15 @@ type PageSysRoleSearch struct {
 func (SysRole) TableName() string {
 	return "sys_role"
 }
+
+// SortFields 允许排序的字段
+func (SysRole) SortFields() []string {
+	return []string{"id", "createdAt", "role_name"}
+}
+
+// SysRoleUpdatePolicy 允许修改的字段
+var SysRoleUpdatePolicy = policy.NewUpdatePolicy("sysRole",
+	policy.Field("parent_id", policy.Validate("gte=0")),
+	policy.Field("role_name", policy.Validate("required,max=255")),
+	policy.Field("version", policy.Required(), policy.Validate("gt=0")),
+)
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+)
+
+type SysTenant struct {
+	model.AuditModel
+	Name      string           `gorm:"size:255;not null" json:"name" form:"name" filter:"like" excel:"租户名称"`                                                                         // 租户名称
+	Code      string           `gorm:"size:64;unique;not null" json:"code" form:"code" filter:"eq" excel:"租户编码"`                                                                     // 租户编码，用于子域名
+	Plan      string           `gorm:"type:varchar(16);index;default:free;not null" json:"plan" form:"plan" filter:"eq" excel:"head:套餐;select:free=免费版,standard=标准版,enterprise=企业版"` // 套餐 free：免费版，standard：标准版，enterprise：企业版
+	Status    string           `gorm:"type:varchar(16);index;default:active;not null" json:"status" form:"status" filter:"eq" excel:"head:状态;select:active=正常,disabled=禁用"`          // 状态 active：正常，disabled：禁用
+	ExpiredAt *model.LocalTime `json:"expired_at" form:"expired_at" excel:"过期时间"`                                                                                                    // 过期时间，为空则永不过期
+	Remark    string           `gorm:"size:255;default:''" json:"remark" form:"remark" excel:"备注"`                                                                                   // 备注
+	DSN       string           `gorm:"column:dsn;size:512;default:''" json:"-"`                                                                                                      // 独立数据库连接字符串，为空则使用共享库
+}
+
+type PageSysTenantSearch struct {
+	SysTenant
+	request.PageSearch
+}
+
+func (SysTenant) TableName() string {
+	return "sys_tenant"
+}
+
+// SortFields 允许排序的字段
+func (SysTenant) SortFields() []string {
+	return []string{"id", "createdAt", "name", "code", "plan", "status", "expired_at"}
+}
+
+type AddSysTenantReq struct {
+	Name      string           `json:"name" binding:"required,max=255"`                         // 租户名称
+	Code      string           `json:"code" binding:"required,max=64,alphanum"`                 // 租户编码，字母和数字，不区分大小写
+	Plan      string           `json:"plan" binding:"omitempty,oneof=free standard enterprise"` // 套餐，默认免费版
+	ExpiredAt *model.LocalTime `json:"expired_at"`                                              // 过期时间，为空则永不过期
+	Remark    string           `json:"remark" binding:"max=255"`                                // 备注
+}
+
+type ProvisionSysTenantReq struct {
+	ID  uint   `json:"id" binding:"required"`  // 租户id
+	DSN string `json:"dsn" binding:"required"` // 独立数据库连接字符串，驱动与共享库一致
+}
+
+// SysTenantUpdatePolicy 允许修改的字段，编码不允许修改
+var SysTenantUpdatePolicy = policy.NewUpdatePolicy("sysTenant",
+	policy.Field("name", policy.Validate("required,max=255")),
+	policy.Field("plan", policy.Validate("oneof=free standard enterprise")),
+	policy.Field("status", policy.Validate("oneof=active disabled")),
+	policy.Field("expired_at", policy.Validate("omitempty,datetime=2006-01-02 15:04:05")),
+	policy.Field("remark", policy.Validate("max=255")),
+)
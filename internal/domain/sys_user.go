@@ -2,16 +2,23 @@ package domain
 
 import (
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 )
 
 type SysUser struct {
-	model.Model
-	Account     string    `gorm:"size:255;unique;not null" json:"account"`   // 账号
-	Password    string    `gorm:"size:255;not null" json:"password"`         // 密码
-	NickName    string    `gorm:"size:255;not null" json:"nick_name"`        // 昵称
-	DefaultRole uint      `gorm:"column:default_role" json:"default_role"`   // 当前角色
-	Roles       []SysRole `gorm:"many2many:sys_user_sys_role;" json:"roles"` // 角色列表
+	model.AuditModel
+	model.TenantModel
+	Account     string           `gorm:"size:255;unique;not null" json:"account" form:"account" filter:"like" excel:"账号"`                                                               // 账号
+	Password    string           `gorm:"size:255;not null" json:"password"`                                                                                                             // 密码
+	NickName    string           `gorm:"size:255;not null" json:"nick_name" form:"nick_name" filter:"like" excel:"昵称"`                                                                  // 昵称
+	Phone       string           `gorm:"size:20;index" json:"phone" form:"phone" filter:"eq" excel:"head:手机号;type:string"`                                                              // 手机号
+	Department  string           `gorm:"size:64;index" json:"department" form:"department" filter:"eq" excel:"部门"`                                                                      // 部门
+	DefaultRole uint             `gorm:"column:default_role" json:"default_role"`                                                                                                       // 当前角色
+	Status      string           `gorm:"type:varchar(16);index;default:active;not null" json:"status" form:"status" filter:"eq" excel:"head:状态;select:active=正常,disabled=禁用,locked=锁定"` // 状态 active：正常，disabled：禁用，locked：锁定
+	ExpiredAt   *model.LocalTime `json:"expired_at" form:"expired_at" excel:"过期时间"`                                                                                                     // 账号过期时间，为空则永不过期
+	SuperAdmin  bool             `gorm:"default:false;not null" json:"super_admin" swaggerignore:"true"`                                                                                // 超级管理员，只能在数据库中设置，接口中传入的值会被忽略
+	Roles       []SysRole        `gorm:"many2many:sys_user_sys_role;" json:"roles"`                                                                                                     // 角色列表
 }
 
 type PageSysUserSearch struct {
@@ -23,7 +30,43 @@ func (SysUser) TableName() string {
 	return "sys_user"
 }
 
+// SortFields 允许排序的字段
+func (SysUser) SortFields() []string {
+	return []string{"id", "createdAt", "account", "nick_name", "department", "status", "expired_at"}
+}
+
+// SearchFields 关键词搜索的字段
+func (SysUser) SearchFields() []string {
+	return []string{"account", "nick_name"}
+}
+
+// HistoryMaskFields 记录变更历史，密码只记录是否修改
+func (SysUser) HistoryMaskFields() []string {
+	return []string{"password"}
+}
+
+// SysUserUpdatePolicy 允许修改的字段，账号和角色(包括当前角色 default_role)不允许通过修改接口变更，角色通过 /sysRole/user-list 设置
+var SysUserUpdatePolicy = policy.NewUpdatePolicy("sysUser",
+	policy.Field("nick_name", policy.Validate("required,max=255")),
+	policy.Field("password", policy.Validate("required,min=6,max=64"), policy.Transform(policy.BcryptHash)),
+	policy.Field("phone", policy.Validate("omitempty,numeric,len=11")),
+	policy.Field("department", policy.Validate("max=64")),
+	policy.Field("expired_at", policy.Validate("omitempty,datetime=2006-01-02 15:04:05")),
+)
+
 type LoginReq struct {
-	Account  string `gorm:"size:255;unique;not null" json:"account" binding:"required"` // 账号
-	Password string `gorm:"size:255;not null" json:"password" binding:"required"`       // 密码
+	Account     string                 `gorm:"size:255;unique;not null" json:"account" binding:"required"` // 账号
+	Password    string                 `gorm:"size:255;not null" json:"password" binding:"required"`       // 密码
+	LoginClient `swaggerignore:"true"` // 客户端信息，由服务端填充
+}
+
+type ResetPasswordReq struct {
+	Ids      []uint `json:"ids" binding:"required,min=1"`              // 用户id
+	Password string `json:"password" binding:"omitempty,min=6,max=64"` // 新密码，为空则为每个用户随机生成
+}
+
+type ResetPasswordResp struct {
+	ID       uint   `json:"id"`
+	Account  string `json:"account"`
+	Password string `json:"password"` // 重置后的密码
 }
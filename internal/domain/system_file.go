@@ -6,11 +6,11 @@ import (
 
 type SystemFile struct {
 	ID         uint   `json:"id" gorm:"primaryKey"`
-	FileName   string `json:"file_name"`
-	Path       string `json:"path"`
-	IsDir      bool   `json:"is_dir"`
-	Size       int64  `json:"size"`       // 文件大小
-	CreateTime string `json:"createTime"` // 创建时间
+	FileName   string `json:"file_name" excel:"文件名"`
+	Path       string `json:"path" excel:"路径"`
+	IsDir      bool   `json:"is_dir" excel:"目录"`
+	Size       int64  `json:"size" excel:"文件大小"`       // 文件大小
+	CreateTime string `json:"createTime" excel:"创建时间"` // 创建时间
 }
 
 type PageSystemFileSearch struct {
@@ -21,3 +21,8 @@ type PageSystemFileSearch struct {
 func (SystemFile) TableName() string {
 	return "system_file"
 }
+
+// SortFields 允许排序的字段
+func (SystemFile) SortFields() []string {
+	return []string{"id", "file_name", "size"}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/scopes"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/cache"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/md5"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/casbin/casbin/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// aggregateCacheExpire 统计结果的缓存时间，数据变更后最多延迟该时间生效
+const aggregateCacheExpire = time.Minute
+
+var (
+	ErrorAggregateNotSupported = errors.New("该资源不支持统计")
+	ErrorAggregateDenied       = errors.New("没有该资源的统计权限")
+	ErrorAggregateField        = errors.New("不允许统计的字段")
+	ErrorAggregateMetric       = errors.New("不支持的统计指标")
+)
+
+// AggregateResource 允许通过 /aggregate/{resource} 统计的资源
+// 只能按声明的字段分组、统计，模型有 TenantID 时自动按租户隔离，已删除的数据不参与统计
+type AggregateResource struct {
+	Model      func() interface{}                                                         // 领域模型，需要实现 TableName()
+	Search     func() interface{}                                                         // 过滤条件，与列表接口的查询结构体一致，例如 &domain.PageDataImportSearch{}
+	GroupBy    []string                                                                   // 允许分组的字段(json名称)
+	Metrics    []string                                                                   // 允许 sum、avg、min、max 的字段(json名称)
+	TimeFields []string                                                                   // 允许按时间分组的字段(json名称)
+	Permission string                                                                     // 统计需要的接口权限，用户需要有该接口的 GET 权限，例如 /sysUser/list
+	Shared     bool                                                                       // 平台数据，只在共享库统计
+	Scope      func(ctx context.Context, roleID uint) (func(db *gorm.DB) *gorm.DB, error) // 数据权限，限制当前用户能统计的数据，可以为空，与 NoPageResource.Scope 一致
+}
+
+// aggregateResources 允许统计的资源，key为表名
+var aggregateResources = map[string]AggregateResource{}
+
+// RegisterAggregate 注册允许统计的资源
+func RegisterAggregate(resource AggregateResource) {
+	tabler, ok := resource.Model().(interface{ TableName() string })
+	if !ok {
+		panic("aggregate model must implement TableName()")
+	}
+	if resource.Permission == "" || resource.Search == nil {
+		panic("aggregate resource " + tabler.TableName() + " must declare Permission and Search")
+	}
+	aggregateResources[tabler.TableName()] = resource
+}
+
+// AggregateSearch 资源的过滤条件，用于绑定请求参数
+func AggregateSearch(resource string) (interface{}, error) {
+	res, ok := aggregateResources[resource]
+	if !ok {
+		return nil, ErrorAggregateNotSupported
+	}
+	return res.Search(), nil
+}
+
+func init() {
+	RegisterAggregate(AggregateResource{
+		Model:      func() interface{} { return &domain.DataImport{} },
+		Search:     func() interface{} { return &domain.PageDataImportSearch{} },
+		GroupBy:    []string{"status", "category"},
+		Metrics:    []string{"count", "success_count", "failure_count"},
+		TimeFields: []string{"createdAt"},
+		Permission: "/dataImport/list",
+	})
+	RegisterAggregate(AggregateResource{
+		Model:      func() interface{} { return &domain.SysUser{} },
+		Search:     func() interface{} { return &domain.PageSysUserSearch{} },
+		GroupBy:    []string{"status", "department", "default_role"},
+		TimeFields: []string{"createdAt", "expired_at"},
+		Permission: "/sysUser/list",
+		Shared:     true,
+		Scope:      sysUserScope,
+	})
+	RegisterAggregate(AggregateResource{
+		Model:      func() interface{} { return &domain.SysLoginLog{} },
+		Search:     func() interface{} { return &domain.PageSysLoginLogSearch{} },
+		GroupBy:    []string{"login_type", "result"},
+		TimeFields: []string{"createdAt"},
+		Permission: "/sysLoginLog/list",
+		Shared:     true,
+	})
+	RegisterAggregate(AggregateResource{
+		Model:      func() interface{} { return &domain.SysOperationLog{} },
+		Search:     func() interface{} { return &domain.PageSysOperationLogSearch{} },
+		GroupBy:    []string{"method", "path", "code", "user_id"},
+		Metrics:    []string{"latency"},
+		TimeFields: []string{"createdAt"},
+		Permission: "/sysOperationLog/list",
+		Shared:     true,
+	})
+}
+
+// 定义接口
+type AggregateRepo interface {
+	Aggregate(ctx context.Context, model interface{}, groupBy []string, metrics []domain.AggregateMetric, bucket, timeField string, fns ...func(db *gorm.DB) *gorm.DB) ([]map[string]interface{}, error)
+}
+
+type AggregateService struct {
+	repo  AggregateRepo
+	e     func() *casbin.Enforcer
+	cache func() cache.Cache
+}
+
+func NewAggregateService() *AggregateService {
+	return &AggregateService{repo: &data.AggregateRepo{}, e: Casbin, cache: aggregateCache}
+}
+
+// aggregateCache 没有配置 redis 时不缓存
+func aggregateCache() cache.Cache {
+	if global.Rdb == nil {
+		return nil
+	}
+	return cache.NewRdbCache(global.Rdb)
+}
+
+// Aggregate 统计注册的资源，search 为 AggregateSearch 返回的过滤条件，除了路由的权限外还需要有资源声明的接口权限
+func (s *AggregateService) Aggregate(ctx context.Context, roleID uint, req domain.AggregateReq, search interface{}) ([]map[string]interface{}, error) {
+	resource, ok := aggregateResources[req.Resource]
+	if !ok {
+		return nil, ErrorAggregateNotSupported
+	}
+	allowed, err := s.e().Enforce(constant.GetCasbinRoleKey(roleID), resource.Permission, http.MethodGet)
+	if err != nil {
+		logger.Error("s.e().Enforce", zap.Error(err), zap.Uint("roleID", roleID), zap.String("permission", resource.Permission))
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrorAggregateDenied
+	}
+
+	groupBy, err := aggregateFields(req.GroupBy, resource.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := aggregateMetrics(req.Metrics, resource.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	timeField := req.TimeField
+	if req.Bucket != "" {
+		if timeField == "" && len(resource.TimeFields) > 0 {
+			timeField = resource.TimeFields[0]
+		}
+		if !slices.Contains(resource.TimeFields, timeField) {
+			return nil, fmt.Errorf("%w: %s", ErrorAggregateField, timeField)
+		}
+	}
+
+	if resource.Shared {
+		ctx = global.WithShared(ctx)
+	}
+	ca := s.cache()
+	key := s.cacheKey(ctx, roleID, req, resource, search)
+	if ca != nil {
+		if res, err := ca.Get(ctx, key); err == nil {
+			if str, ok := res.(string); ok && str != "" {
+				var rows []map[string]interface{}
+				if err = json.Unmarshal([]byte(str), &rows); err == nil {
+					return rows, nil
+				}
+			}
+		}
+	}
+
+	fns := []func(db *gorm.DB) *gorm.DB{scopes.Filter(search)}
+	if resource.Scope != nil {
+		scope, err := resource.Scope(ctx, roleID)
+		if err != nil {
+			logger.Error("resource.Scope", zap.Error(err), zap.Uint("roleID", roleID), zap.String("resource", req.Resource))
+			return nil, err
+		}
+		if scope != nil {
+			fns = append(fns, scope)
+		}
+	}
+	rows, err := s.repo.Aggregate(ctx, resource.Model(), groupBy, metrics, req.Bucket, timeField, fns...)
+	if err != nil {
+		logger.Error("s.repo.Aggregate(req)", zap.Error(err), zap.Any("domain.AggregateReq", req))
+		return nil, err
+	}
+
+	if ca != nil {
+		if err = ca.Set(ctx, key, rows, aggregateCacheExpire); err != nil {
+			logger.Error("ca.Set", zap.Error(err), zap.String("key", key))
+		}
+	}
+	return rows, nil
+}
+
+// cacheKey 相同租户、相同查询条件的统计结果共用缓存，资源有数据权限时按用户和角色缓存
+func (s *AggregateService) cacheKey(ctx context.Context, roleID uint, req domain.AggregateReq, resource AggregateResource, search interface{}) string {
+	var actor uint
+	if resource.Scope != nil {
+		actor = gorm_plugin.ActorFromContext(ctx)
+	} else {
+		roleID = 0
+	}
+	filters, _ := json.Marshal(search)
+	digest := fmt.Sprintf("%d|%d|%s|%s|%s|%s|%s", actor, roleID, req.GroupBy, req.Metrics, req.Bucket, req.TimeField, filters)
+	return fmt.Sprintf(constants.AggregateCacheKey, gorm_plugin.TenantFromContext(ctx), req.Resource, md5.Md5To16(digest))
+}
+
+// aggregateFields 请求中逗号分隔的字段，必须是 allowed 中的字段
+func aggregateFields(fields string, allowed []string) ([]string, error) {
+	var res []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("%w: %s", ErrorAggregateField, field)
+		}
+		res = append(res, field)
+	}
+	return res, nil
+}
+
+// aggregateMetrics 解析 count,sum:字段 格式的统计指标，字段必须是 allowed 中的字段，为空时统计数量
+func aggregateMetrics(metrics string, allowed []string) ([]domain.AggregateMetric, error) {
+	var res []domain.AggregateMetric
+	for _, item := range strings.Split(metrics, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		fn, field, _ := strings.Cut(item, ":")
+		metric := domain.AggregateMetric{Func: strings.ToLower(strings.TrimSpace(fn)), Field: strings.TrimSpace(field)}
+		switch metric.Func {
+		case "count":
+		case "sum", "avg", "min", "max":
+			if metric.Field == "" {
+				return nil, fmt.Errorf("%w: %s", ErrorAggregateMetric, item)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrorAggregateMetric, item)
+		}
+		if metric.Field != "" && !slices.Contains(allowed, metric.Field) {
+			return nil, fmt.Errorf("%w: %s", ErrorAggregateField, metric.Field)
+		}
+		res = append(res, metric)
+	}
+	if len(res) == 0 {
+		res = append(res, domain.AggregateMetric{Func: "count"})
+	}
+	return res, nil
+}
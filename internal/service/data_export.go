@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/excel"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+const (
+	exportBatchSize      = 100  // 每批查询的数量，与分页的最大数量一致
+	exportAsyncThreshold = 5000 // 没有配置时超过该数量异步导出
+	exportDir            = "./uploads/exports"
+)
+
+var (
+	ErrorExportNotSupported = errors.New("该列表不支持导出")
+	ErrorExportNotFinished  = errors.New("导出未完成")
+	ErrorExportNoActor      = errors.New("登录后才能导出")
+)
+
+// ExportResource 允许通过列表接口的 export 参数导出的列表
+// 列为领域模型中有 excel 标签的字段，查询条件、排序与列表接口一致
+type ExportResource struct {
+	Name   string                                                                       // 文件名和工作表名，例如 用户
+	Model  func() interface{}                                                           // 领域模型
+	Search func() interface{}                                                           // 列表的查询结构体，需要嵌入 request.PageSearch
+	List   func(ctx context.Context, search interface{}) (response.PageResponse, error) // 列表查询，见 exportList
+	Shared bool                                                                         // 平台数据，异步导出时在共享库查询
+}
+
+// exportResources 允许导出的列表，key为表名
+var exportResources = map[string]ExportResource{}
+
+// RegisterExport 注册允许导出的列表
+func RegisterExport(resource string, r ExportResource) {
+	if r.Name == "" || r.Model == nil || r.Search == nil || r.List == nil {
+		panic("export resource " + resource + " must declare Name, Model, Search and List")
+	}
+	if _, ok := r.Search().(interface{ GetPageSearch() *request.PageSearch }); !ok {
+		panic("export resource " + resource + " Search must embed request.PageSearch")
+	}
+	exportResources[resource] = r
+}
+
+// exportList 列表服务的 List 方法转换为 ExportResource.List
+func exportList[S any](list func(ctx context.Context, search S) (response.PageResponse, error)) func(ctx context.Context, search interface{}) (response.PageResponse, error) {
+	return func(ctx context.Context, search interface{}) (response.PageResponse, error) {
+		return list(ctx, *search.(*S))
+	}
+}
+
+func init() {
+	RegisterExport("demo", ExportResource{
+		Name:   "demo",
+		Model:  func() interface{} { return &domain.Demo{} },
+		Search: func() interface{} { return &domain.PageDemoSearch{} },
+		List:   exportList(NewDemoService().List),
+	})
+	RegisterExport("data_import", ExportResource{
+		Name:   "导入记录",
+		Model:  func() interface{} { return &domain.DataImport{} },
+		Search: func() interface{} { return &domain.PageDataImportSearch{} },
+		List:   exportList(NewDataImportService().List),
+	})
+	RegisterExport("file", ExportResource{
+		Name:   "文件",
+		Model:  func() interface{} { return &domain.File{} },
+		Search: func() interface{} { return &domain.PageFileSearch{} },
+		List:   exportList(NewFileService().List),
+	})
+	RegisterExport("sys_user", ExportResource{
+		Name:   "用户",
+		Model:  func() interface{} { return &domain.SysUser{} },
+		Search: func() interface{} { return &domain.PageSysUserSearch{} },
+		List:   exportList(NewSysUserService().List),
+		Shared: true,
+	})
+	RegisterExport("sys_api", ExportResource{
+		Name:   "接口",
+		Model:  func() interface{} { return &domain.SysApi{} },
+		Search: func() interface{} { return &domain.PageSysApiSearch{} },
+		List:   exportList(NewSysApiService().List),
+		Shared: true,
+	})
+	RegisterExport("sys_login_log", ExportResource{
+		Name:   "登录日志",
+		Model:  func() interface{} { return &domain.SysLoginLog{} },
+		Search: func() interface{} { return &domain.PageSysLoginLogSearch{} },
+		List:   exportList(NewSysLoginLogService().List),
+		Shared: true,
+	})
+	RegisterExport("sys_operation_log", ExportResource{
+		Name:   "操作日志",
+		Model:  func() interface{} { return &domain.SysOperationLog{} },
+		Search: func() interface{} { return &domain.PageSysOperationLogSearch{} },
+		List:   exportList(NewSysOperationLogService().List),
+		Shared: true,
+	})
+	RegisterExport("sys_role", ExportResource{
+		Name:   "角色",
+		Model:  func() interface{} { return &domain.SysRole{} },
+		Search: func() interface{} { return &domain.PageSysRoleSearch{} },
+		List:   exportList(NewSysRoleService().List),
+	})
+	RegisterExport("sys_menu", ExportResource{
+		Name:   "菜单",
+		Model:  func() interface{} { return &domain.SysMenu{} },
+		Search: func() interface{} { return &domain.PageSysMenuSearch{} },
+		List:   exportList(NewSysMenuService().List),
+	})
+	RegisterExport("sys_tenant", ExportResource{
+		Name:   "租户",
+		Model:  func() interface{} { return &domain.SysTenant{} },
+		Search: func() interface{} { return &domain.PageSysTenantSearch{} },
+		List:   exportList(NewSysTenantService().List),
+		Shared: true,
+	})
+	RegisterExport("system_file", ExportResource{
+		Name:   "系统文件",
+		Model:  func() interface{} { return &domain.SystemFile{} },
+		Search: func() interface{} { return &SystemFilePathReq{} },
+		List:   exportList(NewSystemFileService().List),
+	})
+	RegisterExport("sys_change_history", ExportResource{
+		Name:   "变更历史",
+		Model:  func() interface{} { return &domain.SysChangeHistory{} },
+		Search: func() interface{} { return &domain.PageSysChangeHistorySearch{} },
+		List:   exportList(NewSysChangeHistoryService().List),
+	})
+}
+
+// 定义接口
+type DataExportRepo interface {
+	Create(ctx context.Context, dataExport *domain.DataExport) error
+	Update(ctx context.Context, dataExport domain.DataExport) error
+	Find(ctx context.Context, dataExport domain.DataExport) (domain.DataExport, error)
+	List(ctx context.Context, page domain.PageDataExportSearch) ([]domain.DataExport, response.PageInfo, error)
+}
+
+// DataExportService 列表导出，导出记录保存在共享库
+type DataExportService struct {
+	repo DataExportRepo
+}
+
+func NewDataExportService() *DataExportService {
+	return &DataExportService{repo: &data.DataExportRepo{}}
+}
+
+// Export 按列表的查询条件导出全部数据，search 为列表的查询结构体指针，其中的 export 为文件格式
+// 数量不超过 export.async-threshold 时通过 open 获取输出直接写入，返回 nil
+// 超过时创建导出记录异步导出，完成后通过短信通知，返回导出记录
+// 导出记录属于发起导出的用户，没有登录时返回 ErrorExportNoActor
+func (s *DataExportService) Export(ctx context.Context, resource string, search interface{}, open func(fileName string) io.Writer) (*domain.DataExport, error) {
+	res, ok := exportResources[resource]
+	if !ok {
+		return nil, ErrorExportNotSupported
+	}
+	if gorm_plugin.ActorFromContext(ctx) == 0 {
+		return nil, ErrorExportNoActor
+	}
+	page := search.(interface{ GetPageSearch() *request.PageSearch }).GetPageSearch()
+	format := page.Export
+	if format == "" {
+		format = constants.DataExportFormatXlsx
+	}
+
+	// 按列表的查询条件统计数量
+	query := *page
+	*page = request.PageSearch{Keyword: query.Keyword, PageNum: 1, PageSize: 1, Total: request.TotalExact}
+	list, err := res.List(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	*page = query
+
+	threshold := exportAsyncThreshold
+	if config := conf.Conf.ExportConfig; config != nil && config.AsyncThreshold > 0 {
+		threshold = int(config.AsyncThreshold)
+	}
+	if list.Total <= int64(threshold) {
+		_, err = exportRows(ctx, res, search, format, open(exportFileName(res, format)))
+		return nil, err
+	}
+
+	payload, err := json.Marshal(search)
+	if err != nil {
+		return nil, err
+	}
+	dataExport := domain.DataExport{
+		Resource: resource,
+		Format:   format,
+		FileName: exportFileName(res, format),
+		Status:   constants.DataExportStatusExporting,
+		Query:    payload,
+	}
+	ctx = global.WithShared(ctx)
+	if err = s.repo.Create(ctx, &dataExport); err != nil {
+		logger.Error("s.repo.Create(dataExport)", zap.Error(err), zap.Any("domain.DataExport", dataExport))
+		return nil, err
+	}
+	if err = global.Producer.NewTask(constants.QueueDataExport, dataExport); err != nil {
+		logger.Error("global.Producer.NewTask", zap.Error(err), zap.Uint("dataExport.ID", dataExport.ID))
+		dataExport.Status, dataExport.Reason = constants.DataExportStatusFailed, err.Error()
+		s.repo.Update(ctx, dataExport)
+		return nil, err
+	}
+	return &dataExport, nil
+}
+
+// Handle 异步导出，文件保存在 export.dir 中
+func (s *DataExportService) Handle(ctx context.Context, task *asynq.Task) error {
+	var payload domain.DataExport
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+
+	// 以发起导出的用户查询，属于发起导出的租户
+	ctx = gorm_plugin.WithTaskTenant(gorm_plugin.WithActor(ctx, payload.CreatedBy), payload.TenantID)
+
+	count, path, err := s.export(ctx, payload)
+	if err != nil {
+		logger.Error("s.export(payload)", zap.Error(err), zap.Uint("dataExport.ID", payload.ID))
+		payload.Status, payload.Reason = constants.DataExportStatusFailed, err.Error()
+		if len([]rune(payload.Reason)) > 255 {
+			payload.Reason = string([]rune(payload.Reason)[:255])
+		}
+	} else {
+		payload.Status, payload.Count, payload.FileUrl = constants.DataExportStatusSuccess, uint(count), path
+	}
+	if err = s.repo.Update(global.WithShared(ctx), payload); err != nil {
+		logger.Error("s.repo.Update(payload)", zap.Error(err), zap.Any("domain.DataExport", payload))
+		return err
+	}
+
+	s.notify(ctx, payload)
+	return nil
+}
+
+// export 导出到文件，返回数量和文件路径，失败时删除文件
+func (s *DataExportService) export(ctx context.Context, payload domain.DataExport) (int, string, error) {
+	res, ok := exportResources[payload.Resource]
+	if !ok {
+		return 0, "", ErrorExportNotSupported
+	}
+	search := res.Search()
+	if err := json.Unmarshal(payload.Query, search); err != nil {
+		return 0, "", err
+	}
+	if res.Shared {
+		ctx = global.WithShared(ctx)
+	}
+
+	dir := exportDir
+	if config := conf.Conf.ExportConfig; config != nil && config.Dir != "" {
+		dir = config.Dir
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return 0, "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d.%s", payload.ID, payload.Format))
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, "", err
+	}
+	count, err := exportRows(ctx, res, search, payload.Format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, "", err
+	}
+	return count, path, nil
+}
+
+// notify 通过短信通知发起导出的用户，没有配置模板或用户没有手机号时不通知
+func (s *DataExportService) notify(ctx context.Context, dataExport domain.DataExport) {
+	config := conf.Conf.SMSConfig
+	if config == nil || config.SmsExportTpl == "" || dataExport.CreatedBy == 0 {
+		return
+	}
+	var user domain.SysUser
+	err := global.DB.WithContext(global.WithShared(ctx)).Select("id", "phone").First(&user, dataExport.CreatedBy).Error
+	if err != nil || user.Phone == "" {
+		return
+	}
+
+	status := "成功"
+	if dataExport.Status != constants.DataExportStatusSuccess {
+		status = "失败"
+	}
+	sms := domain.Sms{
+		PhoneNumber:  user.Phone,
+		SignName:     config.SmsSignName,
+		TemplateCode: config.SmsExportTpl,
+		TemplateParams: map[string]string{
+			"name":   dataExport.FileName,
+			"status": status,
+			"count":  strconv.Itoa(int(dataExport.Count)),
+		},
+	}
+	if err = global.Producer.NewTask(constants.QueueSms, sms); err != nil {
+		logger.Error("发送导出通知失败", zap.Error(err), zap.Uint("dataExport.ID", dataExport.ID))
+	}
+}
+
+// List 当前用户的导出记录
+func (s *DataExportService) List(ctx context.Context, page domain.PageDataExportSearch) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	page.CreatedBy = gorm_plugin.ActorFromContext(ctx)
+	data, info, err := s.repo.List(global.WithShared(ctx), page)
+	if err != nil {
+		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageDataExportSearch", page))
+		return pageRes, err
+	}
+
+	pageRes.List = data
+	pageRes.PageInfo = info
+
+	return pageRes, nil
+}
+
+// Download 当前用户导出成功的文件
+func (s *DataExportService) Download(ctx context.Context, dataExport domain.DataExport) (domain.DataExport, error) {
+	res, err := s.repo.Find(global.WithShared(ctx), dataExport)
+	if err != nil {
+		logger.Error("s.repo.Find(dataExport)", zap.Error(err), zap.Any("domain.DataExport", dataExport))
+		return res, err
+	}
+	if res.CreatedBy != gorm_plugin.ActorFromContext(ctx) || res.Status != constants.DataExportStatusSuccess {
+		return res, ErrorExportNotFinished
+	}
+	return res, nil
+}
+
+// exportRows 使用游标分页分批查询，写入 w，返回导出的数量
+func exportRows(ctx context.Context, res ExportResource, search interface{}, format string, w io.Writer) (int, error) {
+	var (
+		count  int
+		tool   *excel.ExcelTool
+		writer interface {
+			WriteRows(list interface{}) error
+			Flush() error
+		}
+	)
+	switch format {
+	case constants.DataExportFormatCsv:
+		writer = excel.NewCSVWriter(w, res.Model())
+	default:
+		tool = excel.NewExcelTool(res.Name)
+		if tool == nil {
+			return 0, errors.New("create excel failed")
+		}
+		writer = tool.Model(res.Model())
+	}
+
+	page := search.(interface{ GetPageSearch() *request.PageSearch }).GetPageSearch()
+	*page = request.PageSearch{Keyword: page.Keyword, OrderBy: page.OrderBy, Keyset: true, PageSize: exportBatchSize, Total: request.TotalNone}
+	for {
+		list, err := res.List(ctx, search)
+		if err != nil {
+			return count, err
+		}
+		if list.List != nil {
+			if err = writer.WriteRows(list.List); err != nil {
+				return count, err
+			}
+			count += reflect.Indirect(reflect.ValueOf(list.List)).Len()
+		}
+		if !list.HasMore {
+			break
+		}
+		page.Cursor = list.NextCursor
+	}
+
+	if err := writer.Flush(); err != nil {
+		return count, err
+	}
+	if tool != nil {
+		return count, tool.Write(w)
+	}
+	return count, nil
+}
+
+// exportFileName 文件名，例如 用户_20060102150405.xlsx
+func exportFileName(res ExportResource, format string) string {
+	return fmt.Sprintf("%s_%s.%s", res.Name, time.Now().Format("20060102150405"), format)
+}
@@ -2,19 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
 	"github.com/Madou-Shinni/gin-quickstart/internal/data"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/excel"
 	"github.com/Madou-Shinni/go-logger"
+	"github.com/casbin/casbin/v2"
 	"go.uber.org/zap"
 )
 
+// importDir 没有配置上传目录时导入文件所在的目录
+const importDir = "./uploads"
+
+var (
+	ErrorImportFile   = errors.New("导入文件不存在，请先通过 /file/upload 上传 xlsx 文件")
+	ErrorImportDenied = errors.New("没有导入该数据的权限")
+)
+
+// importPermissions 导入需要的接口权限，与逐条创建数据的接口一致
+var importPermissions = map[string]string{
+	constants.DataImportCategorySysUser: "/sysUser",
+}
+
 type DataImportTemplateReq struct {
 	Category string `json:"category" form:"category"` // 类型
 }
@@ -22,25 +44,41 @@ type DataImportTemplateReq struct {
 type DemoExcelTpl struct {
 	Name     string           `excel:"姓名"`
 	Age      int              `excel:"年龄"`
-	BirthDay *model.LocalTime `excel:"生日"`
+	BirthDay *model.LocalTime `excel:"head:生日;type:date"`
 }
 
+// SysUserExcelTpl 用户导入模板
+type SysUserExcelTpl struct {
+	Account    string `excel:"head:账号;required;color:#FFC7CE" json:"account"`
+	NickName   string `excel:"head:昵称;required;color:#FFC7CE" json:"nick_name"`
+	Phone      string `excel:"head:手机号;type:string" json:"phone"`
+	Role       string `excel:"head:角色;required;color:#FFC7CE" json:"role"`
+	Department string `excel:"部门" json:"department"`
+	Password   string `excel:"初始密码" json:"password"` // 为空则随机生成
+}
+
+const sysUserExcelRemark = `填写说明:
+1.账号、昵称、角色为必填项，账号不能与已有账号重复
+2.手机号为11位数字，导入成功后初始密码将通过短信发送
+3.初始密码为空时将随机生成`
+
 // 定义接口
 type DataImportRepo interface {
 	Create(ctx context.Context, dataImport *domain.DataImport) error
 	Delete(ctx context.Context, dataImport domain.DataImport) error
 	Update(ctx context.Context, dataImport domain.DataImport) error
 	Find(ctx context.Context, dataImport domain.DataImport) (domain.DataImport, error)
-	List(ctx context.Context, page domain.PageDataImportSearch) ([]domain.DataImport, int64, error)
+	List(ctx context.Context, page domain.PageDataImportSearch) ([]domain.DataImport, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
 type DataImportService struct {
 	repo DataImportRepo
+	e    func() *casbin.Enforcer
 }
 
 func NewDataImportService() *DataImportService {
-	return &DataImportService{repo: &data.DataImportRepo{}}
+	return &DataImportService{repo: &data.DataImportRepo{}, e: Casbin}
 }
 
 func (s *DataImportService) Add(ctx context.Context, dataImport domain.DataImport) error {
@@ -88,14 +126,14 @@ func (s *DataImportService) List(ctx context.Context, page domain.PageDataImport
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageDataImportSearch", page))
 		return pageRes, err
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
@@ -115,6 +153,8 @@ func (s *DataImportService) Template(ctx context.Context, req DataImportTemplate
 	switch req.Category {
 	case constants.DataImportCategoryDemo:
 		err = demoExcelTpl(ctx, tool)
+	case constants.DataImportCategorySysUser:
+		err = sysUserExcelTpl(ctx, tool, sysUserDropList)
 	default:
 		return nil, fmt.Errorf("暂不支持该类型")
 	}
@@ -133,7 +173,22 @@ func (s *DataImportService) Template(ctx context.Context, req DataImportTemplate
 	return buffer.Bytes(), nil
 }
 
-func (s *DataImportService) Import(ctx context.Context, req domain.DataImport) (interface{}, error) {
+// Import 创建导入记录，由异步任务流式解析 FileUrl 上传的文件
+// 导入类型声明了接口权限时，当前角色需要有该接口的 POST 权限
+func (s *DataImportService) Import(ctx context.Context, roleID uint, req domain.DataImport) (interface{}, error) {
+	if permission, ok := importPermissions[req.Category]; ok {
+		allowed, err := s.e().Enforce(constant.GetCasbinRoleKey(roleID), permission, http.MethodPost)
+		if err != nil {
+			logger.Error("s.e().Enforce", zap.Error(err), zap.Uint("roleID", roleID), zap.String("permission", permission))
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrorImportDenied
+		}
+	}
+	if _, err := ImportFilePath(req.FileUrl); err != nil {
+		return nil, err
+	}
 	if err := global.DB.WithContext(ctx).Create(&req).Error; err != nil {
 		return nil, err
 	}
@@ -145,6 +200,33 @@ func (s *DataImportService) Import(ctx context.Context, req domain.DataImport) (
 	return nil, nil
 }
 
+// ImportFilePath 导入文件的路径，只能是上传目录中的 xlsx 文件，导出的文件不能导入
+func ImportFilePath(fileUrl string) (string, error) {
+	dir := importDir
+	if conf.Conf.UploadConfig != nil && conf.Conf.UploadConfig.Dir != "" {
+		dir = conf.Conf.UploadConfig.Dir
+	}
+	exports := exportDir
+	if conf.Conf.ExportConfig != nil && conf.Conf.ExportConfig.Dir != "" {
+		exports = conf.Conf.ExportConfig.Dir
+	}
+
+	path := filepath.Clean(fileUrl)
+	if fileUrl == "" || strings.ToLower(filepath.Ext(path)) != ".xlsx" || !inDir(dir, path) || inDir(exports, path) {
+		return "", ErrorImportFile
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", ErrorImportFile
+	}
+	return path, nil
+}
+
+// inDir path 在 dir 目录中
+func inDir(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func demoExcelTpl(ctx context.Context, tool *excel.ExcelTool, fns ...func(ctx context.Context, tool *excel.ExcelTool) error) error {
 	tool.Model(&DemoExcelTpl{})
 
@@ -156,3 +238,45 @@ func demoExcelTpl(ctx context.Context, tool *excel.ExcelTool, fns ...func(ctx co
 
 	return tool.Flush()
 }
+
+func sysUserExcelTpl(ctx context.Context, tool *excel.ExcelTool, fns ...func(ctx context.Context, tool *excel.ExcelTool) error) error {
+	tool.Model(&SysUserExcelTpl{}).Remark(sysUserExcelRemark)
+
+	for _, f := range fns {
+		if err := f(ctx, tool); err != nil {
+			return err
+		}
+	}
+
+	return tool.Flush()
+}
+
+// sysUserDropList 角色、部门下拉列表
+// 部门取已有用户的部门，允许填写新部门
+func sysUserDropList(ctx context.Context, tool *excel.ExcelTool) error {
+	var (
+		roles       []string
+		departments []string
+	)
+	err := global.DB.WithContext(ctx).Model(&domain.SysRole{}).Pluck("role_name", &roles).Error
+	if err != nil {
+		return err
+	}
+	err = global.DB.WithContext(ctx).Model(&domain.SysUser{}).
+		Where("department <> ?", "").
+		Distinct("department").
+		Pluck("department", &departments).Error
+	if err != nil {
+		return err
+	}
+
+	dropList := make(map[string][]string)
+	if len(roles) > 0 {
+		dropList["角色"] = roles
+	}
+	if len(departments) > 0 {
+		dropList["部门"] = departments
+	}
+
+	return tool.SetDropListPro(dropList)
+}
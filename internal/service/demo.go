@@ -12,11 +12,11 @@ import (
 
 // 定义接口
 type DemoRepo interface {
-	Create(ctx context.Context, demo domain.Demo) error
+	Create(ctx context.Context, demo *domain.Demo) error
 	Delete(ctx context.Context, demo domain.Demo) error
 	Update(ctx context.Context, demo domain.Demo) error
 	Find(ctx context.Context, demo domain.Demo) (domain.Demo, error)
-	List(ctx context.Context, page domain.PageDemoSearch) ([]domain.Demo, int64, error)
+	List(ctx context.Context, page domain.PageDemoSearch) ([]domain.Demo, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
@@ -30,7 +30,7 @@ func NewDemoService() *DemoService {
 
 func (s *DemoService) Add(ctx context.Context, demo domain.Demo) error {
 	// 3.持久化入库
-	if err := s.repo.Create(ctx, demo); err != nil {
+	if err := s.repo.Create(ctx, &demo); err != nil {
 		// 4.记录日志
 		logger.Error("s.repo.Create(demo)", zap.Error(err), zap.Any("domain.Demo", demo))
 		return err
@@ -73,14 +73,14 @@ func (s *DemoService) List(ctx context.Context, page domain.PageDemoSearch) (res
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageDemoSearch", page))
 		return pageRes, err
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
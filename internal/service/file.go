@@ -24,11 +24,11 @@ import (
 
 // 定义接口
 type FileRepo interface {
-	Create(ctx context.Context, file domain.File) error
+	Create(ctx context.Context, file *domain.File) error
 	Delete(ctx context.Context, file domain.File) error
 	Update(ctx context.Context, file map[string]interface{}) error
 	Find(ctx context.Context, file domain.File) (domain.File, error)
-	List(ctx context.Context, page domain.PageFileSearch) ([]domain.File, int64, error)
+	List(ctx context.Context, page domain.PageFileSearch) ([]domain.File, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
@@ -49,7 +49,7 @@ func (s *FileService) Add(ctx context.Context, file domain.File) error {
 	file.ID = id
 
 	// 3.持久化入库
-	if err := s.repo.Create(ctx, file); err != nil {
+	if err := s.repo.Create(ctx, &file); err != nil {
 		// 4.记录日志
 		logger.Error("s.repo.Create(file)", zap.Error(err), zap.Any("domain.File", file))
 		return err
@@ -92,14 +92,14 @@ func (s *FileService) List(ctx context.Context, page domain.PageFileSearch) (res
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageFileSearch", page))
 		return pageRes, err
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
@@ -208,7 +208,7 @@ func (s *FileService) MergeChunk(ctx context.Context, file domain.File) (domain.
 	file.FilePath = dst
 
 	// 入库
-	err = s.repo.Create(ctx, file)
+	err = s.repo.Create(ctx, &file)
 	if err != nil {
 		logger.Error("s.repo.Create(file)", zap.Error(err))
 		return domain.File{}, err
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/casbin/casbin/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrorNoPageNotSupported = errors.New("该资源不支持查询")
+	ErrorNoPageDenied       = errors.New("没有该资源的查询权限")
+	ErrorNoPageField        = errors.New("不允许查询的字段")
+)
+
+// NoPageResource 允许通过 /noPage/{table} 查询的资源
+// 只能查询、搜索声明的字段，模型有 TenantID 时自动按租户隔离，已删除的数据不会返回
+type NoPageResource struct {
+	Model      func() interface{}                                                         // 领域模型，需要实现 TableName()
+	Selects    []string                                                                   // 允许查询的字段(json名称)，请求中没有指定时返回全部
+	Searches   []string                                                                   // 允许按关键词搜索的字段(json名称)，请求中没有指定时搜索全部
+	Permission string                                                                     // 查询需要的接口权限，用户需要有该接口的 GET 权限，例如 /sysUser/list
+	Shared     bool                                                                       // 平台数据，只在共享库查询
+	Scope      func(ctx context.Context, roleID uint) (func(db *gorm.DB) *gorm.DB, error) // 数据权限，限制当前用户能查询的数据，可以为空
+}
+
+// noPageResources 允许不分页查询的资源，key为表名
+var noPageResources = map[string]NoPageResource{}
+
+// RegisterNoPage 注册允许不分页查询的资源
+func RegisterNoPage(resource NoPageResource) {
+	tabler, ok := resource.Model().(interface{ TableName() string })
+	if !ok {
+		panic("no page model must implement TableName()")
+	}
+	if resource.Permission == "" || len(resource.Selects) == 0 {
+		panic("no page resource " + tabler.TableName() + " must declare Permission and Selects")
+	}
+	noPageResources[tabler.TableName()] = resource
+}
+
+func init() {
+	RegisterNoPage(NoPageResource{
+		Model:      func() interface{} { return &domain.Demo{} },
+		Selects:    []string{"id", "name"},
+		Searches:   []string{"name"},
+		Permission: "/demo/list",
+	})
+	RegisterNoPage(NoPageResource{
+		Model:      func() interface{} { return &domain.SysUser{} },
+		Selects:    []string{"id", "account", "nick_name", "department"},
+		Searches:   []string{"account", "nick_name"},
+		Permission: "/sysUser/list",
+		Shared:     true,
+		Scope:      sysUserScope,
+	})
+	RegisterNoPage(NoPageResource{
+		Model:      func() interface{} { return &domain.SysRole{} },
+		Selects:    []string{"id", "parent_id", "role_name"},
+		Searches:   []string{"role_name"},
+		Permission: "/sysRole/list",
+	})
+	RegisterNoPage(NoPageResource{
+		Model:      func() interface{} { return &domain.SysMenu{} },
+		Selects:    []string{"id", "parent_id", "name"},
+		Searches:   []string{"name"},
+		Permission: "/sysMenu/list",
+		Shared:     true,
+	})
+}
+
+// sysUserScope 超级管理员可以查询全部用户，其他用户只能查询同部门的用户和拥有当前角色及其子角色的用户
+func sysUserScope(ctx context.Context, roleID uint) (func(db *gorm.DB) *gorm.DB, error) {
+	if gorm_plugin.TenantBypassed(ctx) {
+		return nil, nil
+	}
+	return (&data.SysUserRepo{}).DataScope(ctx, gorm_plugin.ActorFromContext(ctx), roleID)
+}
+
+// 定义接口
+type NoPageRepo interface {
+	List(ctx context.Context, model interface{}, selects, searches []string, keyword string, fns ...func(db *gorm.DB) *gorm.DB) ([]map[string]interface{}, int64, error)
+}
+
+type NoPageService struct {
+	repo NoPageRepo
+	e    func() *casbin.Enforcer
+}
+
+func NewNoPageService() *NoPageService {
+	return &NoPageService{repo: &data.NoPageRepo{}, e: Casbin}
+}
+
+// List 不分页查询注册的资源，除了路由的权限外还需要有资源声明的接口权限
+func (s *NoPageService) List(ctx context.Context, roleID uint, req domain.NoPageReq) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	resource, ok := noPageResources[req.Table]
+	if !ok {
+		return pageRes, ErrorNoPageNotSupported
+	}
+	allowed, err := s.e().Enforce(constant.GetCasbinRoleKey(roleID), resource.Permission, http.MethodGet)
+	if err != nil {
+		logger.Error("s.e().Enforce", zap.Error(err), zap.Uint("roleID", roleID), zap.String("permission", resource.Permission))
+		return pageRes, err
+	}
+	if !allowed {
+		return pageRes, ErrorNoPageDenied
+	}
+
+	selects, err := noPageFields(req.Select, resource.Selects)
+	if err != nil {
+		return pageRes, err
+	}
+	searches, err := noPageFields(req.FindConditionsCols, resource.Searches)
+	if err != nil {
+		return pageRes, err
+	}
+
+	if resource.Shared {
+		ctx = global.WithShared(ctx)
+	}
+	var fns []func(db *gorm.DB) *gorm.DB
+	if resource.Scope != nil {
+		scope, err := resource.Scope(ctx, roleID)
+		if err != nil {
+			logger.Error("resource.Scope", zap.Error(err), zap.Uint("roleID", roleID), zap.String("table", req.Table))
+			return pageRes, err
+		}
+		if scope != nil {
+			fns = append(fns, scope)
+		}
+	}
+
+	list, count, err := s.repo.List(ctx, resource.Model(), selects, searches, req.Keyword, fns...)
+	if err != nil {
+		logger.Error("s.repo.List(req)", zap.Error(err), zap.Any("domain.NoPageReq", req))
+		return pageRes, err
+	}
+
+	pageRes.List = list
+	pageRes.Total = count
+
+	return pageRes, nil
+}
+
+// noPageFields 请求中逗号分隔的字段，必须是 allowed 中的字段，为空时返回 allowed
+func noPageFields(fields string, allowed []string) ([]string, error) {
+	if strings.TrimSpace(fields) == "" {
+		return allowed, nil
+	}
+	var res []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("%w: %s", ErrorNoPageField, field)
+		}
+		res = append(res, field)
+	}
+	return res, nil
+}
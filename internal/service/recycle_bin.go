@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrorRecycleBinNotSupported = errors.New("该数据不支持回收站")
+
+// recycleBinDomains 支持回收站的领域，key为表名
+// 模型需要嵌入 model.AuditModel 以记录删除人
+var recycleBinDomains = map[string]func() interface{}{}
+
+// RegisterRecycleBin 注册支持回收站的领域
+func RegisterRecycleBin(newModel func() interface{}) {
+	m := newModel()
+	tabler, ok := m.(interface{ TableName() string })
+	if !ok {
+		panic("recycle bin model must implement TableName()")
+	}
+	recycleBinDomains[tabler.TableName()] = newModel
+}
+
+func init() {
+	RegisterRecycleBin(func() interface{} { return &domain.Demo{} })
+	RegisterRecycleBin(func() interface{} { return &domain.SysUser{} })
+	RegisterRecycleBin(func() interface{} { return &domain.SysRole{} })
+	RegisterRecycleBin(func() interface{} { return &domain.SysMenu{} })
+	RegisterRecycleBin(func() interface{} { return &domain.DataImport{} })
+}
+
+// 定义接口
+type RecycleBinRepo interface {
+	List(ctx context.Context, model interface{}, page request.PageSearch) (interface{}, int64, error)
+	Restore(ctx context.Context, model interface{}, ids []uint) (int64, error)
+	Purge(ctx context.Context, model interface{}, ids []uint) (int64, error)
+	PurgeBefore(ctx context.Context, model interface{}, before time.Time) (int64, error)
+}
+
+type RecycleBinService struct {
+	repo RecycleBinRepo
+}
+
+func NewRecycleBinService() *RecycleBinService {
+	return &RecycleBinService{repo: &data.RecycleBinRepo{}}
+}
+
+func (s *RecycleBinService) model(name string) (interface{}, error) {
+	newModel, ok := recycleBinDomains[name]
+	if !ok {
+		return nil, ErrorRecycleBinNotSupported
+	}
+	return newModel(), nil
+}
+
+// List 分页查询回收站中的数据
+func (s *RecycleBinService) List(ctx context.Context, page domain.PageRecycleBinSearch) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	m, err := s.model(page.Domain)
+	if err != nil {
+		return pageRes, err
+	}
+
+	list, count, err := s.repo.List(ctx, m, page.PageSearch)
+	if err != nil {
+		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageRecycleBinSearch", page))
+		return pageRes, err
+	}
+
+	// 密码等不返回具体值的列
+	masks, err := recycleBinMaskFields(ctx, m)
+	if err != nil {
+		return pageRes, err
+	}
+
+	rv := reflect.ValueOf(list)
+	items := make([]domain.RecycleBinItem, 0, rv.Len())
+	deleters := make([]uint, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		row := rv.Index(i)
+		for _, field := range masks {
+			if err = field.Set(ctx, row, reflect.Zero(field.FieldType).Interface()); err != nil {
+				return pageRes, err
+			}
+		}
+		item := domain.RecycleBinItem{
+			ID:        uint(row.FieldByName("ID").Uint()),
+			DeletedBy: uint(row.FieldByName("DeletedBy").Uint()),
+			Data:      row.Addr().Interface(),
+		}
+		if deletedAt := row.FieldByName("DeletedAt").Interface().(gorm.DeletedAt); deletedAt.Valid {
+			item.DeletedAt = &model.LocalTime{Time: deletedAt.Time}
+		}
+		if item.DeletedBy != 0 {
+			deleters = append(deleters, item.DeletedBy)
+		}
+		items = append(items, item)
+	}
+
+	// 删除人昵称
+	if len(deleters) > 0 {
+		var users []domain.SysUser
+		err = global.DB.WithContext(ctx).Unscoped().Model(&domain.SysUser{}).
+			Select("id", "nick_name").Where("id IN ?", deleters).Find(&users).Error
+		if err != nil {
+			logger.Error("查询删除人失败", zap.Error(err), zap.Uints("ids", deleters))
+			return pageRes, err
+		}
+		names := make(map[uint]string, len(users))
+		for _, u := range users {
+			names[u.ID] = u.NickName
+		}
+		for i := range items {
+			items[i].Deleter = names[items[i].DeletedBy]
+		}
+	}
+
+	pageRes.List = items
+	pageRes.Total = count
+
+	return pageRes, nil
+}
+
+// recycleBinMaskFields 模型 HistoryMaskFields 声明的列，回收站中同样不返回具体值
+func recycleBinMaskFields(ctx context.Context, m interface{}) ([]*schema.Field, error) {
+	h, ok := m.(gorm_plugin.Historical)
+	if !ok {
+		return nil, nil
+	}
+	stmt := &gorm.Statement{DB: global.DB.WithContext(ctx)}
+	if err := stmt.Parse(m); err != nil {
+		return nil, err
+	}
+	var fields []*schema.Field
+	for _, name := range h.HistoryMaskFields() {
+		if field := stmt.Schema.LookUpField(name); field != nil {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// Restore 恢复数据
+func (s *RecycleBinService) Restore(ctx context.Context, req domain.RecycleBinReq) (int64, error) {
+	m, err := s.model(req.Domain)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.repo.Restore(ctx, m, req.Ids)
+	if err != nil {
+		logger.Error("s.repo.Restore(req)", zap.Error(err), zap.Any("domain.RecycleBinReq", req))
+		return 0, err
+	}
+
+	// 恢复的用户需要重新加载状态
+	if req.Domain == (domain.SysUser{}).TableName() {
+		NewSysUserService().clearStatusCache(ctx, req.Ids...)
+	}
+
+	return count, nil
+}
+
+// Purge 彻底删除数据
+func (s *RecycleBinService) Purge(ctx context.Context, req domain.RecycleBinReq) (int64, error) {
+	m, err := s.model(req.Domain)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.repo.Purge(ctx, m, req.Ids)
+	if err != nil {
+		logger.Error("s.repo.Purge(req)", zap.Error(err), zap.Any("domain.RecycleBinReq", req))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Cleanup 定时彻底删除超过保留天数的数据
+func (s *RecycleBinService) Cleanup(ctx context.Context, task *asynq.Task) error {
+	config := conf.Conf.RecycleBinConfig
+	if config == nil || config.RetentionDays <= 0 {
+		return nil
+	}
+
+	// 清理所有租户的数据
+	ctx = gorm_plugin.WithTenantBypass(ctx)
+	before := time.Now().AddDate(0, 0, -config.RetentionDays)
+	for name, newModel := range recycleBinDomains {
+		count, err := s.repo.PurgeBefore(ctx, newModel(), before)
+		if err != nil {
+			logger.Error("s.repo.PurgeBefore(before)", zap.Error(err), zap.String("domain", name), zap.Time("before", before))
+			return err
+		}
+		if count > 0 {
+			logger.Info("清理回收站", zap.String("domain", name), zap.Int64("count", count), zap.Time("before", before))
+		}
+	}
+
+	return nil
+}
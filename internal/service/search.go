@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/search"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// 支持关键词搜索并且需要同步到外部搜索引擎的领域
+func init() {
+	search.Register(&domain.Demo{})
+	search.Register(&domain.SysUser{})
+}
+
+type SearchService struct {
+}
+
+func NewSearchService() *SearchService {
+	return &SearchService{}
+}
+
+// Handle 同步变更的数据到搜索引擎
+func (s *SearchService) Handle(ctx context.Context, task *asynq.Task) error {
+	var payload search.Task
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+
+	ctx = gorm_plugin.WithTaskTenant(ctx, payload.TenantID)
+	if payload.Shared {
+		ctx = global.WithShared(ctx)
+	}
+	if err := search.Sync(ctx, global.DB.WithContext(ctx), payload.Table, payload.IDs); err != nil {
+		logger.Error("search.Sync", zap.Error(err), zap.Any("search.Task", payload))
+		return err
+	}
+	return nil
+}
+
+// Reindex 全量同步，tables 为空时同步全部注册的表
+func (s *SearchService) Reindex(ctx context.Context, tables []string, batchSize int) (map[string]int, error) {
+	if len(tables) == 0 {
+		tables = search.Tables()
+	}
+	res := make(map[string]int, len(tables))
+	for _, table := range tables {
+		count, err := search.Reindex(ctx, global.DB.WithContext(ctx), table, batchSize)
+		if err != nil {
+			logger.Error("search.Reindex", zap.Error(err), zap.String("table", table))
+			return res, err
+		}
+		res[table] = count
+	}
+	return res, nil
+}
@@ -12,11 +12,11 @@ import (
 
 // 定义接口
 type SysApiRepo interface {
-	Create(ctx context.Context, sysApi domain.SysApi) error
+	Create(ctx context.Context, sysApi *domain.SysApi) error
 	Delete(ctx context.Context, sysApi domain.SysApi) error
 	Update(ctx context.Context, sysApi map[string]interface{}) error
 	Find(ctx context.Context, sysApi domain.SysApi) (domain.SysApi, error)
-	List(ctx context.Context, page domain.PageSysApiSearch) ([]domain.SysApi, int64, error)
+	List(ctx context.Context, page domain.PageSysApiSearch) ([]domain.SysApi, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
@@ -30,7 +30,7 @@ func NewSysApiService() *SysApiService {
 
 func (s *SysApiService) Add(ctx context.Context, sysApi domain.SysApi) error {
 	// 3.持久化入库
-	if err := s.repo.Create(ctx, sysApi); err != nil {
+	if err := s.repo.Create(ctx, &sysApi); err != nil {
 		// 4.记录日志
 		logger.Error("s.repo.Create(sysApi)", zap.Error(err), zap.Any("domain.SysApi", sysApi))
 		return err
@@ -73,14 +73,14 @@ func (s *SysApiService) List(ctx context.Context, page domain.PageSysApiSearch)
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysApiSearch", page))
 		return pageRes, err
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
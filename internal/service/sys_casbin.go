@@ -45,7 +45,6 @@ var (
 type SysCasbinRepo interface {
 	Create(ctx context.Context, sysCasbin domain.SysCasbin) error
 	Delete(ctx context.Context, sysCasbin domain.SysCasbin) error
-	Update(ctx context.Context, sysCasbin map[string]interface{}) error
 	Find(ctx context.Context, sysCasbin domain.SysCasbin) (domain.SysCasbin, error)
 	List(ctx context.Context, page domain.PageSysCasbinSearch) ([]domain.SysCasbin, int64, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
@@ -133,15 +132,6 @@ func (s *SysCasbinService) Delete(ctx context.Context, sysCasbin domain.SysCasbi
 	return nil
 }
 
-func (s *SysCasbinService) Update(ctx context.Context, sysCasbin map[string]interface{}) error {
-	if err := s.repo.Update(ctx, sysCasbin); err != nil {
-		logger.Error("s.repo.Update(sysCasbin)", zap.Error(err), zap.Any("domain.SysCasbin", sysCasbin))
-		return err
-	}
-
-	return nil
-}
-
 func (s *SysCasbinService) Find(ctx context.Context, sysCasbin domain.SysCasbin) (domain.SysCasbin, error) {
 	res, err := s.repo.Find(ctx, sysCasbin)
 
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/go-logger"
+	"go.uber.org/zap"
+)
+
+var ErrorHistoryNotSupported = errors.New("该表未记录变更历史")
+
+// 允许查询变更历史的表，值为是否为平台数据(变更历史保存在共享库)
+var historyTables = map[string]bool{
+	domain.Demo{}.TableName():    false,
+	domain.SysUser{}.TableName(): true,
+}
+
+// 定义接口
+type SysChangeHistoryRepo interface {
+	List(ctx context.Context, page domain.PageSysChangeHistorySearch) ([]domain.SysChangeHistory, response.PageInfo, error)
+}
+
+type SysChangeHistoryService struct {
+	repo SysChangeHistoryRepo
+}
+
+func NewSysChangeHistoryService() *SysChangeHistoryService {
+	return &SysChangeHistoryService{repo: &data.SysChangeHistoryRepo{}}
+}
+
+// List 查询一条记录的变更历史，按时间倒序
+func (s *SysChangeHistoryService) List(ctx context.Context, page domain.PageSysChangeHistorySearch) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	shared, ok := historyTables[page.Table]
+	if !ok {
+		return pageRes, ErrorHistoryNotSupported
+	}
+	if shared {
+		ctx = global.WithShared(ctx)
+	}
+
+	data, info, err := s.repo.List(ctx, page)
+	if err != nil {
+		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysChangeHistorySearch", page))
+		return pageRes, err
+	}
+
+	pageRes.List = data
+	pageRes.PageInfo = info
+
+	return pageRes, nil
+}
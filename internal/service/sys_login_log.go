@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/ipdb"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/useragent"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// 本地IP库，首次使用时加载
+var (
+	ipDB     *ipdb.DB
+	ipDBOnce sync.Once
+)
+
+func searchLocation(ip string) string {
+	ipDBOnce.Do(func() {
+		if conf.Conf.LoginLogConfig == nil || conf.Conf.LoginLogConfig.IPDBPath == "" {
+			return
+		}
+		db, err := ipdb.Open(conf.Conf.LoginLogConfig.IPDBPath)
+		if err != nil {
+			logger.Error("加载IP库失败", zap.Error(err), zap.String("path", conf.Conf.LoginLogConfig.IPDBPath))
+			return
+		}
+		ipDB = db
+	})
+	return ipDB.Search(ip)
+}
+
+// 定义接口
+type SysLoginLogRepo interface {
+	Create(ctx context.Context, sysLoginLog *domain.SysLoginLog) error
+	List(ctx context.Context, page domain.PageSysLoginLogSearch) ([]domain.SysLoginLog, response.PageInfo, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type SysLoginLogService struct {
+	repo SysLoginLogRepo
+}
+
+func NewSysLoginLogService() *SysLoginLogService {
+	return &SysLoginLogService{repo: &data.SysLoginLogRepo{}}
+}
+
+// Record 记录登录日志
+// 记录失败不影响登录流程，只记录错误日志
+func (s *SysLoginLogService) Record(ctx context.Context, event domain.LoginEvent) {
+	ua := useragent.Parse(event.UserAgent)
+	sysLoginLog := domain.SysLoginLog{
+		UserID:    event.UserID,
+		Account:   event.Account,
+		LoginType: event.LoginType,
+		Result:    constants.LoginResultSuccess,
+		IP:        event.IP,
+		Location:  searchLocation(event.IP),
+		UserAgent: truncate(event.UserAgent, 512),
+		Browser:   ua.Browser,
+		OS:        ua.OS,
+	}
+	if event.Err != nil {
+		sysLoginLog.Result = constants.LoginResultFailed
+		sysLoginLog.Reason = truncate(event.Err.Error(), 255)
+	}
+
+	if err := s.repo.Create(ctx, &sysLoginLog); err != nil {
+		logger.Error("s.repo.Create(sysLoginLog)", zap.Error(err), zap.Any("domain.SysLoginLog", sysLoginLog))
+	}
+}
+
+func (s *SysLoginLogService) List(ctx context.Context, page domain.PageSysLoginLogSearch) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	data, info, err := s.repo.List(ctx, page)
+	if err != nil {
+		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysLoginLogSearch", page))
+		return pageRes, err
+	}
+
+	pageRes.List = data
+	pageRes.PageInfo = info
+
+	return pageRes, nil
+}
+
+// Cleanup 定时清理超过保留天数的登录日志
+func (s *SysLoginLogService) Cleanup(ctx context.Context, task *asynq.Task) error {
+	config := conf.Conf.LoginLogConfig
+	if config == nil || config.RetentionDays <= 0 {
+		return nil
+	}
+
+	before := time.Now().AddDate(0, 0, -config.RetentionDays)
+	count, err := s.repo.DeleteBefore(ctx, before)
+	if err != nil {
+		logger.Error("s.repo.DeleteBefore(before)", zap.Error(err), zap.Time("before", before))
+		return err
+	}
+	logger.Info("清理登录日志", zap.Int64("count", count), zap.Time("before", before))
+
+	return nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
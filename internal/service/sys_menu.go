@@ -15,11 +15,11 @@ import (
 
 // 定义接口
 type SysMenuRepo interface {
-	Create(ctx context.Context, sysMenu domain.SysMenu) error
+	Create(ctx context.Context, sysMenu *domain.SysMenu) error
 	Delete(ctx context.Context, sysMenu domain.SysMenu) error
 	Update(ctx context.Context, sysMenu map[string]interface{}) error
 	Find(ctx context.Context, sysMenu domain.SysMenu) (domain.SysMenu, error)
-	List(ctx context.Context, page domain.PageSysMenuSearch) ([]domain.SysMenu, int64, error)
+	List(ctx context.Context, page domain.PageSysMenuSearch) ([]domain.SysMenu, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
@@ -33,7 +33,7 @@ func NewSysMenuService() *SysMenuService {
 
 func (s *SysMenuService) Add(ctx context.Context, sysMenu domain.SysMenu) error {
 	// 3.持久化入库
-	if err := s.repo.Create(ctx, sysMenu); err != nil {
+	if err := s.repo.Create(ctx, &sysMenu); err != nil {
 		// 4.记录日志
 		logger.Error("s.repo.Create(sysMenu)", zap.Error(err), zap.Any("domain.SysMenu", sysMenu))
 		return err
@@ -76,7 +76,7 @@ func (s *SysMenuService) List(ctx context.Context, page domain.PageSysMenuSearch
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysMenuSearch", page))
 		return pageRes, err
@@ -91,7 +91,7 @@ func (s *SysMenuService) List(ctx context.Context, page domain.PageSysMenuSearch
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
@@ -108,7 +108,7 @@ func (s *SysMenuService) DeleteByIds(ctx context.Context, ids request.Ids) error
 func (s *SysMenuService) RoleList(ctx context.Context, rid uint) ([]domain.SysMenu, error) {
 	var list []domain.SysMenu
 
-	err := global.DB.WithContext(ctx).Model(&domain.SysRole{Model: model.Model{ID: rid}}).
+	err := global.DB.WithContext(ctx).Model(&domain.SysRole{AuditModel: model.AuditModel{Model: model.Model{ID: rid}}}).
 		Where("parent_id = ?", 0).
 		Association("Menus").
 		Find(&list)
@@ -149,7 +149,7 @@ func buildTreeMenu(menus []domain.SysMenu, parentID uint) []domain.SysMenu {
 func (s *SysMenuService) SetRoleList(ctx context.Context, sysRole domain.SysRole) error {
 	var list = sysRole.Menus
 
-	err := global.DB.WithContext(ctx).Model(&domain.SysRole{Model: model.Model{ID: sysRole.ID}}).
+	err := global.DB.WithContext(ctx).Model(&domain.SysRole{AuditModel: model.AuditModel{Model: model.Model{ID: sysRole.ID}}}).
 		Association("Menus").
 		Replace(list)
 	if err != nil {
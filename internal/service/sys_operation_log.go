@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/go-logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// gin路由参数 :id 与 swagger同步到sys_api的路径 {id} 互相转换
+var routeParamRegexp = regexp.MustCompile(`:(\w+)`)
+
+// 定义接口
+type SysOperationLogRepo interface {
+	Create(ctx context.Context, sysOperationLog *domain.SysOperationLog) error
+	Find(ctx context.Context, sysOperationLog domain.SysOperationLog) (domain.SysOperationLog, error)
+	List(ctx context.Context, page domain.PageSysOperationLogSearch) ([]domain.SysOperationLog, response.PageInfo, error)
+}
+
+type SysOperationLogService struct {
+	repo SysOperationLogRepo
+}
+
+func NewSysOperationLogService() *SysOperationLogService {
+	return &SysOperationLogService{repo: &data.SysOperationLogRepo{}}
+}
+
+// Add 保存操作日志，由异步任务调用
+// 接口名称根据 method + 路由模板 从sys_api中解析
+func (s *SysOperationLogService) Add(ctx context.Context, sysOperationLog domain.SysOperationLog) error {
+	if sysOperationLog.ApiName == "" && sysOperationLog.Route != "" {
+		sysOperationLog.ApiName = s.apiName(ctx, sysOperationLog.Method, sysOperationLog.Route)
+	}
+
+	if err := s.repo.Create(ctx, &sysOperationLog); err != nil {
+		logger.Error("s.repo.Create(sysOperationLog)", zap.Error(err), zap.Any("domain.SysOperationLog", sysOperationLog))
+		return err
+	}
+
+	return nil
+}
+
+func (s *SysOperationLogService) apiName(ctx context.Context, method, route string) string {
+	var sysApi domain.SysApi
+	path := routeParamRegexp.ReplaceAllString(route, "{$1}")
+	err := global.DB.WithContext(ctx).Model(&domain.SysApi{}).
+		Select("name").
+		Where("method = ? AND path IN ?", method, []string{path, route}).
+		First(&sysApi).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("查询接口名称失败", zap.Error(err), zap.String("method", method), zap.String("route", route))
+	}
+	return sysApi.Name
+}
+
+func (s *SysOperationLogService) Find(ctx context.Context, sysOperationLog domain.SysOperationLog) (domain.SysOperationLog, error) {
+	res, err := s.repo.Find(ctx, sysOperationLog)
+
+	if err != nil {
+		logger.Error("s.repo.Find(sysOperationLog)", zap.Error(err), zap.Any("domain.SysOperationLog", sysOperationLog))
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (s *SysOperationLogService) List(ctx context.Context, page domain.PageSysOperationLogSearch) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	data, info, err := s.repo.List(ctx, page)
+	if err != nil {
+		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysOperationLogSearch", page))
+		return pageRes, err
+	}
+
+	pageRes.List = data
+	pageRes.PageInfo = info
+
+	return pageRes, nil
+}
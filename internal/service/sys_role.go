@@ -15,11 +15,11 @@ import (
 
 // 定义接口
 type SysRoleRepo interface {
-	Create(ctx context.Context, sysRole domain.SysRole) error
+	Create(ctx context.Context, sysRole *domain.SysRole) error
 	Delete(ctx context.Context, sysRole domain.SysRole) error
 	Update(ctx context.Context, sysRole map[string]interface{}) error
 	Find(ctx context.Context, sysRole domain.SysRole) (domain.SysRole, error)
-	List(ctx context.Context, page domain.PageSysRoleSearch) ([]domain.SysRole, int64, error)
+	List(ctx context.Context, page domain.PageSysRoleSearch) ([]domain.SysRole, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
@@ -93,7 +93,7 @@ func (s *SysRoleService) List(ctx context.Context, page domain.PageSysRoleSearch
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysRoleSearch", page))
 		return pageRes, err
@@ -108,7 +108,7 @@ func (s *SysRoleService) List(ctx context.Context, page domain.PageSysRoleSearch
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
@@ -151,7 +151,7 @@ func (s *SysRoleService) SetUserRoleList(ctx context.Context, sysUser domain.Sys
 			}
 		}
 
-		err = tx.Model(&domain.SysUser{Model: model.Model{ID: sysUser.ID}}).
+		err = tx.Model(&domain.SysUser{AuditModel: model.AuditModel{Model: model.Model{ID: sysUser.ID}}}).
 			Association("Roles").
 			Replace(sysUser.Roles)
 		if err != nil {
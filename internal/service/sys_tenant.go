@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/data"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/go-logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrorTenantExist       = errors.New("租户编码已存在")
+	ErrorTenantNotExist    = errors.New("租户不存在")
+	ErrorTenantDisabled    = errors.New("租户已被禁用")
+	ErrorTenantExpired     = errors.New("租户已过期")
+	ErrorTenantRequired    = errors.New("请指定租户")
+	ErrorTenantDenied      = errors.New("无权访问该租户")
+	ErrorTenantProvision   = errors.New("租户数据库连接或迁移失败")
+	ErrorTenantProvisioned = errors.New("租户已分配独立数据库")
+)
+
+const sysTenantCacheExpire = time.Minute * 5 // 租户状态缓存时间
+
+// sysTenantState 租户状态缓存
+type sysTenantState struct {
+	Status    string     `json:"status"`
+	ExpiredAt *time.Time `json:"expired_at"`
+}
+
+func (t sysTenantState) check() error {
+	if t.Status == constants.SysTenantStatusDisabled {
+		return ErrorTenantDisabled
+	}
+	if t.ExpiredAt != nil && !t.ExpiredAt.IsZero() && time.Now().After(*t.ExpiredAt) {
+		return ErrorTenantExpired
+	}
+	return nil
+}
+
+func newSysTenantState(sysTenant domain.SysTenant) sysTenantState {
+	state := sysTenantState{Status: sysTenant.Status}
+	if sysTenant.ExpiredAt != nil {
+		state.ExpiredAt = &sysTenant.ExpiredAt.Time
+	}
+	return state
+}
+
+// 定义接口
+type SysTenantRepo interface {
+	Create(ctx context.Context, sysTenant *domain.SysTenant) error
+	Delete(ctx context.Context, sysTenant domain.SysTenant) error
+	Update(ctx context.Context, sysTenant map[string]interface{}) error
+	Find(ctx context.Context, sysTenant domain.SysTenant) (domain.SysTenant, error)
+	FindByCode(ctx context.Context, code string) (domain.SysTenant, error)
+	SetDSN(ctx context.Context, id uint, dsn string) error
+	List(ctx context.Context, page domain.PageSysTenantSearch) ([]domain.SysTenant, response.PageInfo, error)
+	DeleteByIds(ctx context.Context, ids request.Ids) error
+}
+
+type SysTenantService struct {
+	repo SysTenantRepo
+}
+
+func NewSysTenantService() *SysTenantService {
+	return &SysTenantService{repo: &data.SysTenantRepo{}}
+}
+
+func (s *SysTenantService) Add(ctx context.Context, req domain.AddSysTenantReq) error {
+	sysTenant := domain.SysTenant{
+		Name:      req.Name,
+		Code:      strings.ToLower(req.Code),
+		Plan:      req.Plan,
+		Status:    constants.SysTenantStatusActive,
+		ExpiredAt: req.ExpiredAt,
+		Remark:    req.Remark,
+	}
+	if sysTenant.Plan == "" {
+		sysTenant.Plan = constants.SysTenantPlanFree
+	}
+
+	_, err := s.repo.FindByCode(ctx, sysTenant.Code)
+	if err == nil {
+		return ErrorTenantExist
+	}
+
+	if err = s.repo.Create(ctx, &sysTenant); err != nil {
+		logger.Error("s.repo.Create(sysTenant)", zap.Error(err), zap.Any("domain.SysTenant", sysTenant))
+		return err
+	}
+
+	return nil
+}
+
+func (s *SysTenantService) Delete(ctx context.Context, sysTenant domain.SysTenant) error {
+	if err := s.repo.Delete(ctx, sysTenant); err != nil {
+		logger.Error("s.repo.Delete(sysTenant)", zap.Error(err), zap.Any("domain.SysTenant", sysTenant))
+		return err
+	}
+
+	s.clearCache(ctx, sysTenant.ID)
+	return nil
+}
+
+func (s *SysTenantService) DeleteByIds(ctx context.Context, ids request.Ids) error {
+	if err := s.repo.DeleteByIds(ctx, ids); err != nil {
+		logger.Error("s.DeleteByIds(ids)", zap.Error(err), zap.Any("ids request.Ids", ids))
+		return err
+	}
+
+	s.clearCache(ctx, idsToUint(ids)...)
+	return nil
+}
+
+// Update 修改租户，禁用、过期的租户已签发的token立即失效
+func (s *SysTenantService) Update(ctx context.Context, sysTenant map[string]interface{}) error {
+	if err := s.repo.Update(ctx, sysTenant); err != nil {
+		logger.Error("s.repo.Update(sysTenant)", zap.Error(err), zap.Any("domain.SysTenant", sysTenant))
+		return err
+	}
+
+	id, _ := policy.ParseID(sysTenant["id"])
+	s.clearCache(ctx, id)
+	return nil
+}
+
+func (s *SysTenantService) Find(ctx context.Context, sysTenant domain.SysTenant) (domain.SysTenant, error) {
+	res, err := s.repo.Find(ctx, sysTenant)
+
+	if err != nil {
+		logger.Error("s.repo.Find(sysTenant)", zap.Error(err), zap.Any("domain.SysTenant", sysTenant))
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (s *SysTenantService) List(ctx context.Context, page domain.PageSysTenantSearch) (response.PageResponse, error) {
+	var (
+		pageRes response.PageResponse
+	)
+
+	data, info, err := s.repo.List(ctx, page)
+	if err != nil {
+		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysTenantSearch", page))
+		return pageRes, err
+	}
+
+	pageRes.List = data
+	pageRes.PageInfo = info
+
+	return pageRes, nil
+}
+
+// Provision 为租户分配独立数据库：检查连接并执行迁移后保存连接字符串
+// 共享库中已有的租户数据不会迁移到独立数据库；其他实例缓存了连接，所以分配后不允许修改
+func (s *SysTenantService) Provision(ctx context.Context, req domain.ProvisionSysTenantReq) error {
+	tenants, err := global.DB.Tenants()
+	if err != nil {
+		return err
+	}
+
+	sysTenant := domain.SysTenant{}
+	sysTenant.ID = req.ID
+	sysTenant, err = s.repo.Find(ctx, sysTenant)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrorTenantNotExist
+		}
+		return err
+	}
+	if sysTenant.DSN != "" {
+		return ErrorTenantProvisioned
+	}
+
+	if err = tenants.Provision(ctx, req.DSN); err != nil {
+		logger.Error("tenants.Provision(dsn)", zap.Error(err), zap.Uint("id", req.ID))
+		return ErrorTenantProvision
+	}
+	if err = s.repo.SetDSN(ctx, req.ID, req.DSN); err != nil {
+		logger.Error("s.repo.SetDSN(id, dsn)", zap.Error(err), zap.Uint("id", req.ID))
+		return err
+	}
+
+	tenants.Evict(req.ID)
+	return nil
+}
+
+// Check 校验租户是否可用(禁用、过期、已删除)
+// 状态缓存在redis中，修改、删除租户时删除缓存
+func (s *SysTenantService) Check(ctx context.Context, id uint) error {
+	key := fmt.Sprintf(constants.SysTenantCacheKey, id)
+	var state sysTenantState
+
+	if global.Rdb != nil {
+		res, err := global.Rdb.Get(ctx, key).Result()
+		if err == nil && json.Unmarshal([]byte(res), &state) == nil {
+			return state.check()
+		}
+	}
+
+	sysTenant := domain.SysTenant{}
+	sysTenant.ID = id
+	sysTenant, err := s.repo.Find(ctx, sysTenant)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrorTenantNotExist
+		}
+		logger.Error("s.Check(id)", zap.Error(err), zap.Uint("id", id))
+		return err
+	}
+
+	state = newSysTenantState(sysTenant)
+	if global.Rdb != nil {
+		if marshal, err := json.Marshal(state); err == nil {
+			global.Rdb.Set(ctx, key, marshal, sysTenantCacheExpire)
+		}
+	}
+
+	return state.check()
+}
+
+// Resolve 根据编码获取租户id
+// 编码唯一(包括已删除的租户)且不能修改，所以对应关系缓存后不需要删除
+func (s *SysTenantService) Resolve(ctx context.Context, code string) (uint, error) {
+	code = strings.ToLower(code)
+	key := fmt.Sprintf(constants.SysTenantCodeCacheKey, code)
+
+	if global.Rdb != nil {
+		if id, err := global.Rdb.Get(ctx, key).Uint64(); err == nil {
+			return uint(id), nil
+		}
+	}
+
+	sysTenant, err := s.repo.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrorTenantNotExist
+		}
+		logger.Error("s.Resolve(code)", zap.Error(err), zap.String("code", code))
+		return 0, err
+	}
+
+	if global.Rdb != nil {
+		global.Rdb.Set(ctx, key, sysTenant.ID, sysTenantCacheExpire)
+	}
+
+	return sysTenant.ID, nil
+}
+
+func (s *SysTenantService) clearCache(ctx context.Context, ids ...uint) {
+	if global.Rdb == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf(constants.SysTenantCacheKey, id))
+	}
+	if err := global.Rdb.Del(ctx, keys...).Err(); err != nil {
+		logger.Error("删除租户缓存失败", zap.Error(err), zap.Uints("ids", ids))
+	}
+}
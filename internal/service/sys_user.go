@@ -2,38 +2,90 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+
 	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
 	"github.com/Madou-Shinni/gin-quickstart/internal/data"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/policy"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/str"
 	"github.com/Madou-Shinni/go-logger"
 	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 var (
-	ErrorUserExist = errors.New("account already exist")
-	ErrorAccount   = errors.New("账号或密码错误")
+	ErrorUserExist    = errors.New("account already exist")
+	ErrorAccount      = errors.New("账号或密码错误")
+	ErrorUserNotExist = errors.New("用户不存在")
+	ErrorUserDisabled = errors.New("账号已被禁用")
+	ErrorUserLocked   = errors.New("账号已被锁定，请联系管理员")
+	ErrorUserExpired  = errors.New("账号已过期")
+)
+
+const (
+	maxLoginFailures    = 5               // 连续登录失败次数达到后锁定账号
+	loginFailuresExpire = time.Hour * 24  // 登录失败次数的统计周期
+	sysUserStatusExpire = time.Minute * 5 // 用户状态缓存时间
+	resetPasswordLength = 12              // 随机重置密码长度
 )
 
-var sysRoleService = NewSysRoleService()
+// dummyPasswordHash 账号不存在时用于比较的密码哈希，使响应时间与密码错误时一致
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// sysUserState 用户状态缓存
+type sysUserState struct {
+	Status    string     `json:"status"`
+	ExpiredAt *time.Time `json:"expired_at"`
+}
+
+func (u sysUserState) check() error {
+	switch u.Status {
+	case constants.SysUserStatusDisabled:
+		return ErrorUserDisabled
+	case constants.SysUserStatusLocked:
+		return ErrorUserLocked
+	}
+	if u.ExpiredAt != nil && !u.ExpiredAt.IsZero() && time.Now().After(*u.ExpiredAt) {
+		return ErrorUserExpired
+	}
+	return nil
+}
+
+func newSysUserState(sysUser domain.SysUser) sysUserState {
+	state := sysUserState{Status: sysUser.Status}
+	if sysUser.ExpiredAt != nil {
+		state.ExpiredAt = &sysUser.ExpiredAt.Time
+	}
+	return state
+}
+
+var (
+	sysRoleService     = NewSysRoleService()
+	sysLoginLogService = NewSysLoginLogService()
+	sysTenantService   = NewSysTenantService()
+)
 
 // 定义接口
 type SysUserRepo interface {
-	Create(ctx context.Context, sysUser domain.SysUser) error
+	Create(ctx context.Context, sysUser *domain.SysUser) error
 	Delete(ctx context.Context, sysUser domain.SysUser) error
 	Update(ctx context.Context, sysUser map[string]interface{}) error
 	Find(ctx context.Context, sysUser domain.SysUser) (domain.SysUser, error)
-	List(ctx context.Context, page domain.PageSysUserSearch) ([]domain.SysUser, int64, error)
+	List(ctx context.Context, page domain.PageSysUserSearch) ([]domain.SysUser, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
@@ -46,14 +98,18 @@ func NewSysUserService() *SysUserService {
 }
 
 func (s *SysUserService) Add(ctx context.Context, sysUser domain.SysUser) error {
+	// 超级管理员只能在数据库中设置
+	sysUser.SuperAdmin = false
+
 	// 3.持久化入库
-	db := global.DB.WithContext(ctx).Model(&domain.SysUser{})
+	// 账号全局唯一，需要跨租户校验
+	db := global.DB.WithContext(gorm_plugin.WithTenantBypass(ctx)).Model(&domain.SysUser{})
 	err := db.Where("account = ?", sysUser.Account).First(&domain.SysUser{}).Error
 	if err == nil {
 		return ErrorUserExist
 	}
 
-	if err := s.repo.Create(ctx, sysUser); err != nil {
+	if err := s.repo.Create(ctx, &sysUser); err != nil {
 		// 4.记录日志
 		logger.Error("s.repo.Create(sysUser)", zap.Error(err), zap.Any("domain.SysUser", sysUser))
 		return err
@@ -77,6 +133,11 @@ func (s *SysUserService) Update(ctx context.Context, sysUser map[string]interfac
 		return err
 	}
 
+	if _, ok := sysUser["expired_at"]; ok {
+		id, _ := policy.ParseID(sysUser["id"])
+		s.clearStatusCache(ctx, id)
+	}
+
 	return nil
 }
 
@@ -104,14 +165,14 @@ func (s *SysUserService) List(ctx context.Context, page domain.PageSysUserSearch
 		pageRes response.PageResponse
 	)
 
-	data, count, err := s.repo.List(ctx, page)
+	data, info, err := s.repo.List(ctx, page)
 	if err != nil {
 		logger.Error("s.repo.List(page)", zap.Error(err), zap.Any("domain.PageSysUserSearch", page))
 		return pageRes, err
 	}
 
 	pageRes.List = data
-	pageRes.Total = count
+	pageRes.PageInfo = info
 
 	return pageRes, nil
 }
@@ -126,25 +187,65 @@ func (s *SysUserService) DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
 func (s *SysUserService) Login(ctx context.Context, user domain.LoginReq) (interface{}, error) {
-	// 查询用户
 	var sysUser domain.SysUser
-	err := global.DB.WithContext(ctx).Model(&domain.SysUser{}).First(&sysUser, "account = ?", user.Account).Error
+	token, err := s.login(ctx, user, &sysUser)
+
+	// 记录登录日志
+	sysLoginLogService.Record(ctx, domain.LoginEvent{
+		LoginClient: user.LoginClient,
+		UserID:      sysUser.ID,
+		Account:     user.Account,
+		LoginType:   constants.LoginTypePassword,
+		Err:         err,
+	})
+
+	return token, err
+}
+
+func (s *SysUserService) login(ctx context.Context, user domain.LoginReq, sysUser *domain.SysUser) (interface{}, error) {
+	// 登录时还没有租户，账号在所有租户中唯一
+	ctx = gorm_plugin.WithTenantBypass(ctx)
+	// 查询用户
+	err := global.DB.WithContext(ctx).Model(&domain.SysUser{}).First(sysUser, "account = ?", user.Account).Error
 	if err != nil {
-		logger.Error("s.Login()", zap.Error(err), zap.Any("domain.SysUser", user))
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error("s.Login()", zap.Error(err), zap.Any("account", user.Account))
+		}
+		// 账号不存在时同样比较一次密码，避免通过响应时间判断账号是否存在
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(user.Password))
 		return nil, ErrorAccount
 	}
 
-	// 验证密码
+	// 先验证密码，账号不存在、密码错误返回相同的错误，验证通过后才返回账号、租户的状态
 	err = bcrypt.CompareHashAndPassword([]byte(sysUser.Password), []byte(user.Password))
 	if err != nil {
+		// 不可用的账号不累计失败次数，避免禁用的账号被改为锁定
+		if newSysUserState(*sysUser).check() == nil {
+			s.loginFailed(ctx, *sysUser)
+		}
 		return nil, ErrorAccount
 	}
 
+	// 验证账号状态
+	if err = newSysUserState(*sysUser).check(); err != nil {
+		return nil, err
+	}
+	// 验证租户状态
+	if sysUser.TenantID != 0 {
+		if err = sysTenantService.Check(ctx, sysUser.TenantID); err != nil {
+			return nil, err
+		}
+	}
+	s.loginSucceeded(ctx, sysUser.ID)
+
 	// 生成token
 	mp := jwt.MapClaims{
 		tools.UserIdKey: sysUser.ID,
 		tools.RoleIdKey: sysUser.DefaultRole,
-		tools.ExpKey:    time.Duration(conf.Conf.JwtConfig.AccessExpire) * time.Second,
+		tools.TenantKey: sysUser.TenantID,
+		// 只有平台用户可以是超级管理员
+		tools.SuperAdminKey: sysUser.TenantID == 0 && sysUser.SuperAdmin,
+		tools.ExpKey:        time.Duration(conf.Conf.JwtConfig.AccessExpire) * time.Second,
 	}
 	token, err := tools.GenToken(mp, conf.Conf.JwtConfig.Secret)
 	if err != nil {
@@ -154,3 +255,161 @@ func (s *SysUserService) Login(ctx context.Context, user domain.LoginReq) (inter
 
 	return token, nil
 }
+
+// loginFailed 记录连续登录失败次数，达到 maxLoginFailures 后锁定账号
+// 锁定后仍返回账号或密码错误，密码正确时才提示账号已锁定
+func (s *SysUserService) loginFailed(ctx context.Context, sysUser domain.SysUser) {
+	if global.Rdb == nil {
+		return
+	}
+
+	key := fmt.Sprintf(constants.SysUserLoginFailedKey, sysUser.ID)
+	count, err := global.Rdb.Incr(ctx, key).Result()
+	if err != nil {
+		logger.Error("记录登录失败次数失败", zap.Error(err), zap.Uint("id", sysUser.ID))
+		return
+	}
+	global.Rdb.Expire(ctx, key, loginFailuresExpire)
+
+	if count < maxLoginFailures {
+		return
+	}
+
+	if err = s.SetStatus(ctx, []uint{sysUser.ID}, constants.SysUserStatusLocked); err != nil {
+		logger.Error("锁定账号失败", zap.Error(err), zap.Uint("id", sysUser.ID))
+	}
+}
+
+func (s *SysUserService) loginSucceeded(ctx context.Context, id uint) {
+	if global.Rdb == nil {
+		return
+	}
+	global.Rdb.Del(ctx, fmt.Sprintf(constants.SysUserLoginFailedKey, id))
+}
+
+// CheckStatus 校验用户是否可用(禁用、锁定、过期、已删除)
+// 状态缓存在redis中，状态变更时删除缓存，使已签发的token立即失效
+func (s *SysUserService) CheckStatus(ctx context.Context, id uint) error {
+	key := fmt.Sprintf(constants.SysUserStatusCacheKey, id)
+	var state sysUserState
+
+	if global.Rdb != nil {
+		res, err := global.Rdb.Get(ctx, key).Result()
+		if err == nil && json.Unmarshal([]byte(res), &state) == nil {
+			return state.check()
+		}
+	}
+
+	var sysUser domain.SysUser
+	// 在解析租户之前校验，按用户id查询
+	err := global.DB.WithContext(gorm_plugin.WithTenantBypass(ctx)).Model(&domain.SysUser{}).
+		Select("id", "status", "expired_at").
+		First(&sysUser, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrorUserNotExist
+		}
+		logger.Error("s.CheckStatus(id)", zap.Error(err), zap.Uint("id", id))
+		return err
+	}
+
+	state = newSysUserState(sysUser)
+	if global.Rdb != nil {
+		if marshal, err := json.Marshal(state); err == nil {
+			global.Rdb.Set(ctx, key, marshal, sysUserStatusExpire)
+		}
+	}
+
+	return state.check()
+}
+
+func (s *SysUserService) clearStatusCache(ctx context.Context, ids ...uint) {
+	if global.Rdb == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf(constants.SysUserStatusCacheKey, id))
+	}
+	if err := global.Rdb.Del(ctx, keys...).Err(); err != nil {
+		logger.Error("删除用户状态缓存失败", zap.Error(err), zap.Uints("ids", ids))
+	}
+}
+
+// SetStatus 批量修改用户状态
+// 启用账号时同时清空连续登录失败次数
+func (s *SysUserService) SetStatus(ctx context.Context, ids []uint, status string) error {
+	err := global.DB.WithContext(ctx).Model(&domain.SysUser{}).Where("id IN ?", ids).Update("status", status).Error
+	if err != nil {
+		logger.Error("s.SetStatus(ids, status)", zap.Error(err), zap.Uints("ids", ids), zap.String("status", status))
+		return err
+	}
+
+	s.clearStatusCache(ctx, ids...)
+	if status == constants.SysUserStatusActive {
+		for _, id := range ids {
+			s.loginSucceeded(ctx, id)
+		}
+	}
+
+	return nil
+}
+
+// Enable 批量启用(解锁)用户
+func (s *SysUserService) Enable(ctx context.Context, ids request.Ids) error {
+	return s.SetStatus(ctx, idsToUint(ids), constants.SysUserStatusActive)
+}
+
+// Disable 批量禁用用户，已签发的token立即失效
+func (s *SysUserService) Disable(ctx context.Context, ids request.Ids) error {
+	return s.SetStatus(ctx, idsToUint(ids), constants.SysUserStatusDisabled)
+}
+
+// ResetPassword 批量重置密码
+// 未指定密码时为每个用户生成随机密码，返回重置后的密码
+func (s *SysUserService) ResetPassword(ctx context.Context, req domain.ResetPasswordReq) ([]domain.ResetPasswordResp, error) {
+	var users []domain.SysUser
+	err := global.DB.WithContext(ctx).Model(&domain.SysUser{}).Select("id", "account").Find(&users, "id IN ?", req.Ids).Error
+	if err != nil {
+		logger.Error("s.ResetPassword(req)", zap.Error(err), zap.Uints("ids", req.Ids))
+		return nil, err
+	}
+
+	res := make([]domain.ResetPasswordResp, 0, len(users))
+	err = global.DB.Tx(ctx, func(ctx context.Context) error {
+		for _, u := range users {
+			var err error
+			pwd := req.Password
+			if pwd == "" {
+				pwd, err = str.GeneratePassword(resetPasswordLength)
+				if err != nil {
+					return err
+				}
+			}
+			hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			err = global.DB.WithContext(ctx).Model(&domain.SysUser{}).Where("id = ?", u.ID).Update("password", string(hash)).Error
+			if err != nil {
+				return err
+			}
+			res = append(res, domain.ResetPasswordResp{ID: u.ID, Account: u.Account, Password: pwd})
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("s.ResetPassword(req)", zap.Error(err), zap.Uints("ids", req.Ids))
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func idsToUint(ids request.Ids) []uint {
+	res := make([]uint, 0, len(ids.Ids))
+	for _, id := range ids.Ids {
+		res = append(res, uint(id))
+	}
+	return res
+}
@@ -21,7 +21,8 @@ import (
 )
 
 type SystemFilePathReq struct {
-	FilePath string `json:"filePath" form:"filePath"`
+	FilePath           string `json:"filePath" form:"filePath"`
+	request.PageSearch        // 目录不分页，只使用其中的 export 参数
 }
 
 type AddSystemFileReq struct {
@@ -49,7 +50,7 @@ type SystemFileRepo interface {
 	Delete(ctx context.Context, systemFile domain.SystemFile) error
 	Update(ctx context.Context, systemFile domain.SystemFile) error
 	Find(ctx context.Context, systemFile domain.SystemFile) (domain.SystemFile, error)
-	List(ctx context.Context, page domain.PageSystemFileSearch) ([]domain.SystemFile, int64, error)
+	List(ctx context.Context, page domain.PageSystemFileSearch) ([]domain.SystemFile, response.PageInfo, error)
 	DeleteByIds(ctx context.Context, ids request.Ids) error
 }
 
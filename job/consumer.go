@@ -32,13 +32,22 @@ func RunConsumer() {
 
 	mux := asynq.NewServeMux()
 	monitorService := service.MonitorServiceEx
+	sysLoginLogService := service.NewSysLoginLogService()
+	recycleBinService := service.NewRecycleBinService()
+	searchService := service.NewSearchService()
+	dataExportService := service.NewDataExportService()
 
 	// 异步任务
 	mux.HandleFunc(constants.QueueSms, handleSmsSend)
 	mux.HandleFunc(constants.QueueDataImport, handleImportData)
+	mux.HandleFunc(constants.QueueAuditLog, handleAuditLog)
+	mux.HandleFunc(constants.QueueSearchIndex, searchService.Handle)
+	mux.HandleFunc(constants.QueueDataExport, dataExportService.Handle)
 
 	// 定时任务
 	mux.HandleFunc(constants.TaskTest, handleTaskTest)
+	mux.HandleFunc(constants.TaskLoginLogCleanup, sysLoginLogService.Cleanup)
+	mux.HandleFunc(constants.TaskRecycleBinPurge, recycleBinService.Cleanup)
 	// 监控任务
 	mux.HandleFunc(constants.TaskMonitor, monitorService.Handle)
 
@@ -6,6 +6,8 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/constants"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
 	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
 	"github.com/Madou-Shinni/go-logger"
 	"github.com/hibiken/asynq"
 	"go.uber.org/zap"
@@ -21,6 +23,16 @@ func handleSmsSend(ctx context.Context, task *asynq.Task) error {
 	return service.SmsSend(payload.PhoneNumber, payload.SignName, payload.TemplateCode, payload.TemplateParams)
 }
 
+func handleAuditLog(ctx context.Context, task *asynq.Task) error {
+	var payload domain.SysOperationLog
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return err
+	}
+
+	return service.NewSysOperationLogService().Add(ctx, payload)
+}
+
 func handleTaskTest(ctx context.Context, task *asynq.Task) error {
 	// 这里可以添加任务测试的逻辑
 	logger.Info("handleTaskTest")
@@ -41,12 +53,22 @@ func handleImportData(ctx context.Context, task *asynq.Task) error {
 		return err
 	}
 
+	// 导入的数据以发起导入的用户作为创建人，属于发起导入的租户
+	ctx = gorm_plugin.WithTaskTenant(gorm_plugin.WithActor(ctx, payload.CreatedBy), payload.TenantID)
+
 	switch payload.Category {
 	case constants.DataImportCategoryDemo:
 		err = importDemo(ctx, payload)
+	case constants.DataImportCategorySysUser:
+		err = importSysUser(global.WithShared(ctx), payload)
 	}
 
 	if err != nil {
+		// 文件无法读取、没有标题行等整个文件的错误，导入失败
+		global.DB.WithContext(ctx).Model(&payload).Updates(domain.DataImport{
+			Status:        constants.DataImportStatusFailed,
+			FailedReasons: []domain.FailedReason{{Reason: err.Error()}},
+		})
 		panic(err)
 	}
 
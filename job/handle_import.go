@@ -2,48 +2,242 @@ package job
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
 	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/excel"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/str"
+	"github.com/Madou-Shinni/go-logger"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"regexp"
 	"strings"
 )
 
+var phoneRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// importBatchSize 每批处理的行数
+const importBatchSize = 100
+
+// eachImportBatch 流式读取上传的导入文件，每 importBatchSize 行调用一次 fn
+func eachImportBatch[T any](payload domain.DataImport, fn func(rows []excel.Row[T]) error) error {
+	path, err := service.ImportFilePath(payload.FileUrl)
+	if err != nil {
+		return err
+	}
+	r, err := excel.OpenRowReader[T](path, "")
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	batch := make([]excel.Row[T], 0, importBatchSize)
+	for r.Next() {
+		batch = append(batch, r.Row())
+		if len(batch) < importBatchSize {
+			continue
+		}
+		if err = fn(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+	}
+	if err = r.Error(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
 func importDemo(ctx context.Context, payload domain.DataImport) error {
 	var (
-		list          []domain.Demo
 		failedReasons []domain.FailedReason
-		err           error
 		successCount  uint
 		failureCount  uint
 	)
-	err = json.Unmarshal(payload.Data, &list)
-	if err != nil {
-		return err
-	}
 
 	return global.DB.Tx(ctx, func(ctx context.Context) error {
-		for i, v := range list {
-			err = func() error {
-				if strings.Contains(v.Name, "error") {
-					// 模拟错误
-					return errors.New("名称异常")
+		err := eachImportBatch(payload, func(rows []excel.Row[service.DemoExcelTpl]) error {
+			for _, row := range rows {
+				err := func() error {
+					if row.Err != nil {
+						return row.Err
+					}
+					if strings.Contains(row.Data.Name, "error") {
+						// 模拟错误
+						return errors.New("名称异常")
+					}
+					demo := domain.Demo{Name: row.Data.Name, Age: row.Data.Age, BirthDay: row.Data.BirthDay}
+					return global.DB.WithContext(ctx).Create(&demo).Error
+				}()
+
+				// 统一错误处理
+				if err != nil {
+					failureCount++
+					failedReasons = append(failedReasons, domain.FailedReason{
+						Row:    fmt.Sprintf("第 %d 行", row.Num),
+						Reason: err.Error(),
+					})
+				} else {
+					successCount++
 				}
-				return global.DB.WithContext(ctx).Create(&list[i]).Error
-			}()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// 修改导入信息
+		payload.SuccessCount = successCount
+		payload.FailureCount = failureCount
+		payload.Count = successCount + failureCount
+		payload.FailedReasons = failedReasons
+		if failedReasons != nil {
+			payload.Status = constants.DataImportStatusFailed
+		} else {
+			payload.Status = constants.DataImportStatusSuccess
+		}
+		return global.DB.WithContext(ctx).Model(&payload).Updates(&payload).Error
+	})
+}
+
+// importSysUser 批量导入用户
+// 导入成功后通过短信将账号和初始密码发送给用户
+func importSysUser(ctx context.Context, payload domain.DataImport) error {
+	var (
+		roles         []domain.SysRole
+		failedReasons []domain.FailedReason
+		credentials   []domain.Sms
+		successCount  uint
+		failureCount  uint
+	)
+
+	// 预加载角色
+	err := global.DB.WithContext(ctx).Find(&roles).Error
+	if err != nil {
+		return err
+	}
+	roleMap := make(map[string]domain.SysRole, len(roles))
+	for _, role := range roles {
+		roleMap[role.RoleName] = role
+	}
+	accountSet := make(map[string]struct{})
 
-			// 统一错误处理
+	err = global.DB.Tx(ctx, func(ctx context.Context) error {
+		err := eachImportBatch(payload, func(rows []excel.Row[service.SysUserExcelTpl]) error {
+			// 按批加载已存在的账号，账号全局唯一，需要跨租户校验，包括已删除的账号
+			var existAccounts []string
+			accounts := make([]string, 0, len(rows))
+			for _, row := range rows {
+				accounts = append(accounts, strings.TrimSpace(row.Data.Account))
+			}
+			err := global.DB.WithContext(gorm_plugin.WithTenantBypass(ctx)).Unscoped().Model(&domain.SysUser{}).
+				Where("account IN ?", accounts).
+				Pluck("account", &existAccounts).Error
 			if err != nil {
-				failureCount++
-				failedReasons = append(failedReasons, domain.FailedReason{
-					Row:    fmt.Sprintf("第 %d 行", i+1),
-					Reason: err.Error(),
-				})
-			} else {
-				successCount++
+				return err
+			}
+			for _, account := range existAccounts {
+				accountSet[account] = struct{}{}
+			}
+
+			for _, row := range rows {
+				var sms *domain.Sms
+				v := row.Data
+				err = func() error {
+					if row.Err != nil {
+						return row.Err
+					}
+					account := strings.TrimSpace(v.Account)
+					if account == "" {
+						return errors.New("账号不能为空")
+					}
+					if _, ok := accountSet[account]; ok {
+						return errors.New("账号已存在")
+					}
+					if strings.TrimSpace(v.NickName) == "" {
+						return errors.New("昵称不能为空")
+					}
+					if v.Role == "" {
+						return errors.New("角色不能为空")
+					}
+					role, ok := roleMap[strings.TrimSpace(v.Role)]
+					if !ok {
+						return fmt.Errorf("角色 %s 不存在", v.Role)
+					}
+					if v.Phone != "" && !phoneRegexp.MatchString(v.Phone) {
+						return errors.New("手机号格式错误")
+					}
+
+					password := v.Password
+					if password == "" {
+						password, err = str.GeneratePassword(12)
+						if err != nil {
+							return err
+						}
+					} else if len(password) < 6 || len(password) > 64 {
+						return errors.New("初始密码长度为6-64位")
+					}
+					hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+					if err != nil {
+						return err
+					}
+
+					user := domain.SysUser{
+						Account:     account,
+						Password:    string(hash),
+						NickName:    strings.TrimSpace(v.NickName),
+						Phone:       v.Phone,
+						Department:  strings.TrimSpace(v.Department),
+						DefaultRole: role.ID,
+						Status:      constants.SysUserStatusActive,
+						Roles:       []domain.SysRole{role},
+					}
+					if err = global.DB.WithContext(ctx).Omit("Roles.*").Create(&user).Error; err != nil {
+						return err
+					}
+					accountSet[account] = struct{}{}
+
+					if user.Phone != "" {
+						sms = &domain.Sms{
+							PhoneNumber:  user.Phone,
+							SignName:     conf.Conf.SMSConfig.SmsSignName,
+							TemplateCode: conf.Conf.SMSConfig.SmsCredentialTpl,
+							TemplateParams: map[string]string{
+								"account":  account,
+								"password": password,
+							},
+						}
+					}
+					return nil
+				}()
+
+				// 统一错误处理
+				if err != nil {
+					failureCount++
+					failedReasons = append(failedReasons, domain.FailedReason{
+						Row:    fmt.Sprintf("第 %d 行", row.Num),
+						Reason: err.Error(),
+					})
+				} else {
+					successCount++
+					if sms != nil {
+						credentials = append(credentials, *sms)
+					}
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		// 修改导入信息
@@ -58,4 +252,16 @@ func importDemo(ctx context.Context, payload domain.DataImport) error {
 		}
 		return global.DB.WithContext(ctx).Model(&payload).Updates(&payload).Error
 	})
+	if err != nil {
+		return err
+	}
+
+	// 事务提交后再发送短信，避免回滚后用户收到无效的账号
+	for _, v := range credentials {
+		if err := global.Producer.NewTask(constants.QueueSms, v); err != nil {
+			logger.Error("发送账号短信失败", zap.Error(err), zap.String("phone", v.PhoneNumber))
+		}
+	}
+
+	return nil
 }
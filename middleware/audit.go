@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/common"
+	"github.com/Madou-Shinni/gin-quickstart/constants"
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/domain"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
+	"github.com/Madou-Shinni/go-logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAuditBodyLength = 2048
+	auditRespBodyLength    = 1024 // 只需要解析响应中的code、message
+)
+
+// Audit 操作日志
+// 记录 POST、PUT、DELETE 请求的操作人、请求参数(已脱敏)、返回结果和耗时，通过消息队列异步写入
+func Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config := conf.Conf.AuditConfig
+		if config == nil || !config.Enable || !isMutating(c.Request.Method) || skipAudit(config.SkipPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		maxBodyLength := config.MaxBodyLength
+		if maxBodyLength <= 0 {
+			maxBodyLength = defaultAuditBodyLength
+		}
+
+		start := time.Now()
+		var body []byte
+		if !strings.Contains(c.ContentType(), "multipart") && c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// 表单和文件上传只记录表单字段
+		if len(body) == 0 {
+			if c.Request.MultipartForm != nil {
+				body = []byte(tools.HideFormFields(c.Request.MultipartForm.Value, config.MaskFields).Encode())
+			} else if c.Request.PostForm != nil {
+				body = []byte(tools.HideFormFields(c.Request.PostForm, config.MaskFields).Encode())
+			}
+		} else if strings.Contains(c.ContentType(), "form") {
+			values, _ := url.ParseQuery(string(body))
+			body = []byte(tools.HideFormFields(values, config.MaskFields).Encode())
+		} else {
+			body = tools.HideJSONFields(body, config.MaskFields)
+		}
+
+		userId, _ := common.GetUserIdFromCtx(c)
+		sysOperationLog := domain.SysOperationLog{
+			UserID:    userId,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Route:     c.FullPath(),
+			Query:     tools.HideFormFields(c.Request.URL.Query(), config.MaskFields).Encode(),
+			Body:      truncateRunes(string(body), maxBodyLength),
+			Status:    c.Writer.Status(),
+			Code:      c.Writer.Status(),
+			Latency:   time.Since(start).Milliseconds(),
+			IP:        c.ClientIP(),
+			UserAgent: truncateRunes(c.Request.UserAgent(), 512),
+		}
+
+		// 业务状态码
+		if code, msg := parseResponse(writer.body.Bytes()); code != 0 {
+			sysOperationLog.Code = code
+			sysOperationLog.Msg = truncateRunes(msg, 255)
+		}
+
+		if global.Producer == nil {
+			return
+		}
+		if err := global.Producer.NewTask(constants.QueueAuditLog, sysOperationLog); err != nil {
+			logger.Error("操作日志入队失败", zap.Error(err), zap.Any("domain.SysOperationLog", sysOperationLog))
+		}
+	}
+}
+
+// parseResponse 从响应体中解析 code、message
+// 响应体可能被截断，所以逐个token解析，不要求json完整
+func parseResponse(body []byte) (code int, msg string) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return 0, ""
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return
+		}
+		switch t {
+		case "code":
+			if dec.Decode(&code) != nil {
+				return
+			}
+		case "message":
+			if dec.Decode(&msg) != nil {
+				return
+			}
+		default:
+			var skip json.RawMessage
+			if dec.Decode(&skip) != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func isMutating(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodDelete
+}
+
+func skipAudit(skipPaths []string, path string) bool {
+	for _, p := range skipPaths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// auditResponseWriter 只保留响应的前 auditRespBodyLength 个字节，导出文件等大响应不会占用过多内存
+type auditResponseWriter struct {
+	gin.ResponseWriter
+
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditResponseWriter) capture(b []byte) {
+	if remain := auditRespBodyLength - w.body.Len(); remain > 0 {
+		if len(b) > remain {
+			b = b[:remain]
+		}
+		w.body.Write(b)
+	}
+}
@@ -1,16 +1,31 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/Madou-Shinni/gin-quickstart/constants"
 	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
 	"github.com/gin-gonic/gin"
 )
 
+var errUserStatusChecker = errors.New("未设置账号状态校验")
+
+// UserStatusChecker 校验账号是否可用，禁用、锁定、过期、已删除的账号返回错误
+type UserStatusChecker func(ctx context.Context, userID uint) error
+
+var checkUserStatus UserStatusChecker
+
+// SetUserStatusChecker 设置 JwtAuth 校验账号状态的方法，由 initialize 注入，没有设置时拒绝所有请求
+func SetUserStatusChecker(fn UserStatusChecker) {
+	checkUserStatus = fn
+}
+
 // jwt认证
 // 解析请求头中的token
 // 解析成功则通过，失败返回错误信息
@@ -33,9 +48,30 @@ func JwtAuth() gin.HandlerFunc {
 		userId := claims[tools.UserIdKey]
 		roleId := claims[tools.RoleIdKey]
 
+		// 校验账号状态 禁用、锁定、过期的账号token立即失效
+		uid, _ := userId.(float64)
+		if checkUserStatus == nil {
+			err = errUserStatusChecker
+		} else {
+			err = checkUserStatus(c.Request.Context(), uint(uid))
+		}
+		if err != nil {
+			response.Error(c, constant.CODE_NO_PERMISSIONS, err.Error())
+			c.Abort()
+			return
+		}
+
 		// 将解析的userId保存到上下文中
 		c.Set(constants.CtxUserIdKey, userId)
 		c.Set(constants.CtxRoleIdkEY, roleId)
+		if tenant, ok := claims[tools.TenantKey]; ok {
+			c.Set(constants.CtxTenantKey, tenant)
+		}
+		if superAdmin, ok := claims[tools.SuperAdminKey].(bool); ok {
+			c.Set(constants.CtxSuperAdminKey, superAdmin)
+		}
+		// 操作人随上下文传递，用于记录变更历史
+		c.Request = c.Request.WithContext(gorm_plugin.WithActor(c.Request.Context(), uint(uid)))
 		c.Next()
 	}
 }
@@ -3,6 +3,7 @@ package middleware
 import (
 	"bytes"
 	"fmt"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
 	"github.com/Madou-Shinni/go-logger"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -71,6 +72,7 @@ func GinLogger() gin.HandlerFunc {
 			zap.String("user-agent", c.Request.UserAgent()),
 			zap.String("errors", c.Errors.ByType(gin.ErrorTypePrivate).String()),
 			zap.String("cost", costTime),
+			zap.String("trace-id", gorm_plugin.TraceIDFromContext(c.Request.Context())),
 		)
 	}
 }
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/Madou-Shinni/gin-quickstart/common"
+	"github.com/Madou-Shinni/gin-quickstart/internal/conf"
+	"github.com/Madou-Shinni/gin-quickstart/internal/service"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/constant"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/global"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/response"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTenantHeader       = "X-Tenant-ID"
+	defaultTenantBypassHeader = "X-Tenant-Bypass"
+)
+
+var sysTenantService = service.NewSysTenantService()
+
+// Tenant 解析租户并写入上下文，需要在 JwtAuth 之后使用
+// 租户用户只能访问token中的租户；超级管理员通过请求头或子域名指定租户，
+// 或者通过跨租户请求头显式跨租户操作；不是超级管理员的平台用户(token中租户为0)不能访问租户数据
+func Tenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config := conf.Conf.TenantConfig
+		if config == nil || !config.Enable {
+			c.Next()
+			return
+		}
+
+		tenantID, ok := common.GetTenantFromCtx(c)
+		if !ok {
+			abortTenant(c, tools.ErrorUserInfo)
+			return
+		}
+		ctx := c.Request.Context()
+		superAdmin := tenantID == 0 && common.IsSuperAdmin(c)
+
+		if superAdmin && strings.EqualFold(c.GetHeader(tenantBypassHeader(config)), "true") {
+			c.Request = c.Request.WithContext(gorm_plugin.WithTenantBypass(ctx))
+			c.Next()
+			return
+		}
+
+		requested, err := resolveTenant(c, config)
+		if err != nil {
+			abortTenant(c, err)
+			return
+		}
+		switch {
+		case superAdmin:
+			tenantID = requested
+		case requested != 0 && requested != tenantID:
+			abortTenant(c, service.ErrorTenantDenied)
+			return
+		}
+		if tenantID == 0 {
+			abortTenant(c, service.ErrorTenantRequired)
+			return
+		}
+
+		if err = sysTenantService.Check(ctx, tenantID); err != nil {
+			abortTenant(c, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(gorm_plugin.WithTenant(ctx, tenantID))
+		c.Next()
+	}
+}
+
+// SuperAdmin 只允许超级管理员访问，可以跨租户操作，需要在 JwtAuth 之后使用
+func SuperAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenantID, ok := common.GetTenantFromCtx(c); !ok || tenantID != 0 || !common.IsSuperAdmin(c) {
+			response.Error(c, constant.CODE_NO_PERMISSIONS, constant.CODE_NO_PERMISSIONS.Msg())
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(gorm_plugin.WithTenantBypass(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// SharedDB 平台数据(用户、菜单、接口、日志等)只保存在共享库，不使用租户的独立数据库
+func SharedDB() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(global.WithShared(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// resolveTenant 从请求头或子域名获取请求的租户，都没有时返回0
+func resolveTenant(c *gin.Context, config *conf.TenantConfig) (uint, error) {
+	header := config.Header
+	if header == "" {
+		header = defaultTenantHeader
+	}
+	if v := c.GetHeader(header); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return 0, service.ErrorTenantNotExist
+		}
+		return uint(id), nil
+	}
+
+	if config.Domain == "" {
+		return 0, nil
+	}
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	code, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(config.Domain))
+	if !ok || code == "" || strings.Contains(code, ".") {
+		return 0, nil
+	}
+	return sysTenantService.Resolve(c.Request.Context(), code)
+}
+
+func tenantBypassHeader(config *conf.TenantConfig) string {
+	if config.BypassHeader == "" {
+		return defaultTenantBypassHeader
+	}
+	return config.BypassHeader
+}
+
+func abortTenant(c *gin.Context, err error) {
+	response.Error(c, constant.CODE_NO_PERMISSIONS, err.Error())
+	c.Abort()
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const TraceIDHeader = "X-Request-Id"
+
+// Trace 为每个请求生成链路id，优先使用请求头中的 X-Request-Id
+// 链路id会写入响应头，并随上下文传递到sql日志和变更历史中
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(TraceIDHeader)
+		if traceID == "" || len(traceID) > 64 {
+			traceID = uuid.NewString()
+		}
+
+		c.Header(TraceIDHeader, traceID)
+		c.Request = c.Request.WithContext(gorm_plugin.WithTraceID(c.Request.Context(), traceID))
+		c.Next()
+	}
+}
@@ -0,0 +1,212 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 基线迁移，表结构为引入迁移之前 AutoMigrate 创建的结构
+// 这里的模型是当时的快照，之后修改 domain 时需要新增迁移，不要修改这里
+func init() {
+	register(&migrate.Migration{
+		Version: "20240101000000",
+		Name:    "init",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(initModels...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(initModels...)
+		},
+	})
+}
+
+var initModels = []interface{}{
+	&initDemo{},
+	&initFile{},
+	&initSysUser{},
+	&initSysRole{},
+	&initSysUserSysRole{},
+	&initSysRoleSysMenu{},
+	&initSysApi{},
+	&initSysMenu{},
+	&initDataImport{},
+	&initSystemFile{},
+	&initSysLoginLog{},
+	&initSysOperationLog{},
+	&initSysChangeHistory{},
+}
+
+type initModel struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type initAuditModel struct {
+	Model     initModel `gorm:"embedded"`
+	CreatedBy uint      `gorm:"index;default:0;not null"`
+	UpdatedBy uint      `gorm:"default:0;not null"`
+	DeletedBy uint      `gorm:"default:0;not null"`
+}
+
+type initVersion struct {
+	Version uint `gorm:"default:1;not null"`
+}
+
+type initDemo struct {
+	AuditModel initAuditModel `gorm:"embedded"`
+	Version    initVersion    `gorm:"embedded"`
+	Name       string
+	Age        int
+	BirthDay   *time.Time
+	Tags       datatypes.JSON
+}
+
+func (initDemo) TableName() string { return "demo" }
+
+type initFile struct {
+	ID           int64  `gorm:"column:id;comment:主键;primarykey"`
+	FileMd5      string `gorm:"column:file_md5;comment:文件MD5"`
+	FileSize     string `gorm:"column:file_size;comment:文件大小"`
+	FilePath     string `gorm:"column:file_path;comment:文件路径"`
+	FileName     string `gorm:"column:file_name;comment:文件名"`
+	TotalChunk   int    `gorm:"column:total_chunk;comment:文件总分片数"`
+	AlreadyChunk string `gorm:"column:already_chunk;comment:已经上传的分片"`
+}
+
+func (initFile) TableName() string { return "file" }
+
+type initSysUser struct {
+	AuditModel  initAuditModel `gorm:"embedded"`
+	Account     string         `gorm:"size:255;unique;not null"`
+	Password    string         `gorm:"size:255;not null"`
+	NickName    string         `gorm:"size:255;not null"`
+	Phone       string         `gorm:"size:20;index"`
+	Department  string         `gorm:"size:64;index"`
+	DefaultRole uint           `gorm:"column:default_role"`
+	Status      string         `gorm:"type:varchar(16);index;default:active;not null"`
+	ExpiredAt   *time.Time
+}
+
+func (initSysUser) TableName() string { return "sys_user" }
+
+type initSysRole struct {
+	AuditModel initAuditModel `gorm:"embedded"`
+	Version    initVersion    `gorm:"embedded"`
+	ParentID   uint           `gorm:"column:parent_id"`
+	RoleName   string         `gorm:"column:role_name"`
+}
+
+func (initSysRole) TableName() string { return "sys_role" }
+
+type initSysUserSysRole struct {
+	SysUserID uint `gorm:"primaryKey;autoIncrement:false"`
+	SysRoleID uint `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (initSysUserSysRole) TableName() string { return "sys_user_sys_role" }
+
+type initSysRoleSysMenu struct {
+	SysRoleID uint `gorm:"primaryKey;autoIncrement:false"`
+	SysMenuID uint `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (initSysRoleSysMenu) TableName() string { return "sys_role_sys_menu" }
+
+type initSysApi struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	Name      string `gorm:"name"`
+	Method    string `gorm:"index:idx_method_path,unique"`
+	Path      string `gorm:"index:idx_method_path,unique"`
+}
+
+func (initSysApi) TableName() string { return "sys_api" }
+
+type initSysMenu struct {
+	AuditModel  initAuditModel `gorm:"embedded"`
+	Name        string         `gorm:"size:255;unique;not null"`
+	Icon        string         `gorm:"size:255;not null"`
+	ParentID    uint           `gorm:"default:0"`
+	Description string         `gorm:"size:255;"`
+}
+
+func (initSysMenu) TableName() string { return "sys_menu" }
+
+type initDataImport struct {
+	AuditModel    initAuditModel `gorm:"embedded"`
+	FileName      string         `gorm:"type:varchar(64);not null;"`
+	FileUrl       string         `gorm:"type:varchar(512);not null;"`
+	Status        string         `gorm:"type:varchar(16);index;default:importing;not null;"`
+	Category      string         `gorm:"type:varchar(32);index;default:'';not null;"`
+	Count         uint           `gorm:"type:int;default:0;not null;"`
+	SuccessCount  uint           `gorm:"type:int;default:0;not null;"`
+	FailureCount  uint           `gorm:"type:int;default:0;not null;"`
+	FailedReasons datatypes.JSON `gorm:"type:json"`
+}
+
+func (initDataImport) TableName() string { return "data_import" }
+
+type initSystemFile struct {
+	ID         uint `gorm:"primaryKey"`
+	FileName   string
+	Path       string
+	IsDir      bool
+	Size       int64
+	CreateTime string
+}
+
+func (initSystemFile) TableName() string { return "system_file" }
+
+type initSysLoginLog struct {
+	Model     initModel `gorm:"embedded"`
+	UserID    uint      `gorm:"column:user_id;index"`
+	Account   string    `gorm:"size:255;index;not null"`
+	LoginType string    `gorm:"type:varchar(16);index;not null"`
+	Result    string    `gorm:"type:varchar(16);index;not null"`
+	Reason    string    `gorm:"size:255;default:''"`
+	IP        string    `gorm:"size:64;index;default:''"`
+	Location  string    `gorm:"size:128;default:''"`
+	UserAgent string    `gorm:"size:512;default:''"`
+	Browser   string    `gorm:"size:64;default:''"`
+	OS        string    `gorm:"column:os;size:64;default:''"`
+}
+
+func (initSysLoginLog) TableName() string { return "sys_login_log" }
+
+type initSysOperationLog struct {
+	Model     initModel `gorm:"embedded"`
+	UserID    uint      `gorm:"column:user_id;index"`
+	ApiName   string    `gorm:"size:255;default:''"`
+	Method    string    `gorm:"type:varchar(16);index;not null"`
+	Path      string    `gorm:"size:255;index;not null"`
+	Route     string    `gorm:"size:255;default:''"`
+	Query     string    `gorm:"type:text"`
+	Body      string    `gorm:"type:text"`
+	Status    int       `gorm:"type:int;default:0;not null"`
+	Code      int       `gorm:"type:int;index;default:0;not null"`
+	Msg       string    `gorm:"size:255;default:''"`
+	Latency   int64     `gorm:"type:bigint;default:0;not null"`
+	IP        string    `gorm:"size:64;index;default:''"`
+	UserAgent string    `gorm:"size:512;default:''"`
+}
+
+func (initSysOperationLog) TableName() string { return "sys_operation_log" }
+
+type initSysChangeHistory struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt *time.Time
+	Table     string         `gorm:"column:table_name;size:64;index:idx_table_record;not null"`
+	RecordID  string         `gorm:"size:64;index:idx_table_record;not null"`
+	Action    string         `gorm:"type:varchar(16);not null"`
+	Changes   datatypes.JSON `gorm:"type:json"`
+	ActorID   uint           `gorm:"index"`
+	TraceID   string         `gorm:"size:64;index;default:''"`
+}
+
+func (initSysChangeHistory) TableName() string { return "sys_change_history" }
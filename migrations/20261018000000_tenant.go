@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+// 多租户：租户表，用户和导入记录增加租户
+// 已有的用户租户为0，即平台用户
+func init() {
+	register(&migrate.Migration{
+		Version: "20261018000000",
+		Name:    "tenant",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&tenantSysTenant{}); err != nil {
+				return err
+			}
+			for _, model := range tenantModels {
+				if err := tx.Migrator().AddColumn(model, "TenantID"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(model, "TenantID"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range tenantModels {
+				if err := tx.Migrator().DropIndex(model, "TenantID"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(model, "TenantID"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&tenantSysTenant{})
+		},
+	})
+}
+
+var tenantModels = []interface{}{
+	&tenantSysUser{},
+	&tenantDataImport{},
+}
+
+type tenantColumn struct {
+	TenantID uint `gorm:"index;default:0;not null"`
+}
+
+type tenantSysUser struct {
+	Tenant tenantColumn `gorm:"embedded"`
+}
+
+func (tenantSysUser) TableName() string { return "sys_user" }
+
+type tenantDataImport struct {
+	Tenant tenantColumn `gorm:"embedded"`
+}
+
+func (tenantDataImport) TableName() string { return "data_import" }
+
+type tenantSysTenant struct {
+	AuditModel initAuditModel `gorm:"embedded"`
+	Name       string         `gorm:"size:255;not null"`
+	Code       string         `gorm:"size:64;unique;not null"`
+	Plan       string         `gorm:"type:varchar(16);index;default:free;not null"`
+	Status     string         `gorm:"type:varchar(16);index;default:active;not null"`
+	ExpiredAt  *time.Time
+	Remark     string `gorm:"size:255;default:''"`
+}
+
+func (tenantSysTenant) TableName() string { return "sys_tenant" }
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+// 租户独立数据库连接字符串
+func init() {
+	register(&migrate.Migration{
+		Version: "20261018000001",
+		Name:    "tenant_dsn",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&tenantDSN{}, "DSN")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&tenantDSN{}, "DSN")
+		},
+	})
+}
+
+type tenantDSN struct {
+	DSN string `gorm:"column:dsn;size:512;default:''"`
+}
+
+func (tenantDSN) TableName() string { return "sys_tenant" }
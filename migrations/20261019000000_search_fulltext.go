@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+// 关键词搜索的FULLTEXT索引，只有mysql需要，中文使用ngram分词
+func init() {
+	register(&migrate.Migration{
+		Version: "20261019000000",
+		Name:    "search_fulltext",
+		Up: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "mysql" {
+				return nil
+			}
+			for _, index := range searchFullTextIndexes {
+				if err := tx.Exec("CREATE FULLTEXT INDEX " + index.name + " ON " + index.table + " (" + index.columns + ") WITH PARSER ngram").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "mysql" {
+				return nil
+			}
+			for _, index := range searchFullTextIndexes {
+				if err := tx.Exec("DROP INDEX " + index.name + " ON " + index.table).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+var searchFullTextIndexes = []struct {
+	table, name, columns string
+}{
+	{table: "demo", name: "idx_demo_search", columns: "name"},
+	{table: "sys_user", name: "idx_sys_user_search", columns: "account, nick_name"},
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 列表异步导出记录
+func init() {
+	register(&migrate.Migration{
+		Version: "20261019000001",
+		Name:    "data_export",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&exportDataExport{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&exportDataExport{})
+		},
+	})
+}
+
+type exportDataExport struct {
+	AuditModel initAuditModel `gorm:"embedded"`
+	Tenant     tenantColumn   `gorm:"embedded"`
+	Resource   string         `gorm:"type:varchar(64);index;not null;"`
+	Format     string         `gorm:"type:varchar(8);not null;"`
+	FileName   string         `gorm:"type:varchar(128);not null;"`
+	FileUrl    string         `gorm:"type:varchar(512);default:'';not null;"`
+	Status     string         `gorm:"type:varchar(16);index;default:exporting;not null;"`
+	Count      uint           `gorm:"type:int;default:0;not null;"`
+	Reason     string         `gorm:"type:varchar(255);default:'';not null;"`
+	Query      datatypes.JSON `gorm:"type:json"`
+}
+
+func (exportDataExport) TableName() string { return "data_export" }
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+// 超级管理员标记，只有标记的平台用户可以跨租户操作、管理租户
+// 已有的用户都不是超级管理员，需要手动设置 sys_user.super_admin
+func init() {
+	register(&migrate.Migration{
+		Version: "20261019000002",
+		Name:    "super_admin",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&superAdminSysUser{}, "SuperAdmin")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&superAdminSysUser{}, "SuperAdmin")
+		},
+	})
+}
+
+type superAdminSysUser struct {
+	SuperAdmin bool `gorm:"default:false;not null"`
+}
+
+func (superAdminSysUser) TableName() string { return "sys_user" }
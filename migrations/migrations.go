@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/migrate"
+)
+
+// SQLDir sql迁移文件目录，migrate create 在该目录下创建文件
+const SQLDir = "migrations/sql"
+
+//go:embed sql
+var sqlFS embed.FS
+
+// go迁移，在各自文件的 init 中注册
+var goMigrations []*migrate.Migration
+
+func register(m *migrate.Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+// Load 加载go迁移和sql迁移
+func Load(m *migrate.Migrator) error {
+	if err := m.Register(goMigrations...); err != nil {
+		return err
+	}
+	sub, err := fs.Sub(sqlFS, "sql")
+	if err != nil {
+		return err
+	}
+	return m.LoadFS(sub)
+}
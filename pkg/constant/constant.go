@@ -10,6 +10,8 @@ const (
 
 	FileChunkHkey   = "filechunkid:"    // redis HKey 分片文件
 	FileChunkHFiled = "filechunkindex:" // redis HFiled 分片文件
+
+	ContentTypeXlsx = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" // excel文件下载
 )
 
 // 错误码
@@ -22,6 +24,7 @@ const (
 	CODE_DELETE_FAILED                           // 删除失败
 	CODE_UPDATE_FAILED                           // 修改失败
 	CODE_FIND_FAILED                             // 查询失败
+	CODE_VERSION_CONFLICT                        // 版本冲突
 )
 
 var codeMsgMap = map[RspCode]string{
@@ -33,6 +36,7 @@ var codeMsgMap = map[RspCode]string{
 	CODE_DELETE_FAILED:     "删除失败！",
 	CODE_UPDATE_FAILED:     "修改失败！",
 	CODE_FIND_FAILED:       "查询失败",
+	CODE_VERSION_CONFLICT:  "数据已被修改，请刷新后重试！",
 }
 
 func (c RspCode) Msg() string {
@@ -6,6 +6,7 @@ import (
 	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/message_queue"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 // 全局变量
@@ -17,33 +18,100 @@ var (
 )
 
 type Data struct {
-	db *gorm.DB
+	db      *gorm.DB
+	tenants *TenantDBs
 }
 
 func NewData(db *gorm.DB) *Data {
 	return &Data{db: db}
 }
 
-type contextTxKey struct{}
+type (
+	contextTxKey          struct{}
+	contextPrimaryKey     struct{}
+	contextAfterCommitKey struct{}
+)
+
+// afterCommit 事务提交后执行的函数
+type afterCommit struct {
+	fns []func()
+}
+
+// AfterCommit 在ctx的事务(Tx)提交后执行 fn，事务回滚时不执行，ctx中没有事务时立即执行
+// 用于投递依赖已提交数据的异步任务
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(contextAfterCommitKey{}).(*afterCommit); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
+// WithPrimary 使用该ctx的查询都走主库
+// 配置了从库时查询默认走从库，写入后需要立即读取(read-your-writes)时使用
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextPrimaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(contextPrimaryKey{}).(bool)
+	return v
+}
 
 // Tx gorm Transaction
+// 事务始终在主库中执行，提交后执行事务中通过 AfterCommit 注册的函数
 func (d *Data) Tx(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent, _ := ctx.Value(contextAfterCommitKey{}).(*afterCommit)
+	hooks := &afterCommit{}
+	ctx = context.WithValue(ctx, contextAfterCommitKey{}, hooks)
+
 	if tx, ok := ctx.Value(contextTxKey{}).(*gorm.DB); ok {
-		// 嵌套事务处理
-		return tx.Transaction(func(tx *gorm.DB) error {
-			return fn(ctx)
+		// 嵌套事务处理，回滚到保存点时丢弃注册的函数，成功时交给外层事务
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, contextTxKey{}, tx.WithContext(ctx)))
+		})
+		if err != nil {
+			return err
+		}
+		if parent != nil {
+			parent.fns = append(parent.fns, hooks.fns...)
+			return nil
+		}
+	} else {
+		db, err := d.conn(ctx)
+		if err != nil {
+			return err
+		}
+		err = db.WithContext(ctx).Clauses(dbresolver.Write).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, contextTxKey{}, tx))
 		})
+		if err != nil {
+			return err
+		}
 	}
-	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		ctx = context.WithValue(ctx, contextTxKey{}, tx)
-		return fn(ctx)
-	})
+
+	for _, f := range hooks.fns {
+		f()
+	}
+	return nil
 }
 
+// WithContext 返回ctx对应的数据库
+// ctx中有事务时返回事务，租户有独立数据库时返回租户的数据库
 func (d *Data) WithContext(ctx context.Context) *gorm.DB {
 	tx, ok := ctx.Value(contextTxKey{}).(*gorm.DB)
 	if ok {
 		return tx
 	}
-	return d.db.WithContext(ctx)
+	db, err := d.conn(ctx)
+	if err != nil {
+		// 不能回退到共享库，返回带错误的会话，之后的操作都会失败
+		db = d.db.WithContext(ctx)
+		_ = db.AddError(err)
+		return db
+	}
+	if usePrimary(ctx) {
+		return db.WithContext(ctx).Clauses(dbresolver.Write).Session(&gorm.Session{})
+	}
+	return db.WithContext(ctx)
 }
@@ -0,0 +1,115 @@
+package global
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type article struct {
+	ID    uint
+	Title string
+}
+
+func TestData_Replicas(t *testing.T) {
+	dir := t.TempDir()
+	primaryPath, replicaPath := filepath.Join(dir, "primary.db"), filepath.Join(dir, "replica.db")
+
+	replica, err := gorm.Open(sqlite.Open(replicaPath), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, replica.AutoMigrate(&article{}))
+	assert.NoError(t, replica.Create(&article{Title: "replica"}).Error)
+
+	db, err := gorm.Open(sqlite.Open(primaryPath), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&article{}))
+	assert.NoError(t, db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{sqlite.Open(replicaPath)},
+	})))
+	data := NewData(db)
+	ctx := context.Background()
+
+	// 写入走主库
+	assert.NoError(t, data.WithContext(ctx).Create(&article{Title: "primary"}).Error)
+
+	var res article
+	assert.NoError(t, data.WithContext(ctx).First(&res).Error)
+	assert.Equal(t, "replica", res.Title)
+
+	// 指定主库
+	res = article{}
+	assert.NoError(t, data.WithContext(WithPrimary(ctx)).First(&res).Error)
+	assert.Equal(t, "primary", res.Title)
+	db2 := data.WithContext(WithPrimary(ctx))
+	var count int64
+	assert.NoError(t, db2.Model(&article{}).Count(&count).Error)
+	res = article{}
+	assert.NoError(t, db2.First(&res).Error)
+	assert.Equal(t, "primary", res.Title)
+
+	// 事务中走主库
+	err = data.Tx(ctx, func(ctx context.Context) error {
+		res = article{}
+		return data.WithContext(ctx).First(&res).Error
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", res.Title)
+}
+
+func TestData_AfterCommit(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&article{}))
+	data := NewData(db)
+
+	var calls []string
+	hook := func(ctx context.Context, name string) {
+		AfterCommit(ctx, func() {
+			var count int64
+			// 提交后可以在事务外读取到数据
+			db.Model(&article{}).Where("title = ?", name).Count(&count)
+			calls = append(calls, fmt.Sprint(name, count))
+		})
+	}
+
+	// 没有事务时立即执行
+	hook(context.Background(), "none")
+	assert.Equal(t, []string{"none0"}, calls)
+
+	// 提交后执行，回滚的嵌套事务不执行
+	calls = nil
+	err = data.Tx(context.Background(), func(ctx context.Context) error {
+		if err := data.WithContext(ctx).Create(&article{Title: "outer"}).Error; err != nil {
+			return err
+		}
+		hook(ctx, "outer")
+		_ = data.Tx(ctx, func(ctx context.Context) error {
+			hook(ctx, "rollback")
+			return errors.New("rollback")
+		})
+		_ = data.Tx(ctx, func(ctx context.Context) error {
+			hook(ctx, "nested")
+			return data.WithContext(ctx).Create(&article{Title: "nested"}).Error
+		})
+		assert.Empty(t, calls)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer1", "nested1"}, calls)
+
+	// 回滚时不执行
+	calls = nil
+	err = data.Tx(context.Background(), func(ctx context.Context) error {
+		hook(ctx, "failed")
+		return errors.New("failed")
+	})
+	assert.Error(t, err)
+	assert.Empty(t, calls)
+}
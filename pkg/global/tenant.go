@@ -0,0 +1,223 @@
+package global
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+const (
+	// 租户没有独立数据库时，检查结果的缓存时间，分配独立数据库后最多经过该时间生效
+	sharedTenantTTL = time.Minute
+	// Evict 后等待该时间再关闭旧连接，已经取到旧连接的请求可以正常执行完
+	tenantDBCloseDelay = time.Minute
+)
+
+var ErrTenantDBDisabled = errors.New("未开启租户独立数据库")
+
+type contextSharedKey struct{}
+
+// WithShared 使用该ctx的操作都在共享库执行
+// 平台数据(用户、菜单、接口、日志等)只保存在共享库，租户的独立数据库只保存业务数据
+func WithShared(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextSharedKey{}, true)
+}
+
+// IsShared ctx 是否指定使用共享库
+func IsShared(ctx context.Context) bool {
+	v, _ := ctx.Value(contextSharedKey{}).(bool)
+	return v
+}
+
+// TenantDBConfig 租户独立数据库配置
+type TenantDBConfig struct {
+	// 查询租户的独立数据库连接字符串，为空则使用共享库
+	Lookup func(ctx context.Context, tenantID uint) (string, error)
+	// 根据连接字符串打开数据库，驱动与共享库一致
+	Open func(dsn string) (*gorm.DB, error)
+	// 对数据库执行迁移
+	Migrate func(ctx context.Context, db *gorm.DB) error
+}
+
+type tenantDB struct {
+	db        *gorm.DB // 为空则使用共享库
+	checkedAt time.Time
+}
+
+func (c *tenantDB) valid() bool {
+	return c.db != nil || time.Since(c.checkedAt) < sharedTenantTTL
+}
+
+// TenantDBs 租户独立数据库
+// 第一次访问租户时创建连接，之后复用连接池
+type TenantDBs struct {
+	config     TenantDBConfig
+	mu         sync.RWMutex
+	dbs        map[uint]*tenantDB
+	evicts     map[uint]uint64 // 租户 Evict 的次数，打开数据库期间被 Evict 时不缓存打开的连接
+	group      singleflight.Group
+	closeDelay time.Duration
+}
+
+func NewTenantDBs(config TenantDBConfig) *TenantDBs {
+	return &TenantDBs{config: config, dbs: make(map[uint]*tenantDB), evicts: make(map[uint]uint64), closeDelay: tenantDBCloseDelay}
+}
+
+// Get 返回租户的独立数据库，没有则返回nil
+// 同一个租户同时只有一个请求查询连接字符串并打开数据库，不影响其它租户
+func (t *TenantDBs) Get(ctx context.Context, tenantID uint) (*gorm.DB, error) {
+	t.mu.RLock()
+	cached, ok := t.dbs[tenantID]
+	t.mu.RUnlock()
+	if ok && cached.valid() {
+		return cached.db, nil
+	}
+
+	// 结果由所有等待的请求共用，不受第一个请求取消的影响
+	ctx = context.WithoutCancel(ctx)
+	db, err, _ := t.group.Do(strconv.FormatUint(uint64(tenantID), 10), func() (interface{}, error) {
+		return t.open(ctx, tenantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.(*gorm.DB), nil
+}
+
+// open 查询租户的连接字符串并打开数据库，不持有锁
+func (t *TenantDBs) open(ctx context.Context, tenantID uint) (*gorm.DB, error) {
+	t.mu.RLock()
+	cached, ok := t.dbs[tenantID]
+	evicts := t.evicts[tenantID]
+	t.mu.RUnlock()
+	if ok && cached.valid() {
+		return cached.db, nil
+	}
+
+	dsn, err := t.config.Lookup(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	cached = &tenantDB{checkedAt: time.Now()}
+	if dsn != "" {
+		if cached.db, err = t.config.Open(dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	if t.evicts[tenantID] != evicts {
+		// 打开期间连接字符串可能已经变更，本次使用后关闭，下次访问重新查询
+		t.mu.Unlock()
+		t.closeLater(cached.db)
+		return cached.db, nil
+	}
+	t.dbs[tenantID] = cached
+	t.mu.Unlock()
+	return cached.db, nil
+}
+
+// Evict 移除租户的连接，下次访问时重新查询连接字符串
+// 旧连接延迟关闭，已经取到旧连接的请求可以正常执行完
+func (t *TenantDBs) Evict(tenantID uint) {
+	t.mu.Lock()
+	cached, ok := t.dbs[tenantID]
+	delete(t.dbs, tenantID)
+	t.evicts[tenantID]++
+	t.mu.Unlock()
+	t.group.Forget(strconv.FormatUint(uint64(tenantID), 10))
+
+	if ok {
+		t.closeLater(cached.db)
+	}
+}
+
+// closeLater 等待 closeDelay 后关闭连接
+func (t *TenantDBs) closeLater(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	time.AfterFunc(t.closeDelay, func() {
+		closeDB(db)
+	})
+}
+
+// Provision 检查连接字符串并对数据库执行迁移，成功后需要保存连接字符串并调用 Evict
+func (t *TenantDBs) Provision(ctx context.Context, dsn string) error {
+	db, err := t.config.Open(dsn)
+	if err != nil {
+		return err
+	}
+	defer closeDB(db)
+	return t.config.Migrate(ctx, db)
+}
+
+// Open 打开数据库，用于迁移等需要遍历所有租户数据库的操作，使用后需要关闭
+func (t *TenantDBs) Open(dsn string) (*gorm.DB, error) {
+	return t.config.Open(dsn)
+}
+
+// Close 关闭所有租户的连接
+func (t *TenantDBs) Close() {
+	t.mu.Lock()
+	dbs := t.dbs
+	t.dbs = make(map[uint]*tenantDB)
+	t.mu.Unlock()
+
+	for _, cached := range dbs {
+		if cached.db != nil {
+			closeDB(cached.db)
+		}
+	}
+}
+
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// conn 返回ctx对应的数据库，租户有独立数据库时使用租户的数据库
+// 跨租户操作(WithTenantBypass)和 WithShared 使用共享库
+func (d *Data) conn(ctx context.Context) (*gorm.DB, error) {
+	if d.tenants == nil || IsShared(ctx) || gorm_plugin.TenantBypassed(ctx) {
+		return d.db, nil
+	}
+	tenantID := gorm_plugin.TenantFromContext(ctx)
+	if tenantID == 0 {
+		return d.db, nil
+	}
+	db, err := d.tenants.Get(ctx, tenantID)
+	if err != nil || db == nil {
+		return d.db, err
+	}
+	return db, nil
+}
+
+// DedicatedTenant ctx使用租户的独立数据库时返回租户，使用共享库时返回false
+func (d *Data) DedicatedTenant(ctx context.Context) (uint, bool) {
+	db, err := d.conn(ctx)
+	if err != nil || db == d.db {
+		return 0, false
+	}
+	return gorm_plugin.TenantFromContext(ctx), true
+}
+
+// UseTenantDBs 开启租户独立数据库
+func (d *Data) UseTenantDBs(tenants *TenantDBs) {
+	d.tenants = tenants
+}
+
+// Tenants 租户独立数据库，没有开启时返回 ErrTenantDBDisabled
+func (d *Data) Tenants() (*TenantDBs, error) {
+	if d.tenants == nil {
+		return nil, ErrTenantDBDisabled
+	}
+	return d.tenants, nil
+}
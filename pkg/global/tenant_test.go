@@ -0,0 +1,150 @@
+package global
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/gorm_plugin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestData_TenantDBs(t *testing.T) {
+	dir := t.TempDir()
+	open := func(dsn string) (*gorm.DB, error) {
+		return gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	}
+	migrate := func(ctx context.Context, db *gorm.DB) error {
+		return db.AutoMigrate(&article{})
+	}
+
+	shared, err := open(filepath.Join(dir, "shared.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, migrate(context.Background(), shared))
+	assert.NoError(t, shared.Create(&article{Title: "shared"}).Error)
+
+	dsns := map[uint]string{}
+	var lookups int
+	tenants := NewTenantDBs(TenantDBConfig{
+		Lookup: func(ctx context.Context, tenantID uint) (string, error) {
+			lookups++
+			if tenantID == 3 {
+				return "", errors.New("lookup failed")
+			}
+			return dsns[tenantID], nil
+		},
+		Open:    open,
+		Migrate: migrate,
+	})
+	defer tenants.Close()
+	data := NewData(shared)
+	data.UseTenantDBs(tenants)
+
+	dsn := filepath.Join(dir, "tenant1.db")
+	assert.NoError(t, tenants.Provision(context.Background(), dsn))
+	dsns[1] = dsn
+
+	ctx1 := gorm_plugin.WithTenant(context.Background(), 1)
+	title := func(ctx context.Context) string {
+		var res article
+		_ = data.WithContext(ctx).First(&res).Error
+		return res.Title
+	}
+
+	// 租户独立数据库
+	assert.NoError(t, data.WithContext(ctx1).Create(&article{Title: "tenant1"}).Error)
+	assert.Equal(t, "tenant1", title(ctx1))
+	err = data.Tx(ctx1, func(ctx context.Context) error {
+		return data.WithContext(ctx).Create(&article{Title: "tx"}).Error
+	})
+	assert.NoError(t, err)
+	var count int64
+	data.WithContext(ctx1).Model(&article{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, 1, lookups)
+
+	// 共享库
+	assert.Equal(t, "shared", title(context.Background()))
+	assert.Equal(t, "shared", title(gorm_plugin.WithTenant(context.Background(), 2)))
+	assert.Equal(t, "shared", title(WithShared(ctx1)))
+	assert.Equal(t, "shared", title(gorm_plugin.WithTenantBypass(ctx1)))
+
+	// 查询失败时不回退到共享库
+	err = data.WithContext(gorm_plugin.WithTenant(context.Background(), 3)).First(&article{}).Error
+	assert.EqualError(t, err, "lookup failed")
+
+	tenantID, ok := data.DedicatedTenant(ctx1)
+	assert.True(t, ok)
+	assert.Equal(t, uint(1), tenantID)
+	_, ok = data.DedicatedTenant(gorm_plugin.WithTenant(context.Background(), 2))
+	assert.False(t, ok)
+	_, ok = data.DedicatedTenant(WithShared(ctx1))
+	assert.False(t, ok)
+
+	// 没有独立数据库的租户分配后重新查询
+	dsns[2] = dsn
+	tenants.Evict(2)
+	assert.Equal(t, "tenant1", title(gorm_plugin.WithTenant(context.Background(), 2)))
+}
+
+func TestTenantDBs_GetConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var lookups atomic.Int32
+	release := make(chan struct{})
+	tenants := NewTenantDBs(TenantDBConfig{
+		Lookup: func(ctx context.Context, tenantID uint) (string, error) {
+			lookups.Add(1)
+			if tenantID == 1 {
+				<-release
+			}
+			return filepath.Join(dir, fmt.Sprintf("tenant%d.db", tenantID)), nil
+		},
+		Open: func(dsn string) (*gorm.DB, error) {
+			return gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+		},
+	})
+	defer tenants.Close()
+
+	// 同一个租户只查询一次
+	var wg sync.WaitGroup
+	dbs := make([]*gorm.DB, 5)
+	for i := range dbs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dbs[i], _ = tenants.Get(context.Background(), 1)
+		}()
+	}
+
+	// 打开租户1期间不影响其它租户
+	db2, err := tenants.Get(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.NotNil(t, db2)
+
+	close(release)
+	wg.Wait()
+	for _, db := range dbs {
+		assert.Same(t, dbs[0], db)
+	}
+	assert.Equal(t, int32(2), lookups.Load())
+
+	// Evict 后旧连接延迟关闭，正在使用的请求不受影响
+	tenants.closeDelay = 50 * time.Millisecond
+	tenants.Evict(1)
+	sqlDB, err := dbs[0].DB()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlDB.Ping())
+	assert.Eventually(t, func() bool { return sqlDB.Ping() != nil }, time.Second, 10*time.Millisecond)
+
+	db1, err := tenants.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.NotSame(t, dbs[0], db1)
+	assert.Equal(t, int32(3), lookups.Load())
+}
@@ -0,0 +1,123 @@
+package gorm_plugin
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// skipPluginKey 插件内部执行的语句设置该标记，避免触发其它插件(例如记录删除人的更新不需要记录变更历史)
+const skipPluginKey = "gorm_plugin:skip"
+
+const (
+	createdByField = "CreatedBy"
+	updatedByField = "UpdatedBy"
+	deletedByField = "DeletedBy"
+)
+
+// AuditPlugin 根据上下文中的操作人(WithActor)自动填充 CreatedBy、UpdatedBy、DeletedBy
+// 创建时总是使用上下文中的操作人(没有时为0)，修改结构体时不会修改 CreatedBy、DeletedBy，避免客户端伪造操作人
+type AuditPlugin struct{}
+
+// NewAuditPlugin 初始化操作人插件
+func NewAuditPlugin() *AuditPlugin {
+	return &AuditPlugin{}
+}
+
+// Apply 注册回调
+func (ap *AuditPlugin) Apply(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("audit:create", ap.beforeCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:update", ap.beforeUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("audit:delete", ap.beforeDelete)
+}
+
+func (ap *AuditPlugin) beforeCreate(db *gorm.DB) {
+	actor := ActorFromContext(db.Statement.Context)
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+
+	for _, name := range []string{createdByField, updatedByField} {
+		field := db.Statement.Schema.LookUpField(name)
+		if field == nil {
+			continue
+		}
+		// 批量创建时逐个填充，请求中绑定的操作人会被覆盖
+		rv := reflect.Indirect(db.Statement.ReflectValue)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				setActor(db, field, reflect.Indirect(rv.Index(i)), actor)
+			}
+		case reflect.Struct:
+			setActor(db, field, rv, actor)
+		case reflect.Map:
+			db.Statement.SetColumn(name, actor)
+		}
+	}
+}
+
+func (ap *AuditPlugin) beforeUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || skipped(db) {
+		return
+	}
+
+	// 结构体中的创建人、删除人可能是请求中绑定的，不修改；恢复删除等需要修改时使用 map
+	if _, ok := db.Statement.Dest.(map[string]interface{}); !ok {
+		for _, name := range []string{createdByField, deletedByField} {
+			if field := db.Statement.Schema.LookUpField(name); field != nil {
+				db.Statement.Omits = append(db.Statement.Omits, field.DBName)
+			}
+		}
+	}
+
+	actor := ActorFromContext(db.Statement.Context)
+	if actor != 0 && db.Statement.Schema.LookUpField(updatedByField) != nil {
+		db.Statement.SetColumn(updatedByField, actor, true)
+	}
+}
+
+// beforeDelete 软删除前记录删除人
+// 软删除的 UPDATE 语句由 gorm 生成，无法追加字段，所以在同一事务中先按相同条件更新 DeletedBy
+func (ap *AuditPlugin) beforeDelete(db *gorm.DB) {
+	actor := ActorFromContext(db.Statement.Context)
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Unscoped || actor == 0 {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(deletedByField)
+	if field == nil || !softDelete(db.Statement.Schema) {
+		return
+	}
+
+	tx, ok := scopeOf(db)
+	if !ok {
+		return
+	}
+	if err := tx.Set(skipPluginKey, true).Where(db.Statement.Schema.LookUpField("DeletedAt").DBName+" IS NULL").
+		UpdateColumn(field.DBName, actor).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+func setActor(db *gorm.DB, field *schema.Field, rv reflect.Value, actor uint) {
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	_ = field.Set(db.Statement.Context, rv, actor)
+}
+
+func softDelete(s *schema.Schema) bool {
+	field := s.LookUpField("DeletedAt")
+	return field != nil && field.FieldType == reflect.TypeOf(gorm.DeletedAt{})
+}
+
+func skipped(db *gorm.DB) bool {
+	v, ok := db.Get(skipPluginKey)
+	return ok && v == true
+}
@@ -0,0 +1,74 @@
+package gorm_plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type auditArticle struct {
+	ID        uint `gorm:"primarykey"`
+	Title     string
+	CreatedBy uint
+	UpdatedBy uint
+	DeletedBy uint
+	DeletedAt gorm.DeletedAt
+}
+
+func (auditArticle) HistoryMaskFields() []string {
+	return nil
+}
+
+func TestAuditPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&auditArticle{}))
+
+	var records []ChangeRecord
+	assert.NoError(t, NewHistoryPlugin(func(tx *gorm.DB, rs []ChangeRecord) error {
+		records = append(records, rs...)
+		return nil
+	}).Apply(db))
+	assert.NoError(t, NewAuditPlugin().Apply(db))
+
+	// 创建
+	articles := []auditArticle{{Title: "a"}, {Title: "b", CreatedBy: 9}}
+	assert.NoError(t, db.WithContext(WithActor(context.Background(), 1)).Create(&articles).Error)
+	assert.Equal(t, uint(1), articles[0].CreatedBy)
+	assert.Equal(t, uint(1), articles[0].UpdatedBy)
+	// 请求中的创建人被覆盖
+	assert.Equal(t, uint(1), articles[1].CreatedBy)
+
+	// 修改
+	ctx := WithActor(context.Background(), 2)
+	assert.NoError(t, db.WithContext(ctx).Model(&auditArticle{ID: articles[0].ID}).Updates(map[string]interface{}{"title": "c"}).Error)
+
+	// 修改结构体时不修改创建人、删除人
+	assert.NoError(t, db.WithContext(ctx).Model(&auditArticle{ID: articles[1].ID}).Updates(auditArticle{Title: "d", CreatedBy: 9, DeletedBy: 9}).Error)
+	var updated auditArticle
+	assert.NoError(t, db.First(&updated, articles[1].ID).Error)
+	assert.Equal(t, "d", updated.Title)
+	assert.Equal(t, uint(1), updated.CreatedBy)
+	assert.Equal(t, uint(0), updated.DeletedBy)
+	assert.Equal(t, uint(2), updated.UpdatedBy)
+
+	// 删除
+	ctx = WithActor(context.Background(), 3)
+	assert.NoError(t, db.WithContext(ctx).Delete(&auditArticle{}, articles[0].ID).Error)
+
+	var res auditArticle
+	assert.NoError(t, db.Unscoped().First(&res, articles[0].ID).Error)
+	assert.Equal(t, "c", res.Title)
+	assert.Equal(t, uint(1), res.CreatedBy)
+	assert.Equal(t, uint(2), res.UpdatedBy)
+	assert.Equal(t, uint(3), res.DeletedBy)
+	assert.True(t, res.DeletedAt.Valid)
+
+	// 记录删除人的更新不产生变更历史: create*2 + update*2 + delete
+	assert.Len(t, records, 5)
+}
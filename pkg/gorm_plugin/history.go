@@ -0,0 +1,399 @@
+package gorm_plugin
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Madou-Shinni/go-logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	HistoryActionCreate = "create"
+	HistoryActionUpdate = "update"
+	HistoryActionDelete = "delete"
+
+	historyMask          = "******"
+	historyBeforeKey     = "history:before"
+	defaultHistoryMaxRow = 500
+)
+
+// 默认不记录的字段
+var historyIgnoreColumns = map[string]struct{}{
+	"created_at": {},
+	"updated_at": {},
+}
+
+type (
+	actorKey   struct{}
+	traceIDKey struct{}
+)
+
+// WithActor 在上下文中设置操作人
+func WithActor(ctx context.Context, actor uint) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 获取上下文中的操作人
+func ActorFromContext(ctx context.Context) uint {
+	actor, _ := ctx.Value(actorKey{}).(uint)
+	return actor
+}
+
+// WithTraceID 在上下文中设置链路id
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext 获取上下文中的链路id
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// Historical 实现该接口的模型会记录字段级的变更历史
+type Historical interface {
+	// HistoryMaskFields 只记录发生了变更，不记录具体值的列，例如密码
+	HistoryMaskFields() []string
+}
+
+// FieldChange 字段变更
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ChangeRecord 一行数据的一次变更
+type ChangeRecord struct {
+	Table    string
+	RecordID string
+	Action   string
+	Changes  []FieldChange
+	Actor    uint
+	TraceID  string
+}
+
+// HistoryStore 保存变更记录，tx 与业务操作处于同一事务
+type HistoryStore func(tx *gorm.DB, records []ChangeRecord) error
+
+type historyOpt func(p *HistoryPlugin)
+
+// WithHistoryMaxRows 单次批量更新、删除时最多记录的行数，超过则不记录
+func WithHistoryMaxRows(n int) historyOpt {
+	return func(p *HistoryPlugin) {
+		p.maxRows = n
+	}
+}
+
+// HistoryPlugin 变更历史插件
+// 在 create、update、delete 回调中对比变更前后的数据，通过 HistoryStore 保存
+type HistoryPlugin struct {
+	store   HistoryStore
+	maxRows int
+}
+
+// NewHistoryPlugin 初始化变更历史插件
+func NewHistoryPlugin(store HistoryStore, opts ...historyOpt) *HistoryPlugin {
+	p := &HistoryPlugin{store: store, maxRows: defaultHistoryMaxRow}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Apply 注册回调
+func (hp *HistoryPlugin) Apply(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("history:create", hp.afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("history:before_update", hp.snapshot); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("history:update", hp.afterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("history:before_delete", hp.snapshot); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("history:delete", hp.afterDelete)
+}
+
+// historical 判断模型是否需要记录变更历史，只支持单主键
+func historical(db *gorm.DB) (Historical, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil || skipped(db) {
+		return nil, false
+	}
+	model := db.Statement.Model
+	if model == nil {
+		model = db.Statement.Dest
+	}
+	if h, ok := model.(Historical); ok {
+		return h, true
+	}
+	h, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(Historical)
+	return h, ok
+}
+
+// session 与当前语句共用连接(事务)的新会话，跳过钩子避免递归
+func session(db *gorm.DB) *gorm.DB {
+	model := reflect.New(db.Statement.Schema.ModelType).Interface()
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Model(model).Table(db.Statement.Table)
+}
+
+// scopeOf 返回与当前 update、delete 语句条件一致的查询(包括已软删除的数据)，没有任何条件时返回false
+func scopeOf(db *gorm.DB) (*gorm.DB, bool) {
+	tx := session(db).Unscoped()
+	var hasWhere bool
+	if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		exprs := make([]clause.Expression, 0, len(where.Exprs))
+		for _, expr := range where.Exprs {
+			if _, ok := expr.(versionCondition); !ok {
+				exprs = append(exprs, expr)
+			}
+		}
+		if len(exprs) > 0 {
+			tx = tx.Clauses(clause.Where{Exprs: exprs})
+			hasWhere = true
+		}
+	}
+	// 通过 Model(&T{ID: 1}) 更新时主键条件在 gorm:update 中才会添加
+	if db.Statement.Schema.PrioritizedPrimaryField != nil {
+		if ids := primaryKeys(db); len(ids) > 0 {
+			tx = tx.Where(clause.IN{Column: clause.Column{Name: db.Statement.Schema.PrioritizedPrimaryField.DBName}, Values: ids})
+			hasWhere = true
+		}
+	}
+	return tx, hasWhere
+}
+
+// snapshot 记录 update、delete 之前的数据
+func (hp *HistoryPlugin) snapshot(db *gorm.DB) {
+	if _, ok := historical(db); !ok {
+		return
+	}
+
+	tx, ok := scopeOf(db)
+	if !ok {
+		// 没有条件的全表操作不记录
+		return
+	}
+
+	var rows []map[string]interface{}
+	if err := tx.Limit(hp.maxRows + 1).Find(&rows).Error; err != nil {
+		logger.Error("history snapshot", zap.Error(err), zap.String("table", db.Statement.Table))
+		return
+	}
+	if len(rows) > hp.maxRows {
+		logger.Warn("history skipped, too many rows", zap.String("table", db.Statement.Table), zap.Int("max", hp.maxRows))
+		return
+	}
+	db.InstanceSet(historyBeforeKey, rows)
+}
+
+func (hp *HistoryPlugin) afterCreate(db *gorm.DB) {
+	h, ok := historical(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	ids := primaryKeys(db)
+	if len(ids) == 0 {
+		return
+	}
+	after, err := hp.find(db, ids)
+	if err != nil {
+		return
+	}
+
+	records := make([]ChangeRecord, 0, len(after))
+	for _, row := range after {
+		records = append(records, hp.record(db, h, HistoryActionCreate, nil, row))
+	}
+	hp.save(db, records)
+}
+
+func (hp *HistoryPlugin) afterUpdate(db *gorm.DB) {
+	h, ok := historical(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+	before, ok := hp.before(db)
+	if !ok {
+		return
+	}
+
+	pk := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	ids := make([]interface{}, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row[pk])
+	}
+	after, err := hp.find(db, ids)
+	if err != nil {
+		return
+	}
+	afterMap := make(map[string]map[string]interface{}, len(after))
+	for _, row := range after {
+		afterMap[fmt.Sprint(row[pk])] = row
+	}
+
+	records := make([]ChangeRecord, 0, len(before))
+	for _, row := range before {
+		record := hp.record(db, h, HistoryActionUpdate, row, afterMap[fmt.Sprint(row[pk])])
+		// 没有实际变化的行不记录
+		if len(record.Changes) > 0 {
+			records = append(records, record)
+		}
+	}
+	hp.save(db, records)
+}
+
+func (hp *HistoryPlugin) afterDelete(db *gorm.DB) {
+	h, ok := historical(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+	before, ok := hp.before(db)
+	if !ok {
+		return
+	}
+
+	records := make([]ChangeRecord, 0, len(before))
+	for _, row := range before {
+		records = append(records, hp.record(db, h, HistoryActionDelete, row, nil))
+	}
+	hp.save(db, records)
+}
+
+func (hp *HistoryPlugin) before(db *gorm.DB) ([]map[string]interface{}, bool) {
+	v, ok := db.InstanceGet(historyBeforeKey)
+	if !ok {
+		return nil, false
+	}
+	rows, ok := v.([]map[string]interface{})
+	return rows, ok && len(rows) > 0
+}
+
+func (hp *HistoryPlugin) find(db *gorm.DB, ids []interface{}) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	pk := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	err := session(db).Unscoped().Where(clause.IN{Column: clause.Column{Name: pk}, Values: ids}).Find(&rows).Error
+	if err != nil {
+		logger.Error("history find", zap.Error(err), zap.String("table", db.Statement.Table))
+	}
+	return rows, err
+}
+
+// record 对比变更前后的数据
+func (hp *HistoryPlugin) record(db *gorm.DB, h Historical, action string, before, after map[string]interface{}) ChangeRecord {
+	ctx := db.Statement.Context
+	pk := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	masks := make(map[string]struct{})
+	for _, f := range h.HistoryMaskFields() {
+		masks[f] = struct{}{}
+	}
+
+	record := ChangeRecord{
+		Table:   db.Statement.Table,
+		Action:  action,
+		Actor:   ActorFromContext(ctx),
+		TraceID: TraceIDFromContext(ctx),
+	}
+	if before != nil {
+		record.RecordID = fmt.Sprint(before[pk])
+	} else {
+		record.RecordID = fmt.Sprint(after[pk])
+	}
+
+	seen := make(map[string]struct{}, len(before)+len(after))
+	columns := make([]string, 0, len(before)+len(after))
+	for _, row := range []map[string]interface{}{before, after} {
+		for k := range row {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		if _, ok := historyIgnoreColumns[column]; ok {
+			continue
+		}
+		b, a := normalize(before[column]), normalize(after[column])
+		if action == HistoryActionUpdate && reflect.DeepEqual(b, a) {
+			continue
+		}
+		if b == nil && a == nil {
+			continue
+		}
+		if _, ok := masks[column]; ok {
+			b, a = mask(b), mask(a)
+		}
+		record.Changes = append(record.Changes, FieldChange{Field: column, Before: b, After: a})
+	}
+
+	return record
+}
+
+func (hp *HistoryPlugin) save(db *gorm.DB, records []ChangeRecord) {
+	if len(records) == 0 || hp.store == nil {
+		return
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	if err := hp.store(tx, records); err != nil {
+		// 保存失败时回滚业务操作，保证变更历史完整
+		db.AddError(fmt.Errorf("save change history: %w", err))
+	}
+}
+
+// primaryKeys 获取模型中的主键值(单个结构体或切片)
+func primaryKeys(db *gorm.DB) []interface{} {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	if !rv.IsValid() {
+		return nil
+	}
+
+	var ids []interface{}
+	appendID := func(v reflect.Value) {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct || v.Type() != db.Statement.Schema.ModelType {
+			return
+		}
+		if id, zero := field.ValueOf(db.Statement.Context, v); !zero {
+			ids = append(ids, id)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			appendID(rv.Index(i))
+		}
+	case reflect.Struct:
+		appendID(rv)
+	}
+	return ids
+}
+
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	default:
+		return v
+	}
+}
+
+func mask(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return historyMask
+}
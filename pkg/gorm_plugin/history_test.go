@@ -0,0 +1,81 @@
+package gorm_plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type historyAccount struct {
+	ID        uint `gorm:"primarykey"`
+	Name      string
+	Password  string
+	Age       int
+	DeletedAt gorm.DeletedAt
+}
+
+func (historyAccount) HistoryMaskFields() []string {
+	return []string{"password"}
+}
+
+type historyLog struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+func newHistoryDB(t *testing.T) (*gorm.DB, *[]ChangeRecord) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	// 内存数据库每个连接都是独立的库
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&historyAccount{}, &historyLog{}))
+
+	var records []ChangeRecord
+	plugin := NewHistoryPlugin(func(tx *gorm.DB, rs []ChangeRecord) error {
+		records = append(records, rs...)
+		return nil
+	})
+	assert.NoError(t, plugin.Apply(db))
+	return db, &records
+}
+
+func TestHistoryPlugin(t *testing.T) {
+	db, records := newHistoryDB(t)
+	ctx := WithTraceID(WithActor(context.Background(), 7), "trace-1")
+
+	account := historyAccount{Name: "a", Password: "123", Age: 18}
+	assert.NoError(t, db.WithContext(ctx).Create(&account).Error)
+	assert.NoError(t, db.WithContext(ctx).Model(&historyAccount{ID: account.ID}).Updates(map[string]interface{}{"name": "b", "age": 18, "password": "456"}).Error)
+	assert.NoError(t, db.WithContext(ctx).Delete(&historyAccount{}, account.ID).Error)
+
+	assert.Len(t, *records, 3)
+	create, update, del := (*records)[0], (*records)[1], (*records)[2]
+
+	assert.Equal(t, HistoryActionCreate, create.Action)
+	assert.Equal(t, "1", create.RecordID)
+	assert.Equal(t, uint(7), create.Actor)
+	assert.Equal(t, "trace-1", create.TraceID)
+
+	assert.Equal(t, HistoryActionUpdate, update.Action)
+	assert.Equal(t, []FieldChange{
+		{Field: "name", Before: "a", After: "b"},
+		{Field: "password", Before: historyMask, After: historyMask},
+	}, update.Changes)
+
+	assert.Equal(t, HistoryActionDelete, del.Action)
+	assert.Equal(t, "history_accounts", del.Table)
+	assert.Contains(t, del.Changes, FieldChange{Field: "name", Before: "b", After: nil})
+}
+
+func TestHistoryPlugin_NotHistorical(t *testing.T) {
+	db, records := newHistoryDB(t)
+
+	log := historyLog{Name: "a"}
+	assert.NoError(t, db.Create(&log).Error)
+	assert.NoError(t, db.Model(&log).Update("name", "b").Error)
+	assert.Empty(t, *records)
+}
@@ -66,8 +66,8 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 		zap.Duration("elapsed", elapsed),
 	}
 
-	if logID, ok := ctx.Value("log-id").(string); ok {
-		logFields = append(logFields, zap.String("log-id", logID))
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		logFields = append(logFields, zap.String("trace-id", traceID))
 	}
 
 	switch {
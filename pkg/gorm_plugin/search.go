@@ -0,0 +1,113 @@
+package gorm_plugin
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/search"
+	"github.com/Madou-Shinni/go-logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	searchIDsKey         = "search:ids"
+	defaultSearchMaxRows = 1000
+)
+
+// SearchNotify 数据变更后通知同步搜索引擎，ids 为变更数据的主键，一般投递异步任务
+// 语句的默认事务提交后才通知，在外部事务中时需要等外部事务提交后再投递(见 global.AfterCommit)
+type SearchNotify func(ctx context.Context, table string, ids []string) error
+
+// SearchPlugin 搜索引擎同步插件
+// 模型实现 search.Searchable 并且搜索引擎需要同步数据(search.Indexer)时，创建、修改、删除后通过 SearchNotify 通知变更的主键
+// 批量修改、删除超过 maxRows 行时不通知，需要通过 cmd/search 全量同步
+type SearchPlugin struct {
+	notify  SearchNotify
+	maxRows int
+}
+
+// NewSearchPlugin 初始化搜索引擎同步插件
+func NewSearchPlugin(notify SearchNotify) *SearchPlugin {
+	return &SearchPlugin{notify: notify, maxRows: defaultSearchMaxRows}
+}
+
+// Apply 注册回调，通知在提交语句的默认事务之后
+func (sp *SearchPlugin) Apply(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:commit_or_rollback_transaction").Register("search:create", sp.afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("search:before_update", sp.snapshot); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:commit_or_rollback_transaction").Register("search:update", sp.afterChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("search:before_delete", sp.snapshot); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:commit_or_rollback_transaction").Register("search:delete", sp.afterChange)
+}
+
+// searchable 判断模型是否需要同步搜索引擎
+func searchable(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil || skipped(db) || !search.Indexing() {
+		return false
+	}
+	_, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(search.Searchable)
+	return ok
+}
+
+func (sp *SearchPlugin) afterCreate(db *gorm.DB) {
+	if !searchable(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	sp.send(db, primaryKeys(db))
+}
+
+// snapshot 记录 update、delete 影响的主键
+func (sp *SearchPlugin) snapshot(db *gorm.DB) {
+	if !searchable(db) {
+		return
+	}
+	tx, ok := scopeOf(db)
+	if !ok {
+		return
+	}
+
+	var ids []interface{}
+	pk := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	if err := tx.Limit(sp.maxRows+1).Pluck(pk, &ids).Error; err != nil {
+		logger.Error("search snapshot", zap.Error(err), zap.String("table", db.Statement.Table))
+		return
+	}
+	if len(ids) > sp.maxRows {
+		logger.Warn("search sync skipped, too many rows", zap.String("table", db.Statement.Table), zap.Int("max", sp.maxRows))
+		return
+	}
+	db.InstanceSet(searchIDsKey, ids)
+}
+
+func (sp *SearchPlugin) afterChange(db *gorm.DB) {
+	if !searchable(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	v, _ := db.InstanceGet(searchIDsKey)
+	ids, _ := v.([]interface{})
+	sp.send(db, ids)
+}
+
+// send 通知失败不影响业务操作，只记录日志
+func (sp *SearchPlugin) send(db *gorm.DB, ids []interface{}) {
+	if len(ids) == 0 || sp.notify == nil {
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprint(normalize(id)))
+	}
+	if err := sp.notify(db.Statement.Context, db.Statement.Table, keys); err != nil {
+		logger.Error("search notify", zap.Error(err), zap.String("table", db.Statement.Table), zap.Strings("ids", keys))
+	}
+}
@@ -0,0 +1,85 @@
+package gorm_plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/search"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type searchArticle struct {
+	ID        uint `gorm:"primarykey"`
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+func (searchArticle) SearchFields() []string {
+	return []string{"Title"}
+}
+
+type searchLog struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+type searchIndexer struct{}
+
+func (searchIndexer) Match(ctx context.Context, q search.Query) (clause.Expression, error) {
+	return q.IDs(nil), nil
+}
+
+func (searchIndexer) Index(ctx context.Context, index string, docs []search.Document) error {
+	return nil
+}
+
+func (searchIndexer) Delete(ctx context.Context, index string, ids []string) error {
+	return nil
+}
+
+func TestSearchPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&searchArticle{}, &searchLog{}))
+
+	var notified [][]string
+	assert.NoError(t, NewSearchPlugin(func(ctx context.Context, table string, ids []string) error {
+		assert.Equal(t, "search_articles", table)
+		notified = append(notified, ids)
+		return nil
+	}).Apply(db))
+
+	// 搜索引擎不需要同步数据时不通知
+	assert.NoError(t, db.Create(&searchArticle{Title: "a"}).Error)
+	assert.Empty(t, notified)
+
+	search.SetEngine(searchIndexer{})
+	defer search.SetEngine(nil)
+
+	assert.NoError(t, db.Create(&[]searchArticle{{Title: "b"}, {Title: "c"}}).Error)
+	assert.NoError(t, db.Model(&searchArticle{}).Where("title IN ?", []string{"a", "c"}).Update("title", "x").Error)
+	assert.NoError(t, db.Model(&searchArticle{ID: 2}).Update("title", "y").Error)
+	assert.NoError(t, db.Delete(&searchArticle{}, []uint{1, 2}).Error)
+	// 没有影响任何数据
+	assert.NoError(t, db.Delete(&searchArticle{}, 100).Error)
+	assert.NoError(t, db.Create(&searchLog{Name: "a"}).Error)
+
+	assert.Equal(t, [][]string{{"2", "3"}, {"1", "3"}, {"2"}, {"1", "2"}}, notified)
+
+	// 语句失败回滚时不通知
+	notified = nil
+	db.Callback().Create().Before("gorm:commit_or_rollback_transaction").Register("test:fail", func(db *gorm.DB) {
+		_ = db.AddError(errors.New("fail"))
+	})
+	assert.Error(t, db.Create(&searchArticle{Title: "d"}).Error)
+	assert.Empty(t, notified)
+	var count int64
+	db.Model(&searchArticle{}).Where("title = ?", "d").Count(&count)
+	assert.Equal(t, int64(0), count)
+}
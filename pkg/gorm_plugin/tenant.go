@@ -2,111 +2,191 @@ package gorm_plugin
 
 import (
 	"context"
-	"github.com/gin-gonic/gin"
+	"errors"
+	"reflect"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"net/http"
+	"gorm.io/gorm/schema"
 )
 
-// 定义上下文中的租户 ID 键
-type tenantIDKey struct{}
+const tenantField = "TenantID"
 
-// 插件结构
-type TenantPlugin struct{}
+type (
+	tenantKey       struct{}
+	tenantBypassKey struct{}
+)
 
-// 定义模型时，包含租户 ID
-type Model struct {
-	ID       uint   `gorm:"primarykey"`
-	TenantID string `gorm:"index"`
+// WithTenant 在上下文中设置租户
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
 }
 
-// 示例表结构
-type User struct {
-	Model
-	Name string
+// TenantFromContext 获取上下文中的租户，没有则返回0
+func TenantFromContext(ctx context.Context) uint {
+	tenantID, _ := ctx.Value(tenantKey{}).(uint)
+	return tenantID
 }
 
-// 初始化租户插件
-func NewTenantPlugin() *TenantPlugin {
-	return &TenantPlugin{}
+// WithTenantBypass 跨租户操作，只能由超级管理员使用
+func WithTenantBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantBypassKey{}, true)
 }
 
-// 注册钩子以自动添加租户 ID 条件
-func (tp *TenantPlugin) Apply(db *gorm.DB) {
-	// 在查询前设置租户 ID 条件
-	db.Callback().Query().Before("gorm:query").Register("tenant:query", func(db *gorm.DB) {
-		if tenantID, ok := db.Statement.Context.Value(tenantIDKey{}).(string); ok && db.Statement.Schema != nil {
-			tenantField := db.Statement.Schema.LookUpField("TenantID")
-			if tenantField != nil {
-				db.Statement.AddClause(clause.Where{
-					Exprs: []clause.Expression{
-						clause.Eq{
-							Column: clause.Column{Table: db.Statement.Table, Name: "tenant_id"},
-							Value:  tenantID,
-						},
-					},
-				})
-			}
-		}
-	})
-
-	// 在创建前设置租户 ID
-	db.Callback().Create().Before("gorm:create").Register("tenant:create", func(db *gorm.DB) {
-		if tenantID, ok := db.Statement.Context.Value(tenantIDKey{}).(string); ok && db.Statement.Schema != nil {
-			tenantField := db.Statement.Schema.LookUpField("TenantID")
-			if tenantField != nil {
-				db.Statement.SetColumn("TenantID", tenantID)
-			}
+// TenantBypassed 上下文是否允许跨租户操作
+func TenantBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(tenantBypassKey{}).(bool)
+	return bypass
+}
+
+// ErrTenantRequired 开启 WithTenantRequired 后，操作租户数据的上下文中没有租户也没有 WithTenantBypass
+var ErrTenantRequired = errors.New("缺少租户")
+
+// TenantPlugin 租户隔离
+// 模型中有 TenantID 字段时，查询、修改、删除自动添加上下文中租户的条件，创建时自动填充租户
+// 设置了 WithTenantBypass 时不做处理；上下文中没有租户时默认不做处理，开启 WithTenantRequired 后返回 ErrTenantRequired，
+// 登录、异步任务等系统操作需要显式使用 WithTenantBypass
+type TenantPlugin struct {
+	required bool
+}
+
+type tenantOpt func(p *TenantPlugin)
+
+// WithTenantRequired 上下文中没有租户时拒绝操作租户数据，开启多租户时使用
+func WithTenantRequired() tenantOpt {
+	return func(p *TenantPlugin) {
+		p.required = true
+	}
+}
+
+// NewTenantPlugin 初始化租户插件
+func NewTenantPlugin(opts ...tenantOpt) *TenantPlugin {
+	tp := &TenantPlugin{}
+	for _, opt := range opts {
+		opt(tp)
+	}
+	return tp
+}
+
+// Apply 注册回调
+func (tp *TenantPlugin) Apply(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", tp.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", tp.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", tp.beforeCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", tp.beforeWrite); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", tp.beforeWrite)
+}
+
+// field 需要隔离时返回租户字段和上下文中的租户
+func (tp *TenantPlugin) field(db *gorm.DB) (*schema.Field, uint) {
+	ctx := db.Statement.Context
+	if db.Error != nil || db.Statement.Schema == nil || TenantBypassed(ctx) {
+		return nil, 0
+	}
+	field := db.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return nil, 0
+	}
+	tenantID := TenantFromContext(ctx)
+	if tenantID == 0 {
+		if tp.required {
+			_ = db.AddError(ErrTenantRequired)
 		}
-	})
-
-	// 在修改前设置租户 ID
-	db.Callback().Update().Before("gorm:update").Register("tenant:update", func(db *gorm.DB) {
-		if tenantID, ok := db.Statement.Context.Value(tenantIDKey{}).(string); ok && db.Statement.Schema != nil {
-			tenantField := db.Statement.Schema.LookUpField("TenantID")
-			if tenantField != nil {
-				db.Statement.AddClause(clause.Where{
-					Exprs: []clause.Expression{
-						clause.Eq{
-							Column: clause.Column{Table: db.Statement.Table, Name: "tenant_id"},
-							Value:  tenantID,
-						},
-					},
-				})
+		return nil, 0
+	}
+	return field, tenantID
+}
+
+// WithTaskTenant 异步任务使用发起任务的租户，租户为0时(平台用户、跨租户操作发起的任务)跨租户执行
+func WithTaskTenant(ctx context.Context, tenantID uint) context.Context {
+	if tenantID == 0 {
+		return WithTenantBypass(ctx)
+	}
+	return WithTenant(ctx, tenantID)
+}
+
+func (tp *TenantPlugin) scope(db *gorm.DB) {
+	field, tenantID := tp.field(db)
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// beforeCreate 填充租户，请求中传入的租户会被覆盖
+func (tp *TenantPlugin) beforeCreate(db *gorm.DB) {
+	field, tenantID := tp.field(db)
+	if field == nil {
+		return
+	}
+
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if elem := reflect.Indirect(rv.Index(i)); elem.Kind() == reflect.Struct {
+				_ = field.Set(db.Statement.Context, elem, tenantID)
 			}
 		}
-	})
-
-	// 在删除前设置租户 ID
-	db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", func(db *gorm.DB) {
-		if tenantID, ok := db.Statement.Context.Value(tenantIDKey{}).(string); ok && db.Statement.Schema != nil {
-			tenantField := db.Statement.Schema.LookUpField("TenantID")
-			if tenantField != nil {
-				db.Statement.AddClause(clause.Where{
-					Exprs: []clause.Expression{
-						clause.Eq{
-							Column: clause.Column{Table: db.Statement.Table, Name: "tenant_id"},
-							Value:  tenantID,
-						},
-					},
-				})
+	case reflect.Struct:
+		_ = field.Set(db.Statement.Context, rv, tenantID)
+	case reflect.Map:
+		db.Statement.SetColumn(field.Name, tenantID)
+	}
+}
+
+// beforeWrite 修改、删除只作用于当前租户
+// 添加租户条件后 gorm 不会再检查是否缺少条件，所以这里先检查，避免误修改整个租户的数据
+func (tp *TenantPlugin) beforeWrite(db *gorm.DB) {
+	field, _ := tp.field(db)
+	if field == nil {
+		return
+	}
+	if !db.AllowGlobalUpdate && !hasCondition(db) {
+		_ = db.AddError(gorm.ErrMissingWhereClause)
+		return
+	}
+	tp.scope(db)
+}
+
+// hasCondition 语句是否有条件，没有 WHERE 时 gorm 会使用模型的主键作为条件
+func hasCondition(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["WHERE"]; ok {
+		return true
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(db.Statement.Model))
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if hasPrimaryKey(db, reflect.Indirect(rv.Index(i))) {
+				return true
 			}
 		}
-	})
+	case reflect.Struct:
+		return hasPrimaryKey(db, rv)
+	}
+	return false
 }
 
-// 中间件，提取租户 ID 并存储在 Gin 的上下文中
-func TenantMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		//tenantID := c.GetHeader("X-Tenant-ID")
-		tenantID := "1"
-		if tenantID == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "X-Tenant-ID header is required"})
-			return
+func hasPrimaryKey(db *gorm.DB, rv reflect.Value) bool {
+	if rv.Kind() != reflect.Struct {
+		return false
+	}
+	for _, field := range db.Statement.Schema.PrimaryFields {
+		if _, zero := field.ValueOf(db.Statement.Context, rv); !zero {
+			return true
 		}
-
-		ctx := context.WithValue(c.Request.Context(), tenantIDKey{}, tenantID)
-		c.Request = c.Request.WithContext(ctx)
-		c.Next()
 	}
+	return false
 }
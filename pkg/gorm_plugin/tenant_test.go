@@ -1,64 +1,95 @@
 package gorm_plugin
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"testing"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
 	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
-	"net/http"
-	"testing"
 )
 
-func TestTenantPlugin_Apply(t *testing.T) {
-	// 连接数据库
-	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect database")
-	}
+type tenantArticle struct {
+	ID    uint `gorm:"primarykey"`
+	Title string
+	model.TenantModel
+	DeletedAt gorm.DeletedAt
+}
 
-	// 迁移数据库
-	db.AutoMigrate(&User{})
+func TestTenantPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&tenantArticle{}))
+	assert.NoError(t, NewTenantPlugin().Apply(db))
 
-	// 应用租户插件
-	tenantPlugin := NewTenantPlugin()
-	tenantPlugin.Apply(db)
+	ctx1 := WithTenant(context.Background(), 1)
+	ctx2 := WithTenant(context.Background(), 2)
 
-	// 设置 Gin
-	r := gin.Default()
+	// 创建时填充租户，传入的租户被覆盖
+	a := tenantArticle{Title: "a", TenantModel: model.TenantModel{TenantID: 2}}
+	assert.NoError(t, db.WithContext(ctx1).Create(&a).Error)
+	assert.Equal(t, uint(1), a.TenantID)
+	batch := []tenantArticle{{Title: "b"}, {Title: "c"}}
+	assert.NoError(t, db.WithContext(ctx2).Create(&batch).Error)
+	assert.Equal(t, uint(2), batch[1].TenantID)
 
-	// 使用租户中间件
-	r.Use(TenantMiddleware())
+	// 查询
+	var count int64
+	assert.NoError(t, db.WithContext(ctx1).Model(&tenantArticle{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	var list []tenantArticle
+	assert.NoError(t, db.WithContext(ctx2).Find(&list).Error)
+	assert.Len(t, list, 2)
+	err = db.WithContext(ctx2).First(&tenantArticle{}, a.ID).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
 
-	// 路由处理
-	r.POST("/users", func(c *gin.Context) {
-		var user User
-		if err := c.ShouldBindJSON(&user); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	// 修改、删除其他租户的数据不生效
+	res := db.WithContext(ctx2).Model(&tenantArticle{ID: a.ID}).Update("title", "x")
+	assert.NoError(t, res.Error)
+	assert.Equal(t, int64(0), res.RowsAffected)
+	res = db.WithContext(ctx2).Delete(&tenantArticle{ID: a.ID})
+	assert.Equal(t, int64(0), res.RowsAffected)
 
-		// 使用包含租户 ID 的上下文
-		ctx := c.Request.Context()
-		if err := db.Debug().WithContext(ctx).Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	// 没有条件时不会修改整个租户的数据
+	err = db.WithContext(ctx2).Model(&tenantArticle{}).Update("title", "x").Error
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
 
-		c.JSON(http.StatusOK, user)
-	})
+	// 跨租户
+	assert.NoError(t, db.WithContext(WithTenantBypass(ctx1)).Model(&tenantArticle{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+	// 没有租户的上下文不隔离
+	assert.NoError(t, db.Model(&tenantArticle{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
 
-	r.GET("/users", func(c *gin.Context) {
-		var users []User
+func TestTenantPlugin_Required(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&tenantArticle{}, &searchLog{}))
+	assert.NoError(t, NewTenantPlugin(WithTenantRequired()).Apply(db))
+	ctx := context.Background()
 
-		// 使用包含租户 ID 的上下文
-		ctx := c.Request.Context()
-		if err := db.Debug().WithContext(ctx).Find(&users).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	// 没有租户的上下文不能操作租户数据
+	var count int64
+	assert.ErrorIs(t, db.WithContext(ctx).Model(&tenantArticle{}).Count(&count).Error, ErrTenantRequired)
+	assert.ErrorIs(t, db.WithContext(ctx).Find(&[]tenantArticle{}).Error, ErrTenantRequired)
+	assert.ErrorIs(t, db.WithContext(ctx).Create(&tenantArticle{Title: "a"}).Error, ErrTenantRequired)
+	assert.ErrorIs(t, db.WithContext(ctx).Delete(&tenantArticle{ID: 1}).Error, ErrTenantRequired)
 
-		c.JSON(http.StatusOK, users)
-	})
+	// 租户、跨租户、异步任务
+	assert.NoError(t, db.WithContext(WithTenant(ctx, 1)).Create(&tenantArticle{Title: "a"}).Error)
+	assert.NoError(t, db.WithContext(WithTenantBypass(ctx)).Model(&tenantArticle{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, db.WithContext(WithTaskTenant(ctx, 0)).Model(&tenantArticle{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, db.WithContext(WithTaskTenant(ctx, 2)).Model(&tenantArticle{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
 
-	// 监听并在 0.0.0.0:8080 上启动服务
-	r.Run(":8080")
+	// 没有租户字段的模型不受影响
+	assert.NoError(t, db.WithContext(ctx).Create(&searchLog{Name: "a"}).Error)
 }
@@ -0,0 +1,132 @@
+package gorm_plugin
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	versionField      = "Version"
+	versionCheckedKey = "version:checked"
+)
+
+// VersionPlugin 乐观锁
+// 模型中包含 Version 字段时，修改的数据中携带版本号(map的version或结构体的Version)则按版本号更新
+// 版本号不一致时返回 model.VersionConflictError
+type VersionPlugin struct{}
+
+// NewVersionPlugin 初始化乐观锁插件
+func NewVersionPlugin() *VersionPlugin {
+	return &VersionPlugin{}
+}
+
+// Apply 注册回调
+func (vp *VersionPlugin) Apply(db *gorm.DB) error {
+	if err := db.Callback().Update().Before("gorm:update").Register("version:before_update", vp.beforeUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register("version:after_update", vp.afterUpdate)
+}
+
+func (vp *VersionPlugin) beforeUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || skipped(db) {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(versionField)
+	if field == nil {
+		return
+	}
+
+	var expected uint
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		v, ok := dest[field.DBName]
+		if !ok {
+			return
+		}
+		expected = toVersion(v)
+		delete(dest, field.DBName)
+		if expected == 0 {
+			return
+		}
+		dest[field.DBName] = gorm.Expr(field.DBName + " + 1")
+	default:
+		// Model(&T{ID: 1}).Updates(&T{...}) 时版本号在 Dest 中
+		rv := reflect.Indirect(reflect.ValueOf(dest))
+		if rv.Kind() != reflect.Struct || rv.Type() != db.Statement.Schema.ModelType || !rv.CanAddr() {
+			return
+		}
+		v, zero := field.ValueOf(db.Statement.Context, rv)
+		if zero {
+			// 没有携带版本号时不修改版本号，避免 Select("*") 把版本号改为0
+			db.Statement.Omits = append(db.Statement.Omits, field.DBName)
+			return
+		}
+		expected = toVersion(v)
+		if err := field.Set(db.Statement.Context, rv, expected+1); err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		versionCondition{clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: expected}},
+	}})
+	db.InstanceSet(versionCheckedKey, true)
+}
+
+// afterUpdate 没有更新到数据时查询当前版本号，数据存在则说明版本冲突
+func (vp *VersionPlugin) afterUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected > 0 {
+		return
+	}
+	if _, ok := db.InstanceGet(versionCheckedKey); !ok {
+		return
+	}
+
+	// scopeOf 会忽略版本号条件
+	tx, ok := scopeOf(db)
+	if !ok {
+		return
+	}
+	if deletedAt := db.Statement.Schema.LookUpField("DeletedAt"); deletedAt != nil {
+		tx = tx.Where(clause.Eq{Column: clause.Column{Name: deletedAt.DBName}, Value: nil})
+	}
+	var current []uint
+	if err := tx.Limit(1).Pluck(db.Statement.Schema.LookUpField(versionField).DBName, &current).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	if len(current) > 0 {
+		db.AddError(&model.VersionConflictError{Current: current[0]})
+	}
+}
+
+// versionCondition 版本号条件，查询数据范围时需要排除
+type versionCondition struct {
+	clause.Eq
+}
+
+func toVersion(v interface{}) uint {
+	switch n := v.(type) {
+	case uint:
+		return n
+	case int:
+		if n > 0 {
+			return uint(n)
+		}
+	case int64:
+		if n > 0 {
+			return uint(n)
+		}
+	case float64:
+		if n > 0 && n <= math.MaxUint32 && n == math.Trunc(n) {
+			return uint(n)
+		}
+	}
+	return 0
+}
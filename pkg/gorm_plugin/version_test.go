@@ -0,0 +1,59 @@
+package gorm_plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/model"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type versionArticle struct {
+	ID    uint `gorm:"primarykey"`
+	Title string
+	model.Version
+	DeletedAt gorm.DeletedAt
+}
+
+func TestVersionPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&versionArticle{}))
+	assert.NoError(t, NewVersionPlugin().Apply(db))
+
+	article := versionArticle{Title: "a"}
+	assert.NoError(t, db.Create(&article).Error)
+	assert.Equal(t, uint(1), article.Version.Version)
+
+	// map 携带版本号
+	assert.NoError(t, db.Model(&versionArticle{ID: article.ID}).Updates(map[string]interface{}{"title": "b", "version": 1}).Error)
+
+	// 版本号过期
+	err = db.Model(&versionArticle{ID: article.ID}).Updates(map[string]interface{}{"title": "c", "version": 1}).Error
+	var conflict *model.VersionConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.True(t, errors.Is(err, model.ErrVersionConflict))
+	assert.Equal(t, uint(2), conflict.Current)
+
+	// 结构体携带版本号
+	update := versionArticle{ID: article.ID, Title: "d", Version: model.Version{Version: 2}}
+	assert.NoError(t, db.Model(&update).Updates(&update).Error)
+	assert.Equal(t, uint(3), update.Version.Version)
+	err = db.Model(&versionArticle{ID: article.ID}).Updates(&versionArticle{Title: "e", Version: model.Version{Version: 2}}).Error
+	assert.True(t, errors.Is(err, model.ErrVersionConflict))
+
+	// 不携带版本号时不校验，版本号不变
+	assert.NoError(t, db.Model(&versionArticle{ID: article.ID}).Updates(map[string]interface{}{"title": "f"}).Error)
+
+	var res versionArticle
+	assert.NoError(t, db.First(&res, article.ID).Error)
+	assert.Equal(t, "f", res.Title)
+	assert.Equal(t, uint(3), res.Version.Version)
+
+	// 数据不存在不返回冲突
+	assert.NoError(t, db.Model(&versionArticle{ID: 100}).Updates(map[string]interface{}{"title": "g", "version": 1}).Error)
+}
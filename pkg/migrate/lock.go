@@ -0,0 +1,117 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	lockID       = 1
+	lockInterval = time.Second
+)
+
+var ErrLockTimeout = errors.New("migrate: wait lock timeout")
+
+// lock 迁移锁，锁表中只有一行数据，插入成功即获得锁
+// 使用普通表实现，不依赖 mysql 的 GET_LOCK，各数据库通用
+type lock struct {
+	ID       uint      `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string    `gorm:"size:255"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+func (m *Migrator) lockTable() string {
+	return m.table + "_lock"
+}
+
+// withLock 获得锁后执行 fn，其他实例持有锁时等待
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if err := m.prepare(db); err != nil {
+		return err
+	}
+	if err := m.acquire(ctx, db); err != nil {
+		return err
+	}
+	// 使用不带超时的连接续期和释放锁，避免 ctx 取消后锁无法释放
+	stop := m.heartbeat(m.db)
+	defer m.release(m.db, stop)
+
+	return fn(db)
+}
+
+func (m *Migrator) acquire(ctx context.Context, db *gorm.DB) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		res := db.Table(m.lockTable()).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&lock{ID: lockID, Owner: m.owner, LockedAt: time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			return nil
+		}
+
+		// 没有插入说明锁被占用，持有者崩溃导致锁过期时清除
+		var current lock
+		if err := db.Table(m.lockTable()).Where("id = ?", lockID).Take(&current).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			continue
+		}
+		if time.Since(current.LockedAt) > m.lockExpire {
+			db.Table(m.lockTable()).Where("id = ? AND locked_at = ?", lockID, current.LockedAt).Delete(&lock{})
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w, held by %s since %s", ErrLockTimeout, current.Owner, current.LockedAt.Format(time.DateTime))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockInterval):
+		}
+	}
+}
+
+// heartbeat 持有锁期间每 lockExpire/3 更新一次 locked_at，执行时间超过 lockExpire 的迁移不会被其他实例当作过期锁清除
+// 返回的 stop 停止续期，停止后才返回
+func (m *Migrator) heartbeat(db *gorm.DB) (stop func()) {
+	interval := m.lockExpire / 3
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				db.Table(m.lockTable()).Where("id = ? AND owner = ?", lockID, m.owner).Update("locked_at", time.Now())
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// release 停止续期后释放锁
+func (m *Migrator) release(db *gorm.DB, stop func()) {
+	stop()
+	db.Table(m.lockTable()).Where("id = ? AND owner = ?", lockID, m.owner).Delete(&lock{})
+}
@@ -0,0 +1,252 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTable       = "schema_migrations"
+	defaultLockTimeout = time.Minute * 5
+	defaultLockExpire  = time.Minute * 30
+)
+
+var ErrNoChange = errors.New("no change")
+
+// Migration 一次数据库迁移
+// 版本号按字符串排序，create 命令生成的版本号为14位时间戳
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Status 迁移状态
+type Status struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"` // 为空则未执行
+	Missing   bool       `json:"missing"`    // 已执行但是本地没有对应的迁移
+}
+
+// history 迁移记录
+type history struct {
+	Version   string    `gorm:"primaryKey;size:32"`
+	Name      string    `gorm:"size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+type Option func(m *Migrator)
+
+// WithTable 迁移记录表名，锁表名为 {table}_lock
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithLockTimeout 等待其他实例释放锁的最长时间
+func WithLockTimeout(d time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = d
+	}
+}
+
+// WithLockExpire 锁的过期时间，超过该时间的锁视为持有者已崩溃
+func WithLockExpire(d time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockExpire = d
+	}
+}
+
+// Migrator 版本化迁移
+// 执行记录保存在迁移记录表中，通过锁表保证多实例同时启动时只有一个实例执行迁移
+type Migrator struct {
+	db          *gorm.DB
+	migrations  map[string]*Migration
+	table       string
+	lockTimeout time.Duration
+	lockExpire  time.Duration
+	owner       string
+}
+
+// New 初始化迁移
+func New(db *gorm.DB, opts ...Option) *Migrator {
+	hostname, _ := os.Hostname()
+	m := &Migrator{
+		db:          db.Session(&gorm.Session{SkipHooks: true}),
+		migrations:  make(map[string]*Migration),
+		table:       defaultTable,
+		lockTimeout: defaultLockTimeout,
+		lockExpire:  defaultLockExpire,
+		owner:       fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Register 注册迁移，版本号不能重复
+func (m *Migrator) Register(migrations ...*Migration) error {
+	for _, migration := range migrations {
+		if migration.Version == "" {
+			return fmt.Errorf("migration %q: missing version", migration.Name)
+		}
+		if _, ok := m.migrations[migration.Version]; ok {
+			return fmt.Errorf("migration %s: duplicate version", migration.Version)
+		}
+		m.migrations[migration.Version] = migration
+	}
+	return nil
+}
+
+// Up 执行未执行的迁移，n<=0 时执行全部
+func (m *Migrator) Up(ctx context.Context, n int) ([]string, error) {
+	var done []string
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.sorted() {
+			if n > 0 && len(done) >= n {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err = m.run(db, migration, true); err != nil {
+				return err
+			}
+			done = append(done, migration.Version)
+		}
+		return nil
+	})
+	if err == nil && len(done) == 0 {
+		err = ErrNoChange
+	}
+	return done, err
+}
+
+// Down 回滚最近执行的迁移，n<=0 时回滚1个
+func (m *Migrator) Down(ctx context.Context, n int) ([]string, error) {
+	if n <= 0 {
+		n = 1
+	}
+	var done []string
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		var histories []history
+		if err := db.Table(m.table).Order("version DESC").Limit(n).Find(&histories).Error; err != nil {
+			return err
+		}
+		for _, h := range histories {
+			migration, ok := m.migrations[h.Version]
+			if !ok {
+				return fmt.Errorf("migration %s_%s: not found", h.Version, h.Name)
+			}
+			if err := m.run(db, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration.Version)
+		}
+		return nil
+	})
+	if err == nil && len(done) == 0 {
+		err = ErrNoChange
+	}
+	return done, err
+}
+
+// Status 所有迁移的执行状态，按版本号排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.db.WithContext(ctx)
+	if err := m.prepare(db); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.sorted() {
+		s := Status{Version: migration.Version, Name: migration.Name}
+		if h, ok := applied[migration.Version]; ok {
+			s.AppliedAt = &h.AppliedAt
+		}
+		res = append(res, s)
+	}
+	for _, h := range applied {
+		if _, ok := m.migrations[h.Version]; !ok {
+			appliedAt := h.AppliedAt
+			res = append(res, Status{Version: h.Version, Name: h.Name, AppliedAt: &appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+	return res, nil
+}
+
+// run 在事务中执行迁移并修改迁移记录
+// 注意：mysql 的 DDL 会隐式提交事务，迁移失败时需要手动处理已执行的语句
+func (m *Migrator) run(db *gorm.DB, migration *Migration, up bool) error {
+	fn := migration.Down
+	if up {
+		fn = migration.Up
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if fn != nil {
+			if err := fn(tx); err != nil {
+				return fmt.Errorf("migration %s_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+		if !up {
+			return tx.Table(m.table).Where("version = ?", migration.Version).Delete(&history{}).Error
+		}
+		return tx.Table(m.table).Create(&history{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+}
+
+// prepare 创建迁移记录表和锁表
+func (m *Migrator) prepare(db *gorm.DB) error {
+	if err := db.Table(m.table).AutoMigrate(&history{}); err != nil {
+		return err
+	}
+	return db.Table(m.lockTable()).AutoMigrate(&lock{})
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[string]history, error) {
+	var histories []history
+	if err := db.Table(m.table).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[string]history, len(histories))
+	for _, h := range histories {
+		res[h.Version] = h
+	}
+	return res, nil
+}
+
+func (m *Migrator) sorted() []*Migration {
+	res := make([]*Migration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		res = append(res, migration)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+	return res
+}
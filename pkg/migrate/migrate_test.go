@@ -0,0 +1,155 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+
+	type article struct {
+		ID    uint
+		Title string
+	}
+	m := New(db)
+	assert.NoError(t, m.Register(&Migration{
+		Version: "20240101000000",
+		Name:    "init",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&article{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&article{})
+		},
+	}))
+	assert.NoError(t, m.LoadFS(fstest.MapFS{
+		"20240102000000_add_author.up.sql":   {Data: []byte("-- 作者\nALTER TABLE articles ADD COLUMN author TEXT;\nINSERT INTO articles (title, author) VALUES ('a;b', 'c');")},
+		"20240102000000_add_author.down.sql": {Data: []byte("DELETE FROM articles;\nALTER TABLE articles DROP COLUMN author;")},
+		"README.md":                          {Data: []byte("ignored")},
+	}))
+	assert.Error(t, m.Register(&Migration{Version: "20240101000000"}))
+
+	applied, err := m.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20240101000000", "20240102000000"}, applied)
+	assert.True(t, db.Migrator().HasColumn(&article{}, "author"))
+
+	_, err = m.Up(ctx, 0)
+	assert.ErrorIs(t, err, ErrNoChange)
+
+	status, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, status, 2)
+	assert.NotNil(t, status[1].AppliedAt)
+
+	rolled, err := m.Down(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20240102000000"}, rolled)
+	assert.False(t, db.Migrator().HasColumn(&article{}, "author"))
+
+	status, err = m.Status(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, status[0].AppliedAt)
+	assert.Nil(t, status[1].AppliedAt)
+
+	// 迁移失败时不记录
+	assert.NoError(t, m.Register(&Migration{
+		Version: "20240103000000",
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			return errors.New("broken")
+		},
+	}))
+	applied, err = m.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"20240102000000"}, applied)
+	status, _ = m.Status(ctx)
+	assert.Nil(t, status[2].AppliedAt)
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+
+	m := New(db, WithLockTimeout(time.Millisecond), WithLockExpire(time.Hour))
+	assert.NoError(t, m.prepare(db))
+	assert.NoError(t, db.Table(m.lockTable()).Create(&lock{ID: lockID, Owner: "other", LockedAt: time.Now()}).Error)
+
+	_, err := m.Up(ctx, 0)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+
+	// 锁过期后可以获得锁
+	m = New(db, WithLockExpire(0))
+	_, err = m.Up(ctx, 0)
+	assert.ErrorIs(t, err, ErrNoChange)
+
+	var count int64
+	db.Table(m.lockTable()).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestMigrator_LockHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+
+	m := New(db, WithLockExpire(90*time.Millisecond))
+	other := New(db, WithLockTimeout(time.Millisecond), WithLockExpire(90*time.Millisecond))
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		// 执行时间超过过期时间，持有期间定时续期，其他实例不能清除锁
+		time.Sleep(200 * time.Millisecond)
+		return other.acquire(ctx, db)
+	})
+	assert.ErrorIs(t, err, ErrLockTimeout)
+
+	var count int64
+	db.Table(m.lockTable()).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := SplitStatements(`
+-- 注释;
+CREATE TABLE a (id INT, name VARCHAR(10) DEFAULT 'x;y'); /* 多行;
+注释 */
+INSERT INTO a VALUES (1, 'it\'s;');
+`)
+	assert.Equal(t, []string{
+		"CREATE TABLE a (id INT, name VARCHAR(10) DEFAULT 'x;y')",
+		"INSERT INTO a VALUES (1, 'it\\'s;')",
+	}, statements)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Create(dir, "Add User-Email")
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Regexp(t, `^\d{14}_add_user_email\.up\.sql$`, filepath.Base(files[0]))
+	assert.Regexp(t, `^\d{14}_add_user_email\.down\.sql$`, filepath.Base(files[1]))
+
+	m := New(newDB(t))
+	assert.NoError(t, m.LoadFS(os.DirFS(dir)))
+	assert.Len(t, m.migrations, 1)
+
+	_, err = Create(dir, "--")
+	assert.Error(t, err)
+}
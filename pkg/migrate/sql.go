@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const versionLayout = "20060102150405"
+
+// sql迁移文件名 {version}_{name}.up.sql、{version}_{name}.down.sql
+var (
+	fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	nameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// LoadFS 加载目录下的sql迁移文件
+// 同一版本的 up、down 文件组成一次迁移，与 Register 注册的go迁移版本号不能重复
+func (m *Migrator) LoadFS(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return err
+		}
+
+		version, name, direction := match[1], match[2], match[3]
+		migration, ok := loaded[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			loaded[version] = migration
+		}
+		if migration.Name != name {
+			return fmt.Errorf("migration %s: different names %s and %s", version, migration.Name, name)
+		}
+		fn := execSQL(entry.Name(), string(content))
+		if direction == "up" {
+			migration.Up = fn
+		} else {
+			migration.Down = fn
+		}
+	}
+
+	for _, migration := range loaded {
+		if err = m.Register(migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create 在 dir 目录下创建新的 up、down 迁移文件，返回创建的文件路径
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(nameRegexp.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migrate: invalid name")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	version := time.Now().Format(versionLayout)
+	files := make([]string, 0, 2)
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		content := fmt.Sprintf("-- %s %s\n", name, direction)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// execSQL 逐条执行sql文件中的语句
+func execSQL(file, content string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range SplitStatements(content) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("%s: %w", path.Base(file), err)
+			}
+		}
+		return nil
+	}
+}
+
+// SplitStatements 按分号拆分sql语句，忽略引号中的分号和注释
+func SplitStatements(content string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// 单行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// 多行注释
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case r == ';':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return statements
+}
@@ -17,12 +17,21 @@ type LocalDate struct {
 }
 
 type Model struct {
-	ID        uint           `gorm:"primarykey" json:"id" form:"id" uri:"id"`         // 主键
-	CreatedAt *LocalTime     `json:"createdAt" form:"createdAt" swaggerignore:"true"` // 创建时间
-	UpdatedAt *LocalTime     `json:"updatedAt" form:"updatedAt" swaggerignore:"true"` // 修改时间
+	ID        uint           `gorm:"primarykey" json:"id" form:"id" uri:"id" excel:"ID"`           // 主键
+	CreatedAt *LocalTime     `json:"createdAt" form:"createdAt" swaggerignore:"true" excel:"创建时间"` // 创建时间
+	UpdatedAt *LocalTime     `json:"updatedAt" form:"updatedAt" swaggerignore:"true"`              // 修改时间
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deletedAt" form:"deletedAt" swaggerignore:"true"`
 }
 
+// AuditModel 带操作人的基础模型
+// CreatedBy、UpdatedBy、DeletedBy 由 gorm_plugin.AuditPlugin 根据上下文中的用户id自动填充，只读，不从请求中绑定
+type AuditModel struct {
+	Model
+	CreatedBy uint `gorm:"index;default:0;not null" json:"createdBy" form:"-" binding:"-" swaggerignore:"true"` // 创建人
+	UpdatedBy uint `gorm:"default:0;not null" json:"updatedBy" form:"-" binding:"-" swaggerignore:"true"`       // 修改人
+	DeletedBy uint `gorm:"default:0;not null" json:"deletedBy" form:"-" binding:"-" swaggerignore:"true"`       // 删除人
+}
+
 func (t *LocalTime) UnmarshalParam(param string) error {
 	if string(param) == "" {
 		return nil
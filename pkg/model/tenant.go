@@ -0,0 +1,7 @@
+package model
+
+// TenantModel 多租户数据
+// 嵌入后 gorm_plugin.TenantPlugin 会根据上下文中的租户自动隔离数据，创建时自动填充 TenantID
+type TenantModel struct {
+	TenantID uint `gorm:"index;default:0;not null" json:"tenantId" form:"tenantId" swaggerignore:"true"` // 租户
+}
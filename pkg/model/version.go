@@ -0,0 +1,31 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrVersionConflict = errors.New("数据已被修改，请刷新后重试")
+	ErrMissingVersion  = errors.New("缺少版本号 version")
+)
+
+// Version 乐观锁版本号
+// 嵌入后修改时携带 version 会校验版本号，版本号不一致返回 VersionConflictError，修改成功后版本号+1
+// 修改接口必须携带 version，没有携带时返回 ErrMissingVersion(基于map的修改通过更新策略的 policy.Required 校验)
+type Version struct {
+	Version uint `gorm:"default:1;not null" json:"version" form:"version"` // 版本号
+}
+
+// VersionConflictError 版本冲突
+type VersionConflictError struct {
+	Current uint // 数据当前的版本号
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s(当前版本 %d)", ErrVersionConflict.Error(), e.Current)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
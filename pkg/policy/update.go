@@ -0,0 +1,217 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMissingID       = errors.New("missing id")
+	ErrInvalidID       = errors.New("invalid id")
+	ErrFieldNotAllowed = errors.New("field not allowed")
+	ErrMissingField    = errors.New("missing required field")
+	ErrInvalidValue    = errors.New("invalid value")
+	ErrEmptyUpdate     = errors.New("nothing to update")
+)
+
+var validate = validator.New()
+
+// TransformFunc 字段值转换函数，例如对密码进行hash
+type TransformFunc func(v interface{}) (interface{}, error)
+
+type field struct {
+	column    string        // 数据库列名
+	required  bool          // 每次修改都必须携带，例如乐观锁的 version
+	validate  string        // validator校验规则
+	transform TransformFunc // 转换函数
+}
+
+type FieldOpt func(f *field)
+
+// Column 指定字段对应的数据库列名，默认与请求中的key一致
+func Column(column string) FieldOpt {
+	return func(f *field) {
+		f.column = column
+	}
+}
+
+// Validate 指定字段的校验规则，规则与 binding 标签一致，例如 "required,max=255"
+func Validate(tag string) FieldOpt {
+	return func(f *field) {
+		f.validate = tag
+	}
+}
+
+// Required 每次修改都必须携带该字段，例如乐观锁的 version
+func Required() FieldOpt {
+	return func(f *field) {
+		f.required = true
+	}
+}
+
+// Transform 指定字段的转换函数，在校验通过之后执行
+func Transform(fn TransformFunc) FieldOpt {
+	return func(f *field) {
+		f.transform = fn
+	}
+}
+
+// FieldRule 可更新字段的声明
+type FieldRule struct {
+	key  string
+	opts []FieldOpt
+}
+
+// Field 声明一个允许更新的字段
+// key 请求中的字段名
+func Field(key string, opts ...FieldOpt) FieldRule {
+	return FieldRule{key: key, opts: opts}
+}
+
+// UpdatePolicy 基于map更新的字段白名单策略
+// 只有声明过的字段才允许更新，避免客户端覆盖 password、deleted_at 等任意列
+type UpdatePolicy struct {
+	name   string
+	idKey  string
+	fields map[string]*field
+}
+
+// NewUpdatePolicy 创建更新策略
+// name 领域名称，用于错误信息
+func NewUpdatePolicy(name string, rules ...FieldRule) *UpdatePolicy {
+	p := &UpdatePolicy{
+		name:   name,
+		idKey:  "id",
+		fields: make(map[string]*field, len(rules)),
+	}
+	for _, rule := range rules {
+		f := &field{column: rule.key}
+		for _, opt := range rule.opts {
+			opt(f)
+		}
+		p.fields[rule.key] = f
+	}
+	return p
+}
+
+// Fields 允许更新的字段
+func (p *UpdatePolicy) Fields() []string {
+	keys := make([]string, 0, len(p.fields))
+	for k := range p.fields {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Apply 按照策略校验并转换更新数据
+// 返回主键id以及可直接用于 Updates 的 列名->值
+func (p *UpdatePolicy) Apply(values map[string]interface{}) (uint, map[string]interface{}, error) {
+	raw, ok := values[p.idKey]
+	if !ok {
+		return 0, nil, fmt.Errorf("%s.%s: %w", p.name, p.idKey, ErrMissingID)
+	}
+	id, err := ParseID(raw)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s.%s: %w", p.name, p.idKey, err)
+	}
+
+	for key, f := range p.fields {
+		if _, ok := values[key]; f.required && !ok {
+			return 0, nil, fmt.Errorf("%s.%s: %w", p.name, key, ErrMissingField)
+		}
+	}
+
+	columns := make(map[string]interface{}, len(values))
+	for key, v := range values {
+		if key == p.idKey {
+			continue
+		}
+		f, ok := p.fields[key]
+		if !ok {
+			return 0, nil, fmt.Errorf("%s.%s: %w", p.name, key, ErrFieldNotAllowed)
+		}
+		if f.validate != "" {
+			if err := validate.Var(v, f.validate); err != nil {
+				return 0, nil, fmt.Errorf("%s.%s: %w: %s", p.name, key, ErrInvalidValue, err.Error())
+			}
+		}
+		if f.transform != nil {
+			v, err = f.transform(v)
+			if err != nil {
+				return 0, nil, fmt.Errorf("%s.%s: %w: %s", p.name, key, ErrInvalidValue, err.Error())
+			}
+		}
+		columns[f.column] = v
+	}
+
+	if len(columns) == 0 {
+		return 0, nil, fmt.Errorf("%s: %w", p.name, ErrEmptyUpdate)
+	}
+
+	return id, columns, nil
+}
+
+// IsInvalid 是否是策略校验产生的错误(参数错误)
+func IsInvalid(err error) bool {
+	return errors.Is(err, ErrMissingID) ||
+		errors.Is(err, ErrInvalidID) ||
+		errors.Is(err, ErrFieldNotAllowed) ||
+		errors.Is(err, ErrMissingField) ||
+		errors.Is(err, ErrInvalidValue) ||
+		errors.Is(err, ErrEmptyUpdate)
+}
+
+// ParseID 解析主键，兼容json反序列化得到的float64、json.Number以及字符串
+func ParseID(v interface{}) (uint, error) {
+	switch id := v.(type) {
+	case float64:
+		if id <= 0 || id != math.Trunc(id) || id > math.MaxInt64 {
+			return 0, ErrInvalidID
+		}
+		return uint(id), nil
+	case int:
+		if id <= 0 {
+			return 0, ErrInvalidID
+		}
+		return uint(id), nil
+	case int64:
+		if id <= 0 {
+			return 0, ErrInvalidID
+		}
+		return uint(id), nil
+	case uint:
+		if id == 0 {
+			return 0, ErrInvalidID
+		}
+		return id, nil
+	case json.Number:
+		return ParseID(string(id))
+	case string:
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil || n == 0 {
+			return 0, ErrInvalidID
+		}
+		return uint(n), nil
+	default:
+		return 0, ErrInvalidID
+	}
+}
+
+// BcryptHash 对字符串进行bcrypt hash，用于密码字段
+func BcryptHash(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("must be a string")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(s), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return string(hash), nil
+}
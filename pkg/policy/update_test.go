@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var userPolicy = NewUpdatePolicy("sysUser",
+	Field("nick_name", Validate("required,max=8")),
+	Field("password", Validate("min=6"), Transform(BcryptHash)),
+	Field("fileName", Column("file_name")),
+)
+
+var versionPolicy = NewUpdatePolicy("sysRole",
+	Field("role_name"),
+	Field("version", Required(), Validate("gt=0")),
+)
+
+func TestUpdatePolicy_Apply(t *testing.T) {
+	id, values, err := userPolicy.Apply(map[string]interface{}{
+		"id":        float64(3),
+		"nick_name": "张三",
+		"password":  "123456",
+		"fileName":  "a.txt",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), id)
+	assert.Equal(t, "张三", values["nick_name"])
+	assert.Equal(t, "a.txt", values["file_name"])
+	assert.NotContains(t, values, "fileName")
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(values["password"].(string)), []byte("123456")))
+}
+
+func TestUpdatePolicy_ApplyReject(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		err    error
+	}{
+		{"missing id", map[string]interface{}{"nick_name": "a"}, ErrMissingID},
+		{"string id", map[string]interface{}{"id": "abc", "nick_name": "a"}, ErrInvalidID},
+		{"float id", map[string]interface{}{"id": 1.5, "nick_name": "a"}, ErrInvalidID},
+		{"bool id", map[string]interface{}{"id": true, "nick_name": "a"}, ErrInvalidID},
+		{"deleted_at", map[string]interface{}{"id": float64(1), "deleted_at": nil}, ErrFieldNotAllowed},
+		{"too long", map[string]interface{}{"id": float64(1), "nick_name": strings.Repeat("a", 9)}, ErrInvalidValue},
+		{"bad transform", map[string]interface{}{"id": float64(1), "password": float64(1234567)}, ErrInvalidValue},
+		{"empty", map[string]interface{}{"id": float64(1)}, ErrEmptyUpdate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := userPolicy.Apply(tt.values)
+			assert.True(t, errors.Is(err, tt.err), err)
+			assert.True(t, IsInvalid(err))
+		})
+	}
+}
+
+func TestUpdatePolicy_Required(t *testing.T) {
+	_, _, err := versionPolicy.Apply(map[string]interface{}{"id": float64(1), "role_name": "a"})
+	assert.True(t, errors.Is(err, ErrMissingField), err)
+	assert.True(t, IsInvalid(err))
+
+	_, values, err := versionPolicy.Apply(map[string]interface{}{"id": float64(1), "role_name": "a", "version": float64(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), values["version"])
+}
+
+func TestParseID(t *testing.T) {
+	id, err := ParseID("42")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), id)
+
+	_, err = ParseID(float64(-1))
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
@@ -1,11 +1,43 @@
 package request
 
+// 总数统计方式
+const (
+	TotalExact    = "exact"    // 精确统计，分页查询默认
+	TotalNone     = "none"     // 不统计，游标分页默认
+	TotalEstimate = "estimate" // 根据数据库的统计信息估算整张表的行数，有查询条件或按租户隔离时精确统计
+)
+
 type PageSearch struct {
-	PageNum  int64  `json:"pageNum,omitempty" form:"pageNum"`   // 页码
-	PageSize int64  `json:"pageSize,omitempty" form:"pageSize"` // 每页显示数量
-	NoPage   bool   `json:"noPage,omitempty" form:"noPage"`     // 是否不进行分页
-	Keyword  string `json:"keyword,omitempty" form:"keyword"`   // 关键词
-	OrderBy  string `json:"orderBy,omitempty" form:"orderBy"`   // 排序字段
+	PageNum  int64  `json:"pageNum,omitempty" form:"pageNum"`                                                                       // 页码
+	PageSize int64  `json:"pageSize,omitempty" form:"pageSize"`                                                                     // 每页显示数量
+	NoPage   bool   `json:"noPage,omitempty" form:"noPage"`                                                                         // 是否不进行分页
+	Keyword  string `json:"keyword,omitempty" form:"keyword"`                                                                       // 关键词
+	OrderBy  string `json:"orderBy,omitempty" form:"orderBy" example:"-createdAt,id"`                                               // 排序，多个字段用逗号分隔，字段前加 - 为倒序
+	Keyset   bool   `json:"keyset,omitempty" form:"keyset"`                                                                         // 使用游标分页，第一页不传 cursor，忽略 pageNum
+	Cursor   string `json:"cursor,omitempty" form:"cursor"`                                                                         // 游标分页上一页返回的 nextCursor
+	Total    string `json:"total,omitempty" form:"total" binding:"omitempty,oneof=exact none estimate" enums:"exact,none,estimate"` // 总数统计方式 exact：精确，none：不统计，estimate：估算
+	Export   string `json:"export,omitempty" form:"export" binding:"omitempty,oneof=xlsx csv" enums:"xlsx,csv"`                     // 按查询条件导出全部数据，忽略分页
+}
+
+// GetPageSearch 返回分页参数，嵌入 PageSearch 的查询结构体可以通过该方法修改分页参数
+func (p *PageSearch) GetPageSearch() *PageSearch {
+	return p
+}
+
+// UseKeyset 是否使用游标分页
+func (p PageSearch) UseKeyset() bool {
+	return !p.NoPage && (p.Keyset || p.Cursor != "")
+}
+
+// TotalMode 总数统计方式，没有指定时分页查询精确统计，游标分页不统计
+func (p PageSearch) TotalMode() string {
+	if p.Total != "" {
+		return p.Total
+	}
+	if p.UseKeyset() {
+		return TotalNone
+	}
+	return TotalExact
 }
 
 type Ids struct {
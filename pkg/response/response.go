@@ -10,11 +10,20 @@ type Response struct {
 	Code    constant.RspCode `json:"code,omitempty"`
 	Message string           `json:"message,omitempty"`
 	Data    interface{}      `json:"data,omitempty"`
+	Version uint             `json:"version,omitempty"` // 版本冲突时数据当前的版本号
+}
+
+// PageInfo 分页信息
+type PageInfo struct {
+	Total          int64  `json:"total,omitempty"`
+	TotalEstimated bool   `json:"totalEstimated,omitempty"` // 总数是估算值
+	NextCursor     string `json:"nextCursor,omitempty"`     // 游标分页下一页的游标
+	HasMore        bool   `json:"hasMore,omitempty"`        // 游标分页是否还有下一页
 }
 
 type PageResponse struct {
-	Total int64       `json:"total,omitempty"`
-	List  interface{} `json:"list,omitempty"`
+	PageInfo
+	List interface{} `json:"list,omitempty"`
 }
 
 func Success(c *gin.Context, data ...interface{}) {
@@ -41,3 +50,14 @@ func Error(c *gin.Context, errorCode constant.RspCode, msg ...string) {
 
 	c.JSON(http.StatusBadRequest, r)
 }
+
+// Conflict 版本冲突，返回数据当前的版本号以便客户端重试
+func Conflict(c *gin.Context, version uint) {
+	var r Response
+
+	r.Code = constant.CODE_VERSION_CONFLICT
+	r.Message = r.Code.Msg()
+	r.Version = version
+
+	c.JSON(http.StatusConflict, r)
+}
@@ -0,0 +1,241 @@
+package scopes
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 过滤操作
+const (
+	FilterEq           = "eq"            // 等于
+	FilterNe           = "ne"            // 不等于
+	FilterGt           = "gt"            // 大于
+	FilterGte          = "gte"           // 大于等于
+	FilterLt           = "lt"            // 小于
+	FilterLte          = "lte"           // 小于等于
+	FilterLike         = "like"          // 包含，% 和 _ 会被转义
+	FilterIn           = "in"            // 在列表中
+	FilterBetween      = "between"       // 在区间中，两个值分别为起止，为空则不限制
+	FilterJSONContains = "json_contains" // json数组列包含全部元素
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// filterField 查询结构体中需要过滤的字段
+type filterField struct {
+	index  []int
+	name   string
+	op     string
+	column string // 为空则使用命名策略生成
+}
+
+var filterFields sync.Map // reflect.Type -> []filterField
+
+// Filter 根据查询结构体中 filter 标签生成查询条件，零值字段不作为条件，需要按零值过滤时使用指针
+// 标签格式为 filter:"操作;column:列名"，列名默认与 gorm 一致，嵌入的结构体会递归解析
+// in、between、json_contains 的值可以是切片或逗号分隔的字符串
+//
+//	Name      string   `form:"name" filter:"like"`
+//	Status    []string `form:"status" filter:"in"`
+//	CreatedAt []string `form:"created_at" filter:"between;column:created_at"`
+//	Tags      string   `form:"tags" filter:"json_contains"`
+//
+// 列名只能通过标签指定，请求中的值都作为参数传递
+func Filter(search interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		rv := reflect.Indirect(reflect.ValueOf(search))
+		if rv.Kind() != reflect.Struct {
+			return db
+		}
+		fields, err := parseFilterFields(rv.Type())
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+
+		exprs := make([]clause.Expression, 0, len(fields))
+		for _, field := range fields {
+			fv, err := rv.FieldByIndexErr(field.index)
+			if err != nil {
+				// 嵌入的结构体指针为空
+				continue
+			}
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			} else if fv.IsZero() {
+				continue
+			}
+
+			column := field.column
+			if column == "" {
+				column = db.NamingStrategy.ColumnName("", field.name)
+			}
+			expr, err := filterExpr(db, field, clause.Column{Table: clause.CurrentTable, Name: column}, fv)
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			if expr != nil {
+				exprs = append(exprs, expr)
+			}
+		}
+		if len(exprs) == 0 {
+			return db
+		}
+		return db.Where(clause.And(exprs...))
+	}
+}
+
+// parseFilterFields 解析结构体中的 filter 标签，结果按类型缓存
+func parseFilterFields(t reflect.Type) ([]filterField, error) {
+	if v, ok := filterFields.Load(t); ok {
+		return v.([]filterField), nil
+	}
+
+	var fields []filterField
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup("filter")
+		if !ok || tag == "" || tag == "-" || sf.Anonymous || !sf.IsExported() {
+			continue
+		}
+		field := filterField{
+			index:  sf.Index,
+			name:   sf.Name,
+			op:     strings.ToLower(strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])),
+			column: schema.ParseTagSetting(tag, ";")["COLUMN"],
+		}
+		if field.column == "" {
+			field.column = schema.ParseTagSetting(sf.Tag.Get("gorm"), ";")["COLUMN"]
+		}
+		switch field.op {
+		case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterLike, FilterIn, FilterBetween, FilterJSONContains:
+		default:
+			return nil, fmt.Errorf("%w: %s.%s unknown operator %q", ErrInvalidFilter, t.Name(), sf.Name, tag)
+		}
+		fields = append(fields, field)
+	}
+
+	filterFields.Store(t, fields)
+	return fields, nil
+}
+
+func filterExpr(db *gorm.DB, field filterField, column clause.Column, fv reflect.Value) (clause.Expression, error) {
+	switch field.op {
+	case FilterEq:
+		return clause.Eq{Column: column, Value: fv.Interface()}, nil
+	case FilterNe:
+		return clause.Neq{Column: column, Value: fv.Interface()}, nil
+	case FilterGt:
+		return clause.Gt{Column: column, Value: fv.Interface()}, nil
+	case FilterGte:
+		return clause.Gte{Column: column, Value: fv.Interface()}, nil
+	case FilterLt:
+		return clause.Lt{Column: column, Value: fv.Interface()}, nil
+	case FilterLte:
+		return clause.Lte{Column: column, Value: fv.Interface()}, nil
+	case FilterLike:
+		return clause.Expr{
+			SQL:  "? LIKE ?" + likeEscape(db),
+			Vars: []interface{}{column, "%" + escapeLike(fmt.Sprint(fv.Interface())) + "%"},
+		}, nil
+	case FilterIn:
+		values := filterValues(fv, true)
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	case FilterBetween:
+		values := filterValues(fv, false)
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%w: %s requires 2 values", ErrInvalidFilter, field.name)
+		}
+		exprs := make([]clause.Expression, 0, 2)
+		if !isZero(values[0]) {
+			exprs = append(exprs, clause.Gte{Column: column, Value: values[0]})
+		}
+		if !isZero(values[1]) {
+			exprs = append(exprs, clause.Lte{Column: column, Value: values[1]})
+		}
+		if len(exprs) == 0 {
+			return nil, nil
+		}
+		return clause.And(exprs...), nil
+	case FilterJSONContains:
+		values := filterValues(fv, true)
+		exprs := make([]clause.Expression, 0, len(values))
+		for _, v := range values {
+			query, arg := jsonArrayContains(db, "?", v)
+			exprs = append(exprs, clause.Expr{SQL: query, Vars: []interface{}{column, arg}})
+		}
+		if len(exprs) == 0 {
+			return nil, nil
+		}
+		return clause.And(exprs...), nil
+	}
+	return nil, nil
+}
+
+// filterValues 切片的元素或逗号分隔的字符串
+func filterValues(fv reflect.Value, skipZero bool) []interface{} {
+	var values []interface{}
+	switch fv.Kind() {
+	case reflect.String:
+		for _, s := range strings.Split(fv.String(), ",") {
+			values = append(values, strings.TrimSpace(s))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			elem := fv.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					values = append(values, nil)
+					continue
+				}
+				elem = elem.Elem()
+			}
+			values = append(values, elem.Interface())
+		}
+	default:
+		values = append(values, fv.Interface())
+	}
+	if !skipZero {
+		return values
+	}
+
+	res := values[:0]
+	for _, v := range values {
+		if !isZero(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func isZero(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 转义 LIKE 的通配符
+func escapeLike(s string) string {
+	return likeReplacer.Replace(s)
+}
+
+// likeEscape mysql、postgres 默认使用反斜杠转义，sqlite 需要指定
+func likeEscape(db *gorm.DB) string {
+	if db.Dialector.Name() == "sqlite" {
+		return ` ESCAPE '\'`
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package scopes
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type filterArticle struct {
+	ID     uint
+	Title  string
+	Status string
+	Views  int
+	Tags   datatypes.JSONSlice[string]
+}
+
+type filterBase struct {
+	Status string `filter:"eq"`
+}
+
+type filterSearch struct {
+	filterBase
+	Title    string   `filter:"like"`
+	Statuses []string `filter:"in;column:status"`
+	Views    []int    `filter:"between"`
+	MinViews *int     `filter:"gte;column:views"`
+	Tags     string   `filter:"json_contains"`
+	Keyword  string
+}
+
+func TestFilter(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&filterArticle{}))
+	assert.NoError(t, db.Create(&[]filterArticle{
+		{Title: "golang gin", Status: "draft", Views: 0, Tags: []string{"go", "web"}},
+		{Title: "100% golang", Status: "published", Views: 10, Tags: []string{"go"}},
+		{Title: "rust_axum", Status: "published", Views: 20, Tags: []string{"rust", "web"}},
+	}).Error)
+
+	find := func(search interface{}) []uint {
+		var ids []uint
+		assert.NoError(t, db.Model(&filterArticle{}).Scopes(Filter(search)).Order("id").Pluck("id", &ids).Error)
+		return ids
+	}
+	zero := 0
+
+	assert.Equal(t, []uint{1, 2, 3}, find(filterSearch{Keyword: "ignored"}))
+	assert.Equal(t, []uint{1, 2}, find(filterSearch{Title: "golang"}))
+	// 通配符被转义
+	assert.Equal(t, []uint{2}, find(filterSearch{Title: "0%"}))
+	assert.Equal(t, []uint{3}, find(filterSearch{Title: "t_a"}))
+	assert.Empty(t, find(filterSearch{Title: "t%x"}))
+
+	assert.Equal(t, []uint{1}, find(&filterSearch{filterBase: filterBase{Status: "draft"}}))
+	assert.Equal(t, []uint{1}, find(filterSearch{Statuses: []string{"draft", ""}}))
+	assert.Equal(t, []uint{2}, find(filterSearch{Statuses: []string{"published"}, Title: "golang"}))
+	assert.Equal(t, []uint{2, 3}, find(filterSearch{Views: []int{5, 0}}))
+	assert.Equal(t, []uint{1, 2}, find(filterSearch{Views: []int{0, 10}}))
+	assert.Equal(t, []uint{1, 2, 3}, find(filterSearch{MinViews: &zero}))
+	assert.Equal(t, []uint{1, 3}, find(filterSearch{Tags: "web"}))
+	assert.Equal(t, []uint{1}, find(filterSearch{Tags: "go, web"}))
+
+	// 参数错误
+	err = db.Model(&filterArticle{}).Scopes(Filter(filterSearch{Views: []int{1}})).Find(&[]filterArticle{}).Error
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+	err = db.Model(&filterArticle{}).Scopes(Filter(struct {
+		Title string `filter:"regexp"`
+	}{Title: "go"})).Find(&[]filterArticle{}).Error
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
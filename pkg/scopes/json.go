@@ -0,0 +1,30 @@
+package scopes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// jsonArrayContains 返回判断json数组列包含某个元素的条件，不同数据库的json函数不同
+func jsonArrayContains(db *gorm.DB, col string, val interface{}) (string, interface{}) {
+	switch db.Dialector.Name() {
+	case "postgres":
+		b, _ := json.Marshal([]interface{}{val})
+		return fmt.Sprintf("(%s)::jsonb @> ?::jsonb", col), string(b)
+	case "sqlite":
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE json_each.value = ?)", col), val
+	default:
+		b, _ := json.Marshal([]interface{}{val})
+		return fmt.Sprintf("JSON_CONTAINS(%s, ?)", col), string(b)
+	}
+}
+
+// JSONArrayContainsScope json数组列包含 val
+func JSONArrayContainsScope(col string, val interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		query, arg := jsonArrayContains(db, col, val)
+		return db.Where(query, arg)
+	}
+}
@@ -0,0 +1,65 @@
+package scopes
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type jsonArticle struct {
+	ID   uint
+	Tags datatypes.JSONSlice[string]
+}
+
+func TestMatchStringSliceScope_Sqlite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&jsonArticle{}))
+	assert.NoError(t, db.Create(&[]jsonArticle{
+		{Tags: []string{"golang", "gin"}},
+		{Tags: []string{"golang", `"quote"`}},
+		{Tags: []string{"rust"}},
+	}).Error)
+
+	var ids []uint
+	db.Model(&jsonArticle{}).Scopes(MatchStringSliceScope("tags", []string{"golang", "gin"}, true)).Order("id").Pluck("id", &ids)
+	assert.Equal(t, []uint{1}, ids)
+
+	db.Model(&jsonArticle{}).Scopes(MatchStringSliceScope("tags", []string{"gin", "rust"}, false)).Order("id").Pluck("id", &ids)
+	assert.Equal(t, []uint{1, 3}, ids)
+
+	db.Model(&jsonArticle{}).Scopes(JSONArrayContainsScope("tags", `"quote"`)).Pluck("id", &ids)
+	assert.Equal(t, []uint{2}, ids)
+}
+
+func TestJSONArrayContains_Dialect(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DryRun: true})
+
+	query, arg := jsonArrayContains(db, "tags", "go")
+	assert.Equal(t, "EXISTS (SELECT 1 FROM json_each(tags) WHERE json_each.value = ?)", query)
+	assert.Equal(t, "go", arg)
+
+	db.Dialector = namedDialector{Dialector: db.Dialector, name: "mysql"}
+	query, arg = jsonArrayContains(db, "tags", `a"b`)
+	assert.Equal(t, "JSON_CONTAINS(tags, ?)", query)
+	assert.Equal(t, `["a\"b"]`, arg)
+
+	db.Dialector = namedDialector{Dialector: db.Dialector, name: "postgres"}
+	query, arg = jsonArrayContains(db, "tags", "go")
+	assert.Equal(t, "(tags)::jsonb @> ?::jsonb", query)
+	assert.Equal(t, `["go"]`, arg)
+}
+
+type namedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d namedDialector) Name() string {
+	return d.name
+}
@@ -0,0 +1,296 @@
+package scopes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/Madou-Shinni/gin-quickstart/pkg/tools/pagelimit"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor 游标，记录上一页最后一条数据的排序字段
+type cursor struct {
+	OrderBy string   `json:"o"` // 生成游标时的排序，排序变化后游标失效
+	Values  []string `json:"v"` // 带类型前缀的排序字段值
+}
+
+// Keyset 游标分页
+// 按排序字段和主键定位上一页最后一条数据，之后的数据通过 WHERE 条件查询，不需要 OFFSET
+// 可以为空的排序字段(指针、sql.Null* 等)空值排在最后，与正序倒序无关
+//
+//	keyset := scopes.NewKeyset(page)
+//	db.Scopes(keyset.Scope).Find(&list)
+//	if len(list) > keyset.Limit() {
+//		list = list[:keyset.Limit()]
+//		nextCursor, err = keyset.Cursor(&list[len(list)-1])
+//	}
+type Keyset struct {
+	orderBy string
+	cursor  string
+	limit   int
+	keys    []sortKey
+}
+
+// NewKeyset 根据分页参数初始化游标分页
+func NewKeyset(page request.PageSearch) *Keyset {
+	_, limit := pagelimit.OffsetLimit(1, page.PageSize)
+	return &Keyset{orderBy: page.OrderBy, cursor: page.Cursor, limit: limit}
+}
+
+// Limit 每页数量，查询时会多查询一条用于判断是否有下一页
+func (k *Keyset) Limit() int {
+	return k.limit
+}
+
+// Scope 添加排序、游标条件和数量限制，排序规则与 Sort 一致，没有指定时按主键倒序
+func (k *Keyset) Scope(db *gorm.DB) *gorm.DB {
+	keys, err := parseSort(db, k.orderBy)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	// 追加主键保证顺序唯一
+	pk := db.Statement.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		_ = db.AddError(gorm.ErrPrimaryKeyRequired)
+		return db
+	}
+	var hasPK bool
+	for _, key := range keys {
+		hasPK = hasPK || key.field == pk
+	}
+	if !hasPK {
+		desc := true
+		if len(keys) > 0 {
+			desc = keys[len(keys)-1].desc
+		}
+		keys = append(keys, sortKey{name: jsonName(pk), field: pk, desc: desc})
+	}
+	k.keys = keys
+
+	if k.cursor != "" {
+		values, err := k.decode()
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		db = db.Where(k.after(values))
+	}
+	for _, key := range keys {
+		if key.nullable() {
+			// 各数据库空值的默认顺序不同，统一排在最后
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: db.Statement.Quote(key.orderBy().Column) + " IS NULL", Raw: true}})
+		}
+		db = db.Order(key.orderBy())
+	}
+	return db.Limit(k.limit + 1)
+}
+
+// after 排在游标之后的数据
+// (a, b, id) 之后为 a > ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND id > ?)，倒序时为 <
+// 空值排在最后：游标值为空时 a = ? 为 a IS NULL，之后没有数据；不为空时之后还有 a IS NULL 的数据
+func (k *Keyset) after(values []interface{}) clause.Expression {
+	ors := make([]clause.Expression, 0, len(k.keys))
+	for i, key := range k.keys {
+		if values[i] == nil {
+			continue
+		}
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			// Value 为 nil 时为 IS NULL
+			ands = append(ands, clause.Eq{Column: k.keys[j].orderBy().Column, Value: values[j]})
+		}
+		column := key.orderBy().Column
+		var next clause.Expression = clause.Gt{Column: column, Value: values[i]}
+		if key.desc {
+			next = clause.Lt{Column: column, Value: values[i]}
+		}
+		if key.nullable() {
+			next = clause.Or(next, clause.Eq{Column: column, Value: nil})
+		}
+		ors = append(ors, clause.And(append(ands, next)...))
+	}
+	if len(ors) == 0 {
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	return clause.Or(ors...)
+}
+
+// Cursor 根据本页最后一条数据生成下一页的游标，需要在 Scope 执行之后调用
+func (k *Keyset) Cursor(last interface{}) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(last))
+	c := cursor{OrderBy: k.signature(), Values: make([]string, 0, len(k.keys))}
+	for _, key := range k.keys {
+		v, _ := key.field.ValueOf(context.Background(), rv)
+		s, err := encodeCursorValue(v)
+		if err == nil && s == nullCursorValue && !key.nullable() {
+			err = errors.New("is null")
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %s %v", ErrInvalidCursor, key.name, err)
+		}
+		c.Values = append(c.Values, s)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (k *Keyset) decode() ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(k.cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err = json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.OrderBy != k.signature() || len(c.Values) != len(k.keys) {
+		return nil, fmt.Errorf("%w: order changed", ErrInvalidCursor)
+	}
+
+	values := make([]interface{}, 0, len(c.Values))
+	for _, s := range c.Values {
+		v, err := decodeCursorValue(s)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// signature 排序规则，例如 -createdAt,-id
+func (k *Keyset) signature() string {
+	names := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		names = append(names, key.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// nullCursorValue 空值(NULL)的游标值
+const nullCursorValue = "n:"
+
+// encodeCursorValue 编码排序字段的值，前缀记录类型，解码后与数据库中的类型一致
+func encodeCursorValue(v interface{}) (string, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nullCursorValue, nil
+		}
+		v = rv.Elem().Interface()
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return "", err
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "i:" + strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "u:" + strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return "f:" + strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	case reflect.Bool:
+		return "b:" + strconv.FormatBool(rv.Bool()), nil
+	case reflect.String:
+		return "s:" + rv.String(), nil
+	}
+	switch v := v.(type) {
+	case time.Time:
+		return "t:" + v.Format(time.RFC3339Nano), nil
+	case []byte:
+		return "s:" + string(v), nil
+	case nil:
+		return nullCursorValue, nil
+	}
+	return "", fmt.Errorf("unsupported type %T", v)
+}
+
+func decodeCursorValue(s string) (interface{}, error) {
+	typ, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	switch typ {
+	case "n":
+		return nil, nil
+	case "i":
+		return strconv.ParseInt(value, 10, 64)
+	case "u":
+		return strconv.ParseUint(value, 10, 64)
+	case "f":
+		return strconv.ParseFloat(value, 64)
+	case "b":
+		return strconv.ParseBool(value)
+	case "s":
+		return value, nil
+	case "t":
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return nil, ErrInvalidCursor
+}
+
+// EstimateCount 根据数据库的统计信息估算表的行数
+// 支持 mysql、postgres，其他数据库、没有统计信息、有查询条件或者按租户隔离的表精确统计，返回值 estimated 表示是否为估算值
+func EstimateCount(db *gorm.DB) (count int64, estimated bool, err error) {
+	if err = db.Statement.Parse(db.Statement.Model); err != nil {
+		return 0, false, err
+	}
+	// 统计信息是整张表的行数，有查询条件时不准确，按租户隔离时会暴露所有租户的总数
+	if db.Statement.Schema.LookUpField("TenantID") != nil || hasConditions(db) {
+		err = db.Count(&count).Error
+		return count, false, err
+	}
+	tx := db.Session(&gorm.Session{NewDB: true})
+	var rows *int64
+	switch db.Dialector.Name() {
+	case "mysql":
+		err = tx.Raw("SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", db.Statement.Table).Scan(&rows).Error
+	case "postgres":
+		// 没有执行过 ANALYZE 时为 -1
+		err = tx.Raw("SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)", db.Statement.Table).Scan(&rows).Error
+	}
+	if err == nil && rows != nil && *rows >= 0 {
+		return *rows, true, nil
+	}
+	err = db.Count(&count).Error
+	return count, false, err
+}
+
+// hasConditions 查询是否有条件(包括 Scopes 中的条件)，软删除的条件除外
+func hasConditions(db *gorm.DB) bool {
+	stmt := db.Session(&gorm.Session{DryRun: true}).Count(new(int64)).Statement
+	where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok {
+		return false
+	}
+	deletedAt := stmt.Schema.LookUpField("DeletedAt")
+	for _, expr := range where.Exprs {
+		if eq, ok := expr.(clause.Eq); ok && deletedAt != nil {
+			if column, ok := eq.Column.(clause.Column); ok && column.Table == clause.CurrentTable && column.Name == deletedAt.DBName {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
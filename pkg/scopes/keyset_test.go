@@ -0,0 +1,140 @@
+package scopes
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/Madou-Shinni/gin-quickstart/pkg/request"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type keysetArticle struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Views       int        `json:"views"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	PublishedAt *time.Time `json:"publishedAt"`
+}
+
+func (keysetArticle) SortFields() []string {
+	return []string{"id", "title", "views", "createdAt", "publishedAt"}
+}
+
+func TestKeyset(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&keysetArticle{}))
+	now := time.Now()
+	var articles []keysetArticle
+	for i := 0; i < 7; i++ {
+		// views 有重复值，通过主键区分顺序
+		article := keysetArticle{Title: string(rune('a' + i)), Views: i / 3, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		if i%2 == 0 {
+			published := article.CreatedAt
+			article.PublishedAt = &published
+		}
+		articles = append(articles, article)
+	}
+	assert.NoError(t, db.Create(&articles).Error)
+
+	// 逐页查询直到没有下一页
+	all := func(page request.PageSearch) ([]uint, int) {
+		var (
+			ids   []uint
+			pages int
+		)
+		for {
+			keyset := NewKeyset(page)
+			var list []keysetArticle
+			assert.NoError(t, db.Model(&keysetArticle{}).Scopes(keyset.Scope).Find(&list).Error)
+			pages++
+			if len(list) <= keyset.Limit() {
+				for _, article := range list {
+					ids = append(ids, article.ID)
+				}
+				return ids, pages
+			}
+			list = list[:keyset.Limit()]
+			for _, article := range list {
+				ids = append(ids, article.ID)
+			}
+			page.Cursor, err = keyset.Cursor(&list[len(list)-1])
+			assert.NoError(t, err)
+		}
+	}
+
+	ids, pages := all(request.PageSearch{PageSize: 3})
+	assert.Equal(t, []uint{7, 6, 5, 4, 3, 2, 1}, ids)
+	assert.Equal(t, 3, pages)
+
+	// 混合排序方向，主键跟随最后一个字段的方向
+	ids, _ = all(request.PageSearch{PageSize: 2, OrderBy: "-views,title"})
+	assert.Equal(t, []uint{7, 4, 5, 6, 1, 2, 3}, ids)
+	ids, _ = all(request.PageSearch{PageSize: 2, OrderBy: "views,-id"})
+	assert.Equal(t, []uint{3, 2, 1, 6, 5, 4, 7}, ids)
+	ids, _ = all(request.PageSearch{PageSize: 4, OrderBy: "createdAt"})
+	assert.Equal(t, []uint{1, 2, 3, 4, 5, 6, 7}, ids)
+
+	// 可以为空的字段，空值排在最后
+	ids, _ = all(request.PageSearch{PageSize: 2, OrderBy: "publishedAt"})
+	assert.Equal(t, []uint{1, 3, 5, 7, 2, 4, 6}, ids)
+	ids, _ = all(request.PageSearch{PageSize: 2, OrderBy: "-publishedAt"})
+	assert.Equal(t, []uint{7, 5, 3, 1, 6, 4, 2}, ids)
+	ids, _ = all(request.PageSearch{PageSize: 3, OrderBy: "-publishedAt,title"})
+	assert.Equal(t, []uint{7, 5, 3, 1, 2, 4, 6}, ids)
+
+	find := func(page request.PageSearch) error {
+		return db.Model(&keysetArticle{}).Scopes(NewKeyset(page).Scope).Find(&[]keysetArticle{}).Error
+	}
+	keyset := NewKeyset(request.PageSearch{PageSize: 2, OrderBy: "title"})
+	assert.NoError(t, db.Model(&keysetArticle{}).Scopes(keyset.Scope).Find(&[]keysetArticle{}).Error)
+	cursor, err := keyset.Cursor(&articles[1])
+	assert.NoError(t, err)
+	assert.NoError(t, find(request.PageSearch{OrderBy: "title", Cursor: cursor}))
+
+	// 排序变化、篡改的游标
+	assert.ErrorIs(t, find(request.PageSearch{OrderBy: "-title", Cursor: cursor}), ErrInvalidCursor)
+	assert.ErrorIs(t, find(request.PageSearch{OrderBy: "title", Cursor: cursor + "x"}), ErrInvalidCursor)
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(`{"o":"title,id","v":["s:b","x:1"]}`))
+	assert.ErrorIs(t, find(request.PageSearch{OrderBy: "title", Cursor: tampered}), ErrInvalidCursor)
+	assert.ErrorIs(t, find(request.PageSearch{OrderBy: "secret"}), ErrInvalidSort)
+}
+
+func TestEstimateCount(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&keysetArticle{}))
+	assert.NoError(t, db.Create(&[]keysetArticle{{Title: "a"}, {Title: "b"}}).Error)
+
+	// sqlite 没有统计信息，精确统计
+	count, estimated, err := EstimateCount(db.Model(&keysetArticle{}).Where("title = ?", "a"))
+	assert.NoError(t, err)
+	assert.False(t, estimated)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestHasConditions(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&keysetArticle{}))
+
+	assert.False(t, hasConditions(db.Model(&keysetArticle{})))
+	assert.True(t, hasConditions(db.Model(&keysetArticle{}).Where("title = ?", "a")))
+	assert.True(t, hasConditions(db.Model(&keysetArticle{}).Scopes(Where("title = ?", "a"))))
+
+	// 软删除的条件不算查询条件
+	type softArticle struct {
+		ID        uint
+		DeletedAt gorm.DeletedAt
+	}
+	assert.NoError(t, db.AutoMigrate(&softArticle{}))
+	assert.False(t, hasConditions(db.Model(&softArticle{})))
+	assert.True(t, hasConditions(db.Model(&softArticle{}).Where("id > ?", 1)))
+}
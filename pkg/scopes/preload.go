@@ -0,0 +1,10 @@
+package scopes
+
+import "gorm.io/gorm"
+
+// Preload 预加载关联，参数与 gorm.DB.Preload 一致
+func Preload(query string, args ...interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload(query, args...)
+	}
+}
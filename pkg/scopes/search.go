@@ -0,0 +1,25 @@
+package scopes
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Search 关键词模糊搜索，任意一列包含关键词即可，% 和 _ 会被转义
+// columns 为列名，由调用方保证合法，关键词为空时不做处理
+func Search(keyword string, columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if keyword == "" || len(columns) == 0 {
+			return db
+		}
+		value := "%" + escapeLike(keyword) + "%"
+		exprs := make([]clause.Expression, 0, len(columns))
+		for _, column := range columns {
+			exprs = append(exprs, clause.Expr{
+				SQL:  "? LIKE ?" + likeEscape(db),
+				Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: column}, value},
+			})
+		}
+		return db.Where(clause.Or(exprs...))
+	}
+}
@@ -0,0 +1,20 @@
+package scopes
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithDeleted 查询包括已删除(软删除)的数据
+func WithDeleted() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// OnlyDeleted 只查询已删除(软删除)的数据
+func OnlyDeleted() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}, Value: nil})
+	}
+}
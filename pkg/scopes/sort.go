@@ -0,0 +1,145 @@
+package scopes
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// Sortable 声明模型允许排序的字段(json名称)，没有实现时只能按主键排序
+type Sortable interface {
+	SortFields() []string
+}
+
+var sortFields sync.Map // reflect.Type -> map[string]*schema.Field
+
+// sortKey 排序字段
+type sortKey struct {
+	name  string
+	field *schema.Field
+	desc  bool
+}
+
+func (k sortKey) String() string {
+	if k.desc {
+		return "-" + k.name
+	}
+	return k.name
+}
+
+func (k sortKey) orderBy() clause.OrderByColumn {
+	return clause.OrderByColumn{
+		Column: clause.Column{Table: clause.CurrentTable, Name: k.field.DBName},
+		Desc:   k.desc,
+	}
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// nullable 字段的值可以为空(指针或者 sql.Null* 等 driver.Valuer)
+func (k sortKey) nullable() bool {
+	if k.field.NotNull || k.field.PrimaryKey {
+		return false
+	}
+	t := k.field.FieldType
+	return t.Kind() == reflect.Ptr || t.Implements(valuerType) || reflect.PointerTo(t).Implements(valuerType)
+}
+
+// Sort 根据请求中的 orderBy 排序，多个字段用逗号分隔，字段前加 - 为倒序，例如 -createdAt,name
+// 字段为json名称，通过模型的 schema 映射为列名，不允许排序的字段返回 ErrInvalidSort
+func Sort(orderBy string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if strings.TrimSpace(orderBy) == "" {
+			return db
+		}
+		keys, err := parseSort(db, orderBy)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		for _, key := range keys {
+			db = db.Order(key.orderBy())
+		}
+		return db
+	}
+}
+
+// parseSort 解析 orderBy，字段必须是模型允许排序的字段
+func parseSort(db *gorm.DB, orderBy string) ([]sortKey, error) {
+	if err := db.Statement.Parse(db.Statement.Model); err != nil {
+		return nil, err
+	}
+	fields, err := sortableFields(db.Statement.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []sortKey
+	for _, item := range strings.Split(orderBy, ",") {
+		name := strings.TrimSpace(item)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimLeft(name, "-+")
+		if name == "" {
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, name)
+		}
+		keys = append(keys, sortKey{name: name, field: field, desc: desc})
+	}
+	return keys, nil
+}
+
+// sortableFields 允许排序的json名称到字段的映射，结果按模型缓存
+func sortableFields(s *schema.Schema) (map[string]*schema.Field, error) {
+	if v, ok := sortFields.Load(s.ModelType); ok {
+		return v.(map[string]*schema.Field), nil
+	}
+
+	byName := make(map[string]*schema.Field, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName != "" {
+			byName[jsonName(field)] = field
+		}
+	}
+
+	var names []string
+	if sortable, ok := reflect.New(s.ModelType).Interface().(Sortable); ok {
+		names = sortable.SortFields()
+	} else {
+		for _, field := range s.PrimaryFields {
+			names = append(names, jsonName(field))
+		}
+	}
+
+	fields := make(map[string]*schema.Field, len(names))
+	for _, name := range names {
+		field, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no field %q", ErrInvalidSort, s.Name, name)
+		}
+		fields[name] = field
+	}
+
+	sortFields.Store(s.ModelType, fields)
+	return fields, nil
+}
+
+// jsonName 字段在请求中的名称，没有json标签时使用字段名
+func jsonName(field *schema.Field) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,68 @@
+package scopes
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type sortArticle struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	Views     int    `json:"views"`
+	CreatedAt int64  `json:"createdAt"`
+	Secret    string `json:"secret"`
+}
+
+func (sortArticle) SortFields() []string {
+	return []string{"id", "title", "views", "createdAt"}
+}
+
+type sortComment struct {
+	ID   uint
+	Body string
+}
+
+type sortInvalid struct {
+	ID uint
+}
+
+func (sortInvalid) SortFields() []string {
+	return []string{"missing"}
+}
+
+func TestSort(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+
+	sql := func(model interface{}, orderBy string) (string, error) {
+		stmt := db.Model(model).Scopes(Sort(orderBy)).Find(&[]map[string]interface{}{}).Statement
+		return stmt.SQL.String(), stmt.Error
+	}
+
+	query, err := sql(&sortArticle{}, "-createdAt, title,+views,")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `sort_articles` ORDER BY `sort_articles`.`created_at` DESC,`sort_articles`.`title`,`sort_articles`.`views`", query)
+
+	query, err = sql(&sortArticle{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `sort_articles`", query)
+
+	// 没有声明的字段、列名、注入
+	for _, orderBy := range []string{"secret", "created_at", "id;DROP TABLE sort_articles", "(SELECT 1)"} {
+		_, err = sql(&sortArticle{}, orderBy)
+		assert.ErrorIs(t, err, ErrInvalidSort, orderBy)
+	}
+
+	// 没有声明时只能按主键排序
+	query, err = sql(&sortComment{}, "-ID")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `sort_comments` ORDER BY `sort_comments`.`id` DESC", query)
+	_, err = sql(&sortComment{}, "Body")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+
+	_, err = sql(&sortInvalid{}, "id")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
@@ -1,7 +1,6 @@
 package scopes
 
 import (
-	"fmt"
 	"gorm.io/gorm"
 	"strings"
 )
@@ -18,8 +17,9 @@ func MatchStringSliceScope(col string, vals []string, matchAll bool) func(db *go
 		args := make([]interface{}, 0, len(vals))
 
 		for _, tag := range vals {
-			conditions = append(conditions, fmt.Sprintf("JSON_CONTAINS(%s, ?)", col))
-			args = append(args, fmt.Sprintf(`["%s"]`, tag))
+			query, arg := jsonArrayContains(db, col, tag)
+			conditions = append(conditions, query)
+			args = append(args, arg)
 		}
 
 		join := " OR "
@@ -29,3 +29,10 @@ func MatchStringSliceScope(col string, vals []string, matchAll bool) func(db *go
 		return db.Where(strings.Join(conditions, join), args...)
 	}
 }
+
+// Where 查询条件，参数与 gorm.DB.Where 一致
+func Where(query interface{}, args ...interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
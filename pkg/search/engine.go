@@ -0,0 +1,50 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// FullText mysql FULLTEXT 索引，搜索字段需要建立FULLTEXT索引(中文使用 WITH PARSER ngram)
+// 使用 BOOLEAN MODE，关键词按空格分词后每个词都需要匹配
+type FullText struct{}
+
+// fullTextReplacer BOOLEAN MODE 的运算符替换为空格
+var fullTextReplacer = strings.NewReplacer("+", " ", "-", " ", "<", " ", ">", " ", "(", " ", ")", " ", "~", " ", "*", " ", `"`, " ", "@", " ")
+
+func (FullText) Match(ctx context.Context, q Query) (clause.Expression, error) {
+	words := strings.Fields(fullTextReplacer.Replace(q.Keyword))
+	if len(words) == 0 {
+		return clause.Expr{SQL: "1 = 0"}, nil
+	}
+	against := "+" + strings.Join(words, " +")
+
+	columns := make([]string, 0, len(q.Columns))
+	vars := make([]interface{}, 0, len(q.Columns)+1)
+	for _, column := range q.Columns {
+		columns = append(columns, "?")
+		vars = append(vars, clause.Column{Table: clause.CurrentTable, Name: column})
+	}
+	vars = append(vars, against)
+	return clause.Expr{SQL: "MATCH (" + strings.Join(columns, ",") + ") AGAINST (? IN BOOLEAN MODE)", Vars: vars}, nil
+}
+
+// Like 任意一个搜索字段包含关键词，用于没有全文索引的数据库
+// 使用 ! 转义通配符，各数据库对反斜杠的处理不一致
+type Like struct{}
+
+var likeReplacer = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+func (Like) Match(ctx context.Context, q Query) (clause.Expression, error) {
+	value := "%" + likeReplacer.Replace(q.Keyword) + "%"
+	exprs := make([]clause.Expression, 0, len(q.Columns))
+	for _, column := range q.Columns {
+		exprs = append(exprs, clause.Expr{
+			SQL:  "? LIKE ? ESCAPE '!'",
+			Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: column}, value},
+		})
+	}
+	return clause.Or(exprs...), nil
+}
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Task 同步搜索引擎的异步任务
+type Task struct {
+	Table    string   `json:"table"`
+	IDs      []string `json:"ids"`
+	TenantID uint     `json:"tenant_id"` // 数据所属的租户，用于选择租户的独立数据库
+	Shared   bool     `json:"shared"`    // 数据保存在共享库
+}
+
+// Sync 按主键同步数据，存在的数据写入搜索引擎，不存在(已删除)的从搜索引擎删除
+// 通过 ctx 中的租户选择数据库和索引，搜索引擎不需要同步数据时不做处理
+func Sync(ctx context.Context, db *gorm.DB, table string, ids []string) error {
+	indexer, ok := engine.(Indexer)
+	if !ok || len(ids) == 0 {
+		return nil
+	}
+	tx, s, err := modelDB(ctx, db, table)
+	if err != nil {
+		return err
+	}
+
+	list := reflect.New(reflect.SliceOf(s.ModelType))
+	err = tx.Where(clause.IN{Column: clause.Column{Name: s.PrioritizedPrimaryField.DBName}, Values: toValues(ids)}).
+		Find(list.Interface()).Error
+	if err != nil {
+		return err
+	}
+	docs, err := documents(ctx, s, list.Elem())
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]struct{}, len(docs))
+	for _, doc := range docs {
+		exists[doc.ID] = struct{}{}
+	}
+	var deleted []string
+	for _, id := range ids {
+		if _, ok := exists[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+
+	index := indexName(ctx, table)
+	if len(docs) > 0 {
+		if err = indexer.Index(ctx, index, docs); err != nil {
+			return err
+		}
+	}
+	if len(deleted) > 0 {
+		return indexer.Delete(ctx, index, deleted)
+	}
+	return nil
+}
+
+// Reindex 全量同步一张表，每批 batchSize 条，返回同步的条数
+func Reindex(ctx context.Context, db *gorm.DB, table string, batchSize int) (int, error) {
+	indexer, ok := engine.(Indexer)
+	if !ok {
+		return 0, nil
+	}
+	tx, s, err := modelDB(ctx, db, table)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	index := indexName(ctx, table)
+	list := reflect.New(reflect.SliceOf(s.ModelType))
+	err = tx.FindInBatches(list.Interface(), batchSize, func(batch *gorm.DB, _ int) error {
+		docs, err := documents(ctx, s, list.Elem())
+		if err != nil {
+			return err
+		}
+		count += len(docs)
+		return indexer.Index(ctx, index, docs)
+	}).Error
+	return count, err
+}
+
+func modelDB(ctx context.Context, db *gorm.DB, table string) (*gorm.DB, *schema.Schema, error) {
+	modelType, ok := models[table]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotRegistered, table)
+	}
+	tx := db.WithContext(ctx).Model(reflect.New(modelType).Interface())
+	if err := tx.Statement.Parse(tx.Statement.Model); err != nil {
+		return nil, nil, err
+	}
+	if tx.Statement.Schema.PrioritizedPrimaryField == nil {
+		return nil, nil, gorm.ErrPrimaryKeyRequired
+	}
+	return tx, tx.Statement.Schema, nil
+}
+
+// documents 模型转换为搜索引擎的数据，只包含搜索字段
+func documents(ctx context.Context, s *schema.Schema, list reflect.Value) ([]Document, error) {
+	fields, _, err := searchFields(s)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Document, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		row := list.Index(i)
+		id, _ := s.PrioritizedPrimaryField.ValueOf(ctx, row)
+		doc := Document{ID: fmt.Sprint(id), Fields: make(map[string]interface{}, len(fields))}
+		for _, field := range fields {
+			doc.Fields[jsonName(field)], _ = field.ValueOf(ctx, row)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func toValues(ids []string) []interface{} {
+	values := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id)
+	}
+	return values
+}
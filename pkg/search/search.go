@@ -0,0 +1,190 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrInvalidSearch = errors.New("invalid search")
+	ErrNotRegistered = errors.New("search model not registered")
+)
+
+// Searchable 声明模型允许关键词搜索的字段(json名称)
+type Searchable interface {
+	SearchFields() []string
+}
+
+// Query 关键词搜索
+type Query struct {
+	Index      string   // 索引名，见 SetIndexName
+	PrimaryKey string   // 主键列名
+	Columns    []string // 搜索字段的列名
+	Keyword    string
+}
+
+// IDs 外部搜索引擎返回的主键作为查询条件
+func (q Query) IDs(ids []string) clause.Expression {
+	if len(ids) == 0 {
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	return clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: q.PrimaryKey}, Values: toValues(ids)}
+}
+
+// Engine 搜索引擎，返回关键词搜索的查询条件
+type Engine interface {
+	Match(ctx context.Context, q Query) (clause.Expression, error)
+}
+
+// Document 同步到搜索引擎的一条数据，Fields 的 key 为json名称
+type Document struct {
+	ID     string
+	Fields map[string]interface{}
+}
+
+// Indexer 需要同步数据的外部搜索引擎同时实现该接口
+// 数据变更后通过异步任务同步(见 gorm_plugin.SearchPlugin)，全量同步使用 cmd/search
+type Indexer interface {
+	Index(ctx context.Context, index string, docs []Document) error
+	Delete(ctx context.Context, index string, ids []string) error
+}
+
+var (
+	engine    Engine
+	models    = map[string]reflect.Type{} // 表名 -> 模型
+	indexName = func(ctx context.Context, table string) string { return table }
+)
+
+// SetEngine 设置搜索引擎，没有设置时 mysql 使用 FULLTEXT 索引，其他数据库使用 LIKE
+func SetEngine(e Engine) {
+	engine = e
+}
+
+// SetIndexName 设置 ctx 中的数据使用的索引名，默认为表名
+// 不同数据库的主键会重复，租户有独立数据库时需要使用租户自己的索引
+func SetIndexName(fn func(ctx context.Context, table string) string) {
+	indexName = fn
+}
+
+// Indexing 当前的搜索引擎是否需要同步数据
+func Indexing() bool {
+	_, ok := engine.(Indexer)
+	return ok
+}
+
+// engineOf 当前使用的搜索引擎
+func engineOf(db *gorm.DB) Engine {
+	if engine != nil {
+		return engine
+	}
+	if db.Dialector.Name() == "mysql" {
+		return FullText{}
+	}
+	return Like{}
+}
+
+// Register 注册需要同步到搜索引擎的模型，模型需要实现 Searchable 和 TableName()
+func Register(model interface{}) {
+	tabler, ok := model.(interface{ TableName() string })
+	if !ok {
+		panic("search model must implement TableName()")
+	}
+	if _, ok = model.(Searchable); !ok {
+		panic("search model " + tabler.TableName() + " must implement Searchable")
+	}
+	models[tabler.TableName()] = reflect.Indirect(reflect.ValueOf(model)).Type()
+}
+
+// Tables 注册的表名
+func Tables() []string {
+	tables := make([]string, 0, len(models))
+	for table := range models {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// Scope 关键词搜索，模型没有实现 Searchable 或者关键词为空时不做处理
+func Scope(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			return db
+		}
+		if err := db.Statement.Parse(db.Statement.Model); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		s := db.Statement.Schema
+		fields, ok, err := searchFields(s)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if !ok || s.PrioritizedPrimaryField == nil {
+			return db
+		}
+
+		q := Query{Index: indexName(db.Statement.Context, s.Table), PrimaryKey: s.PrioritizedPrimaryField.DBName, Keyword: keyword}
+		for _, field := range fields {
+			q.Columns = append(q.Columns, field.DBName)
+		}
+		expr, err := engineOf(db).Match(db.Statement.Context, q)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(expr)
+	}
+}
+
+var searchFieldsCache sync.Map // reflect.Type -> []*schema.Field
+
+// searchFields 模型声明的搜索字段，没有实现 Searchable 时返回false
+func searchFields(s *schema.Schema) ([]*schema.Field, bool, error) {
+	if v, ok := searchFieldsCache.Load(s.ModelType); ok {
+		fields := v.([]*schema.Field)
+		return fields, fields != nil, nil
+	}
+
+	searchable, ok := reflect.New(s.ModelType).Interface().(Searchable)
+	if !ok {
+		searchFieldsCache.Store(s.ModelType, []*schema.Field(nil))
+		return nil, false, nil
+	}
+	byName := make(map[string]*schema.Field, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName != "" {
+			byName[jsonName(field)] = field
+		}
+	}
+	fields := make([]*schema.Field, 0)
+	for _, name := range searchable.SearchFields() {
+		field, ok := byName[name]
+		if !ok {
+			return nil, false, fmt.Errorf("%w: %s has no field %q", ErrInvalidSearch, s.Name, name)
+		}
+		fields = append(fields, field)
+	}
+	searchFieldsCache.Store(s.ModelType, fields)
+	return fields, true, nil
+}
+
+// jsonName 字段的json名称，没有json标签时使用字段名
+func jsonName(field *schema.Field) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type searchArticle struct {
+	ID        uint           `json:"id"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	Views     int            `json:"views"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+}
+
+func (searchArticle) TableName() string {
+	return "search_articles"
+}
+
+func (searchArticle) SearchFields() []string {
+	return []string{"title", "body"}
+}
+
+type searchComment struct {
+	ID   uint
+	Body string
+}
+
+// memoryEngine 外部搜索引擎，按标题完全匹配
+type memoryEngine struct {
+	docs map[string]map[string]Document // 索引 -> 主键 -> 数据
+}
+
+func (e *memoryEngine) Match(ctx context.Context, q Query) (clause.Expression, error) {
+	var ids []string
+	for id, doc := range e.docs[q.Index] {
+		if doc.Fields["title"] == q.Keyword {
+			ids = append(ids, id)
+		}
+	}
+	return q.IDs(ids), nil
+}
+
+func (e *memoryEngine) Index(ctx context.Context, index string, docs []Document) error {
+	if e.docs[index] == nil {
+		e.docs[index] = map[string]Document{}
+	}
+	for _, doc := range docs {
+		e.docs[index][doc.ID] = doc
+	}
+	return nil
+}
+
+func (e *memoryEngine) Delete(ctx context.Context, index string, ids []string) error {
+	for _, id := range ids {
+		delete(e.docs[index], id)
+	}
+	return nil
+}
+
+func newSearchDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	assert.NoError(t, db.AutoMigrate(&searchArticle{}, &searchComment{}))
+	assert.NoError(t, db.Create(&[]searchArticle{
+		{Title: "golang gin", Body: "web"},
+		{Title: "100% rust", Body: "axum"},
+		{Title: "python", Body: "go_lang"},
+	}).Error)
+	return db
+}
+
+func TestScope(t *testing.T) {
+	db := newSearchDB(t)
+	find := func(keyword string) []uint {
+		var ids []uint
+		assert.NoError(t, db.Model(&searchArticle{}).Scopes(Scope(keyword)).Order("id").Pluck("id", &ids).Error)
+		return ids
+	}
+
+	// 没有设置搜索引擎时 sqlite 使用 LIKE
+	assert.Equal(t, []uint{1, 2, 3}, find(" "))
+	assert.Equal(t, []uint{1}, find("gin"))
+	assert.Equal(t, []uint{3}, find("go_"))
+	assert.Equal(t, []uint{2}, find("0%"))
+	assert.Empty(t, find("n!"))
+
+	// 没有声明搜索字段时忽略关键词
+	var count int64
+	assert.NoError(t, db.Model(&searchComment{}).Scopes(Scope("x")).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	dry := db.Session(&gorm.Session{DryRun: true})
+	SetEngine(FullText{})
+	defer SetEngine(nil)
+	stmt := dry.Model(&searchArticle{}).Scopes(Scope(`+go -"rust" gin*`)).Find(&[]searchArticle{}).Statement
+	assert.Equal(t, "SELECT * FROM `search_articles` WHERE MATCH (`search_articles`.`title`,`search_articles`.`body`) AGAINST (? IN BOOLEAN MODE) AND `search_articles`.`deleted_at` IS NULL", stmt.SQL.String())
+	assert.Equal(t, []interface{}{"+go +rust +gin"}, stmt.Vars)
+}
+
+func TestSync(t *testing.T) {
+	db := newSearchDB(t)
+	ctx := context.Background()
+	engine := &memoryEngine{docs: map[string]map[string]Document{}}
+	SetEngine(engine)
+	defer SetEngine(nil)
+	Register(&searchArticle{})
+	assert.Equal(t, []string{"search_articles"}, Tables())
+
+	count, err := Reindex(ctx, db, "search_articles", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, map[string]interface{}{"title": "python", "body": "go_lang"}, engine.docs["search_articles"]["3"].Fields)
+
+	var ids []uint
+	assert.NoError(t, db.Model(&searchArticle{}).Scopes(Scope("python")).Pluck("id", &ids).Error)
+	assert.Equal(t, []uint{3}, ids)
+	assert.NoError(t, db.Model(&searchArticle{}).Scopes(Scope("java")).Pluck("id", &ids).Error)
+	assert.Empty(t, ids)
+
+	// 修改的数据重新写入，删除的数据从搜索引擎删除
+	assert.NoError(t, db.Model(&searchArticle{ID: 1}).Update("title", "gin").Error)
+	assert.NoError(t, db.Delete(&searchArticle{ID: 2}).Error)
+	assert.NoError(t, Sync(ctx, db, "search_articles", []string{"1", "2"}))
+	keys := make([]string, 0, len(engine.docs["search_articles"]))
+	for id := range engine.docs["search_articles"] {
+		keys = append(keys, id)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"1", "3"}, keys)
+	assert.Equal(t, "gin", engine.docs["search_articles"]["1"].Fields["title"])
+
+	assert.ErrorIs(t, Sync(ctx, db, "search_comments", []string{"1"}), ErrNotRegistered)
+}
+
+type contextIndexKey struct{}
+
+func TestSetIndexName(t *testing.T) {
+	db := newSearchDB(t)
+	other := newSearchDB(t)
+	engine := &memoryEngine{docs: map[string]map[string]Document{}}
+	SetEngine(engine)
+	defer SetEngine(nil)
+	SetIndexName(func(ctx context.Context, table string) string {
+		if suffix, ok := ctx.Value(contextIndexKey{}).(string); ok {
+			return table + "_" + suffix
+		}
+		return table
+	})
+	defer SetIndexName(func(ctx context.Context, table string) string { return table })
+	Register(&searchArticle{})
+
+	// 两个数据库的主键相同，分别写入自己的索引
+	ctx := context.Background()
+	otherCtx := context.WithValue(ctx, contextIndexKey{}, "other")
+	assert.NoError(t, other.Model(&searchArticle{ID: 1}).Update("title", "java").Error)
+	assert.NoError(t, other.Delete(&searchArticle{ID: 3}).Error)
+	_, err := Reindex(ctx, db, "search_articles", 10)
+	assert.NoError(t, err)
+	assert.NoError(t, Sync(otherCtx, other, "search_articles", []string{"1", "3"}))
+	assert.Equal(t, "golang gin", engine.docs["search_articles"]["1"].Fields["title"])
+	assert.Equal(t, "java", engine.docs["search_articles_other"]["1"].Fields["title"])
+	assert.Contains(t, engine.docs["search_articles"], "3")
+
+	var ids []uint
+	assert.NoError(t, other.WithContext(otherCtx).Model(&searchArticle{}).Scopes(Scope("java")).Pluck("id", &ids).Error)
+	assert.Equal(t, []uint{1}, ids)
+	assert.NoError(t, db.WithContext(ctx).Model(&searchArticle{}).Scopes(Scope("java")).Pluck("id", &ids).Error)
+	assert.Empty(t, ids)
+}
@@ -7,10 +7,7 @@ import (
 	"io"
 	"log"
 	"reflect"
-	"regexp"
-	"strconv"
 	"strings"
-	"time"
 )
 
 // head，指定了此结构体字段对应的 Excel 列名。
@@ -19,62 +16,90 @@ import (
 // required，表示此字段必须包含非零值，否则在写入 Excel 时会报错。
 // omitempty，表示此字段如果是零值，则对应的单元格留空。
 // color，指定了列名所在单元格的颜色，通过这个字段，可以为不同的列名设置不同的底色，赋予一些含义，例如，可以将必填的列和选填的列，设置不同的底色。可以通过 Excel 的 RGB 颜色设置窗口，查看不同颜色对应的色号，作为 color 属性的值。
+// format，时间的格式(Go 的时间格式)或 bool 的文本(真,假)。
+// inline，结构体字段展开为多列，head 为列名的前缀。
+// sheet，切片字段写入指定的子工作表，子工作表第一列为关联的上级 key 列(默认第一列)。
+// key，子工作表关联的列。
+//
+// 选项之间用 ; 分隔，只有列名时可以省略 head，例如
+//
+//	Name   string           `excel:"姓名"`
+//	Phone  string           `excel:"head:手机号;type:string;required;color:#FFFF00"`
+//	Status string           `excel:"head:状态;select:active=正常,disabled=禁用"`
+//	Birth  *model.LocalTime `excel:"head:生日;type:date;omitempty"`
+//	Admin  bool             `excel:"head:管理员;type:bool;format:Y,N"`
+//	Addr   Address          `excel:"head:地址-;inline"`      // 地址-省份、地址-城市
+//	Items  []Item           `excel:"sheet:明细"`             // 写入 明细 工作表
+//
+// type 支持 string(按文本写入)、date、datetime、time、bool，时间字段默认为 datetime
+// select 的选项为 值=文本 时为枚举，写入时值转换为文本，解析时文本转换为值
 // 字段的解析结果
 type Setting struct {
 	Head      string
 	Type      string
-	Select    []string
+	Format    string
+	Select    []string          // 下拉列表的选项，枚举时为文本
+	Enum      map[string]string // 枚举的值与文本
 	Required  bool
 	OmitEmpty bool
 	Color     string
+	Inline    bool
+	Sheet     string
+	Key       bool
 }
 
-// 解析data中带ex的tag的字段，返回解析后的setting列表
+// 解析data中带ex的tag的字段，返回解析后的setting列表，嵌入的结构体会递归解析
 // param interface{}结构体指针
 func ParseExcelTag(data interface{}) []Setting {
-	var (
-		setting     Setting
-		settingList []Setting
-	)
-	tp := reflect.ValueOf(data).Type().Elem() // 获得结构体的反射Type
-	numField := tp.NumField()                 // 获取结构体的字段数量
-
-	for i := 0; i < numField; i++ {
-		field := tp.Field(i)          // 获取字段
-		tag := field.Tag.Get("excel") // 获取tag中的ex值
-		if tag == "" {
-			continue
-		}
-		//s := parseFieldTag(setting, tag)
-		setting.Head = tag
-		settingList = append(settingList, setting)
+	var settingList []Setting
+	for _, f := range parseFields(reflect.TypeOf(data)) {
+		settingList = append(settingList, f.setting)
 	}
 	return settingList
 }
 
 // 解析tag到setting里面，返回setting
+// 不是 key:value 格式、也不是 required、omitempty、inline、key 的选项作为列名
 func parseFieldTag(s Setting, tag string) Setting {
-	re := regexp.MustCompile(`(\w+):([^;]+)(;|$)`)
-	attrs := re.FindAllStringSubmatch(tag, -1)
-
-	for _, attr := range attrs {
-		key := attr[1]
-		value := attr[2]
-
+	for _, attr := range strings.Split(tag, ";") {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(attr, ":")
 		switch key {
 		case "head":
 			s.Head = value
 		case "type":
 			s.Type = value
+		case "format":
+			s.Format = value
 		case "required":
 			s.Required = true
 		case "omitempty":
 			s.OmitEmpty = true
 		case "color":
 			s.Color = value
+		case "inline":
+			s.Inline = true
+		case "sheet":
+			s.Sheet = value
+		case "key":
+			s.Key = true
 		case "select":
-			items := strings.Split(value, ",")
-			s.Select = append(s.Select, items...)
+			for _, item := range strings.Split(value, ",") {
+				val, label, ok := strings.Cut(item, "=")
+				if ok {
+					if s.Enum == nil {
+						s.Enum = make(map[string]string)
+					}
+					s.Enum[val] = label
+					item = label
+				}
+				s.Select = append(s.Select, item)
+			}
+		default:
+			s.Head = attr
 		}
 	}
 
@@ -97,6 +122,27 @@ func WriteHead(f *excelize.File, data interface{}, dataValidation map[string][]s
 	}
 	axis, err := excelize.CoordinatesToCellName(1, 1)
 	err = f.SetSheetRow("Sheet1", axis, &row)
+	if err != nil {
+		return err
+	}
+
+	// 标签中的 color 和 select
+	styles, err := headStyles(f, settingSlice)
+	if err != nil {
+		return err
+	}
+	for i, style := range styles {
+		if style == 0 {
+			continue
+		}
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		if err = f.SetCellStyle("Sheet1", cell, cell, style); err != nil {
+			return err
+		}
+	}
+	if err = addSelects(f, "Sheet1", settingSlice, 2); err != nil {
+		return err
+	}
 
 	for s := range dataValidation {
 		// 创建下拉选项列表
@@ -111,7 +157,7 @@ func WriteHead(f *excelize.File, data interface{}, dataValidation map[string][]s
 	return err
 }
 
-// 写入第一行标题数据
+// 写入第一行标题数据，流式写入无法设置样式和下拉列表，需要 color、select 时使用 ExcelTool
 // params: *excelize.StreamWriter流写入 interface{}结构体指针
 func StreamWriteHead(sw *excelize.StreamWriter, data interface{}) error {
 	settingSlice := ParseExcelTag(data)
@@ -130,55 +176,36 @@ func StreamWriteHead(sw *excelize.StreamWriter, data interface{}) error {
 	return sw.SetRow(axis, rows, excelize.RowOpts{Height: 16})
 }
 
-// 写入除了标题行的内容数据，按结构体属性顺序写入
+// 写入除了标题行的内容数据，按结构体中有 excel 标签的字段顺序写入
 // params: *excelize.StreamWriterexcel流式写入 interface{}切片结构体指针数据集
 func StreamWriteBody(sw *excelize.StreamWriter, d interface{}) error {
 	// 判断d的数据类型
 	switch reflect.TypeOf(d).Kind() {
 	case reflect.Slice, reflect.Array:
-		// 是切片或者数组
-		values := reflect.ValueOf(d)
-		// 创建一个二维数组的数据集，用来存放最终数据集
-		data := make([][]interface{}, values.Len())
-		for i := 0; i < values.Len(); i++ {
-			// 取出切片中的每个结构体，利用反射获取值
-			record := values.Index(i).Elem()
-			if record.Kind() == reflect.Struct {
-				// 创建一个切片来表示一行数据
-				row := make([]interface{}, record.NumField())
-				for j := 0; j < record.NumField(); j++ {
-					// 遍历结构体中的字段，取出字段值
-					field := record.Field(j)
-					row[j] = excelize.Cell{
-						StyleID: 0,
-						Formula: "",
-						Value:   field.Interface(),
-					}
-				}
-				// 将每一行数据保存到二维数组中
-				data[i] = row
-			}
-		}
-
-		row := 2
-		for i := range data {
-			// 逐行插入数据 将数据写入excel
-			// 数据都是从列号1开始；行号从2开始，因为第一行为标题行
-			axis, err := excelize.CoordinatesToCellName(1, row)
-			if err != nil {
-				return err
-			}
-			if err := sw.SetRow(axis, data[i], excelize.RowOpts{Height: 16}); err != nil {
-				return err
-			}
-			row++
-		}
 	default:
 		// 不支持改数据类型
 		return errors.New("resolution of this data type is not supported")
 	}
-
-	return nil
+	fields := parseFields(reflect.TypeOf(d).Elem())
+	formatBool := map[bool]string{true: "是", false: "否"}
+	// 数据都是从列号1开始；行号从2开始，因为第一行为标题行
+	row := 2
+	return eachRecord(d, func(record reflect.Value) error {
+		cells, err := recordCells(record, fields, formatBool)
+		if err != nil {
+			return fmt.Errorf("row %d, %w", row, err)
+		}
+		// 逐行插入数据 将数据写入excel
+		axis, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(axis, cells, excelize.RowOpts{Height: 16}); err != nil {
+			return err
+		}
+		row++
+		return nil
+	})
 }
 
 // StreamWriterAllRows 流式写出数据集，合并单元格，自定义样式
@@ -207,7 +234,9 @@ func StreamWriterAllRows(sw *excelize.StreamWriter, content [][]interface{}, mer
 }
 
 // ParseExcelToSlice 解析excel中全部数据，返回[]T，与字段标签excel匹配的标题 列的内容为字段值
-// 目前支持类型string int int32 int64 *int *int32 *int64 time.time *time.Time
+// 支持 string、整数、浮点数、bool、time.Time、model.LocalTime 及其指针，按标签中的 type、format、select 转换
+// required 的列不存在或单元格为空时返回 ErrRequired，空行会被忽略
+// 解析模型 SheetName 的工作表，没有时解析第一个工作表，多个工作表见 ParseSheet
 //
 //	type TestStruct struct {
 //		Name      string     `excel:"姓名"`
@@ -228,158 +257,71 @@ func ParseExcelToSlice[T any](file io.Reader) ([]T, error) {
 	}
 	defer f.Close()
 
-	var slice []T
-	var headerMap map[string]int // 列标题与索引的映射
-
-	// 获取第一个工作表
-	sheets := f.GetSheetList()
-	if len(sheets) == 0 {
-		return nil, errors.New("no sheets found in excel file")
-	}
-	sheet := sheets[0]
-
-	// 获取所有行
-	rows, err := f.GetRows(sheet)
-	if err != nil {
-		return nil, fmt.Errorf("get rows failed: %w", err)
-	}
-
-	if len(rows) < 2 {
-		return nil, errors.New("excel file is empty or has no data rows")
-	}
+	return ParseSheet[T](f, "")
+}
 
-	// 处理标题行
-	headerMap = make(map[string]int)
-	for j, colCell := range rows[0] {
-		headerMap[colCell] = j
+// headerColumns 字段对应的列索引，没有该列时为-1，required 的列必须存在
+func headerColumns(header []string, fields []field) ([]int, error) {
+	headerMap := make(map[string]int) // 列标题与索引的映射
+	for j, colCell := range header {
+		headerMap[strings.TrimSpace(colCell)] = j
 	}
-
-	// 预分配切片容量
-	slice = make([]T, 0, len(rows)-1)
-
-	// 创建结构体类型信息缓存
-	var structType T
-	val := reflect.ValueOf(&structType).Elem()
-	tp := val.Type()
-
-	// 创建字段映射缓存
-	fieldMap := make(map[string]struct {
-		field     reflect.Value
-		fieldType reflect.Type
-		index     int
-	})
-
-	// 缓存字段信息
-	for k := 0; k < val.NumField(); k++ {
-		field := val.Field(k)
-		structField := tp.Field(k)
-		excelTag := structField.Tag.Get("excel")
-		if excelTag != "" {
-			fieldMap[excelTag] = struct {
-				field     reflect.Value
-				fieldType reflect.Type
-				index     int
-			}{
-				field:     field,
-				fieldType: field.Type(),
-				index:     k,
+	columns := make([]int, len(fields))
+	for k, f := range fields {
+		colIndex, ok := headerMap[f.setting.Head]
+		if !ok {
+			if f.setting.Required {
+				return nil, fmt.Errorf("column %s: %w", f.setting.Head, ErrRequired)
 			}
+			colIndex = -1
 		}
+		columns[k] = colIndex
 	}
+	return columns, nil
+}
 
-	// 处理数据行
-	for i, row := range rows[1:] {
-		// 创建新的结构体实例
-		newVal := reflect.New(tp).Elem()
-
-		// 处理每一列
-		for tag, fieldInfo := range fieldMap {
-			colIndex, ok := headerMap[tag]
-			if !ok {
-				continue
-			}
-
-			// 检查列索引是否有效
-			if colIndex >= len(row) {
-				continue
-			}
-
-			cellValue := row[colIndex]
-			if cellValue == "" {
-				continue
-			}
-
-			// 设置字段值
-			if err := setFieldValue(newVal.Field(fieldInfo.index), cellValue); err != nil {
-				return nil, fmt.Errorf("row %d, column %s: %w", i+2, tag, err)
+// setRecord 解析一行数据到结构体
+func setRecord(record reflect.Value, fields []field, columns []int, row []string) error {
+	for k, f := range fields {
+		var cellValue string
+		if colIndex := columns[k]; colIndex >= 0 && colIndex < len(row) {
+			cellValue = strings.TrimSpace(row[colIndex])
+		}
+		if cellValue == "" {
+			if f.setting.Required {
+				return fmt.Errorf("column %s: %w", f.setting.Head, ErrRequired)
 			}
+			continue
 		}
 
-		// 检查结构体是否为空
-		if !reflect.ValueOf(newVal.Interface()).IsZero() {
-			slice = append(slice, newVal.Interface().(T))
+		// 设置字段值
+		if err := f.setting.setValue(fieldByIndex(record, f.index), cellValue); err != nil {
+			return fmt.Errorf("column %s: %w", f.setting.Head, err)
 		}
 	}
-
-	return slice, nil
+	return nil
 }
 
-// setFieldValue 设置字段值，支持多种类型转换
-func setFieldValue(field reflect.Value, value string) error {
-	// 处理指针类型
-	if field.Kind() == reflect.Ptr {
-		if field.IsNil() {
-			field.Set(reflect.New(field.Type().Elem()))
+// fieldByIndex 按索引获取字段，嵌入的结构体指针为空时创建
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
 		}
-		field = field.Elem()
-	}
-
-	// 处理空值
-	if value == "" {
-		return nil
+		v = v.Field(x)
 	}
+	return v
+}
 
-	// 根据字段类型进行转换
-	switch field.Kind() {
-	case reflect.String:
-		field.SetString(value)
-	case reflect.Int, reflect.Int32, reflect.Int64:
-		intVal, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid integer value: %w", err)
-		}
-		field.SetInt(intVal)
-	case reflect.Struct:
-		// 处理 time.Time 类型
-		if field.Type().String() == "time.Time" {
-			// 尝试多种时间格式
-			formats := []string{
-				"2006-01-02 15:04:05.999999999 -0700 MST",
-				"2006-01-02 15:04:05.999999999 -0700",
-				"2006-01-02 15:04:05.999999999",
-				"2006-01-02 15:04:05",
-				"2006-01-02",
-				time.RFC3339,
-				time.RFC3339Nano,
-			}
-			var t time.Time
-			var err error
-			for _, format := range formats {
-				t, err = time.Parse(format, value)
-				if err == nil {
-					break
-				}
-			}
-			if err != nil {
-				return fmt.Errorf("invalid time format: %w", err)
-			}
-			field.Set(reflect.ValueOf(t))
-			return nil
+// isBlankRow 所有单元格都为空
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
 		}
-		return fmt.Errorf("unsupported struct type: %v", field.Type())
-	default:
-		return fmt.Errorf("unsupported field type: %v", field.Kind())
 	}
-
-	return nil
+	return true
 }
@@ -1,10 +1,13 @@
 package excel
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/stretchr/testify/assert"
 	"github.com/xuri/excelize/v2"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -39,7 +42,7 @@ func TestStreamWrite(t *testing.T) {
 	// 流式必须在结束使用Flush()
 	sw.Flush()
 
-	err := ef.SaveAs("stream_info.xlsx")
+	err := ef.SaveAs(filepath.Join(t.TempDir(), "stream_info.xlsx"))
 	if err != nil {
 		fmt.Println(err.Error())
 	}
@@ -63,7 +66,7 @@ func Test2(t *testing.T) {
 		return
 	}
 	err = f.SetCellStyle("Sheet1", "A6", "A6", style)
-	err = f.SaveAs("stream_info.xlsx")
+	err = f.SaveAs(filepath.Join(t.TempDir(), "stream_info.xlsx"))
 	if err != nil {
 		fmt.Println(err.Error())
 	}
@@ -174,7 +177,7 @@ func TestStreamWriterAllRows(t *testing.T) {
 
 	sw.Flush()
 	// 保存 Excel 文件
-	err = ef.SaveAs("output2.xlsx")
+	err = ef.SaveAs(filepath.Join(t.TempDir(), "output2.xlsx"))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -203,3 +206,90 @@ func TestParseExcelToSlice(t *testing.T) {
 		fmt.Println(testStruct)
 	}
 }
+
+type tagStruct struct {
+	Name     string     `excel:"head:姓名;required;color:#FFFF00"`
+	Phone    string     `excel:"手机号;type:string"`
+	Status   string     `excel:"head:状态;select:active=正常,disabled=禁用"`
+	Gender   string     `excel:"head:性别;select:男,女"`
+	Birthday *time.Time `excel:"head:生日;type:date;omitempty"`
+	Admin    bool       `excel:"head:管理员;type:bool;format:Y,N"`
+	Age      int        `excel:"head:年龄;omitempty"`
+}
+
+func TestParseFieldTag(t *testing.T) {
+	assert.Equal(t, Setting{Head: "姓名"}, parseFieldTag(Setting{}, "姓名"))
+	assert.Equal(t, Setting{Head: "姓名", Required: true, Color: "#FFFF00"}, parseFieldTag(Setting{}, "head:姓名;required;color:#FFFF00"))
+	assert.Equal(t, Setting{Head: "生日", Type: "date", Format: "2006/01/02", OmitEmpty: true}, parseFieldTag(Setting{}, "生日;type:date;format:2006/01/02;omitempty"))
+	assert.Equal(t, Setting{
+		Head:   "状态",
+		Select: []string{"正常", "禁用"},
+		Enum:   map[string]string{"active": "正常", "disabled": "禁用"},
+	}, parseFieldTag(Setting{}, "head:状态;select:active=正常,disabled=禁用"))
+}
+
+func TestParseExcelToSlice_Tag(t *testing.T) {
+	birthday := time.Date(2000, 1, 20, 0, 0, 0, 0, time.UTC)
+	tool := NewExcelTool("Sheet1").Model(&tagStruct{})
+	assert.NoError(t, tool.WriteRows([]tagStruct{
+		{Name: "张三", Phone: "13800000000", Status: "disabled", Gender: "男", Birthday: &birthday, Admin: true},
+		{Name: "李四", Status: "active"},
+	}))
+	assert.NoError(t, tool.Flush())
+	buf, err := tool.WriteToBuffer()
+	assert.NoError(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	rows, err := f.GetRows("Sheet1")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"姓名", "手机号", "状态", "性别", "生日", "管理员", "年龄"},
+		{"张三", "13800000000", "禁用", "男", "2000-01-20", "Y"},
+		{"李四", "", "正常", "", "", "N"},
+	}, rows)
+	style, err := f.GetCellStyle("Sheet1", "A1")
+	assert.NoError(t, err)
+	assert.NotZero(t, style)
+	dvs, err := f.GetDataValidations("Sheet1")
+	assert.NoError(t, err)
+	assert.Len(t, dvs, 2)
+
+	list, err := ParseExcelToSlice[tagStruct](bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, []tagStruct{
+		{Name: "张三", Phone: "13800000000", Status: "disabled", Gender: "男", Birthday: &birthday, Admin: true},
+		{Name: "李四", Status: "active"},
+	}, list)
+
+	// 写入时必填
+	tool = NewExcelTool("Sheet1").Model(&tagStruct{})
+	assert.ErrorIs(t, tool.WriteRows([]tagStruct{{Phone: "1"}}), ErrRequired)
+}
+
+func TestParseExcelToSlice_Invalid(t *testing.T) {
+	write := func(rows ...[]interface{}) *bytes.Reader {
+		f := excelize.NewFile()
+		for i, row := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			assert.NoError(t, f.SetSheetRow("Sheet1", cell, &row))
+		}
+		buf, err := f.WriteToBuffer()
+		assert.NoError(t, err)
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	// 必填的列不存在
+	_, err := ParseExcelToSlice[tagStruct](write([]interface{}{"手机号"}, []interface{}{"1"}))
+	assert.ErrorIs(t, err, ErrRequired)
+	// 必填的单元格为空，空行忽略
+	_, err = ParseExcelToSlice[tagStruct](write([]interface{}{"姓名", "手机号"}, []interface{}{}, []interface{}{"", "1"}))
+	assert.ErrorIs(t, err, ErrRequired)
+	assert.ErrorContains(t, err, "row 3")
+	// 不在下拉列表中
+	_, err = ParseExcelToSlice[tagStruct](write([]interface{}{"姓名", "状态"}, []interface{}{"张三", "删除"}))
+	assert.ErrorContains(t, err, "invalid option")
+	// bool
+	_, err = ParseExcelToSlice[tagStruct](write([]interface{}{"姓名", "管理员"}, []interface{}{"张三", "maybe"}))
+	assert.ErrorContains(t, err, "invalid bool value")
+}
@@ -22,6 +22,10 @@ type ExcelTool struct {
 	remark              string            // 备注(A1单元格)
 	TagCol              map[string]string // 结构体标签对应的列
 	formatBool          map[bool]string   // bool格式化
+	row                 int               // 下一行的行号，写入标题后不为0
+	book                *workbook         // 同一文件中的工作表
+	link                string            // 子工作表第一列的列名，为关联的上级列
+	flushed             bool
 }
 
 func NewExcelTool(sheet string) *ExcelTool {
@@ -32,13 +36,16 @@ func NewExcelTool(sheet string) *ExcelTool {
 		return nil
 	}
 
-	return &ExcelTool{
+	e := &ExcelTool{
 		sheet:      sheet,
 		file:       file,
 		sw:         sw,
 		TagCol:     make(map[string]string),
 		formatBool: map[bool]string{true: "是", false: "否"},
+		book:       &workbook{},
 	}
+	e.book.sheets = append(e.book.sheets, e)
+	return e
 }
 
 func (e *ExcelTool) FormatBool(m map[bool]string) *ExcelTool {
@@ -82,12 +89,8 @@ func (e *ExcelTool) headToCols(heads ...string) []string {
 	for _, head := range heads {
 		headMap[head] = struct{}{}
 	}
-	tp := reflect.ValueOf(e.model).Type().Elem() // 获得结构体的反射Type
-	numField := tp.NumField()                    // 获取结构体的字段数量
-	for i := 0; i < numField; i++ {
-		field := tp.Field(i)          // 获取字段
-		tag := field.Tag.Get("excel") // 获取tag中的ex值
-		if _, ok := headMap[tag]; ok {
+	for i, f := range parseFields(reflect.TypeOf(e.model)) {
+		if _, ok := headMap[f.setting.Head]; ok {
 			cols = append(cols, indexToColumnName(i))
 		}
 	}
@@ -105,32 +108,76 @@ func indexToColumnName(index int) string {
 }
 
 func (e *ExcelTool) headToMergeConditionIndex(head string) int {
-	tp := reflect.ValueOf(e.model).Type().Elem() // 获得结构体的反射Type
-	numField := tp.NumField()                    // 获取结构体的字段数量
-	for i := 0; i < numField; i++ {
-		field := tp.Field(i)          // 获取字段
-		tag := field.Tag.Get("excel") // 获取tag中的ex值
-		if tag == head {
+	for i, f := range parseFields(reflect.TypeOf(e.model)) {
+		if f.setting.Head == head {
 			return i
 		}
 	}
 	return 0
 }
 
+// Flush 写入文件中全部工作表的数据，多个工作表时调用任意一个工作表的 Flush 即可
 func (e *ExcelTool) Flush() error {
-	if err := e.setRemark(); err != nil {
-		return err
+	// 先写入全部工作表的数据，子工作表的数据在上级工作表写入时写入
+	for _, s := range e.book.sheets {
+		if err := s.writeBody(); err != nil {
+			return err
+		}
 	}
-	if err := e.StreamWriteHead(e.sw, e.model); err != nil {
-		return err
+	for _, s := range e.book.sheets {
+		if s.flushed {
+			continue
+		}
+		if s.row == 0 {
+			if err := s.writeHead(); err != nil {
+				return err
+			}
+		}
+		if err := s.sw.Flush(); err != nil {
+			return err
+		}
+		s.flushed = true
+	}
+	return nil
+}
+
+// writeBody 写入 WriteBody 的数据
+func (e *ExcelTool) writeBody() error {
+	if e.flushed {
+		return nil
+	}
+	if e.row == 0 {
+		if err := e.writeHead(); err != nil {
+			return err
+		}
 	}
 	if e.list != nil {
 		err := e.StreamWriteBodyWithMerge(e.sw, e.list, e.mergeConditionIndex, e.mergeCols)
 		if err != nil {
 			return err
 		}
+		e.list = nil
+	}
+	return nil
+}
+
+// writeHead 写入备注和标题，并创建子工作表
+func (e *ExcelTool) writeHead() error {
+	if err := e.setRemark(); err != nil {
+		return err
+	}
+	e.row = 2
+	if e.remark != "" {
+		e.row++
+	}
+	if e.model == nil {
+		// 没有设置模型的工作表
+		return nil
+	}
+	if err := e.StreamWriteHead(e.sw, e.model); err != nil {
+		return err
 	}
-	return e.sw.Flush()
+	return e.createChildren()
 }
 
 func (e *ExcelTool) setRemark() error {
@@ -171,12 +218,26 @@ func (e *ExcelTool) StreamWriteHead(sw *excelize.StreamWriter, data interface{})
 	}
 
 	settingSlice := ParseExcelTag(data)
+	if e.link != "" {
+		// 子工作表第一列为关联的上级列
+		settingSlice = append([]Setting{{Head: e.link}}, settingSlice...)
+	}
+	// 标签中的 color 为标题的底色
+	styles, err := headStyles(e.file, settingSlice)
+	if err != nil {
+		return err
+	}
 	rows := make([]interface{}, len(settingSlice)) // 创建一个切片，表示一行数据
 	for i := range settingSlice {
 		rows[i] = excelize.Cell{
-			Value: settingSlice[i].Head,
+			StyleID: styles[i],
+			Value:   settingSlice[i].Head,
 		}
 	}
+	// 标签中的 select 为标题下方的下拉列表
+	if err = addSelects(e.file, e.sheet, settingSlice, row+1); err != nil {
+		return err
+	}
 	// 列名都是从列号1开始；行号从1开始
 	axis, err := excelize.CoordinatesToCellName(1, row)
 	if err != nil {
@@ -186,64 +247,32 @@ func (e *ExcelTool) StreamWriteHead(sw *excelize.StreamWriter, data interface{})
 	return sw.SetRow(axis, rows, excelize.RowOpts{Height: 16})
 }
 
-func (e *ExcelTool) formatValue(v reflect.Value) (any, error) {
-	// 处理指针类型
-	if v.Kind() == reflect.Ptr {
-		if v.IsNil() {
-			return nil, nil // 或返回 "", 0，视你的使用场景而定
-		}
-		v = v.Elem() // 解引用
-	}
-
-	switch v.Kind() {
-	case reflect.Bool:
-		if e.formatBool != nil {
-			return e.formatBool[v.Bool()], nil
-		}
-	default:
-		return v.Interface(), nil
-	}
-
-	return nil, errors.New("not support")
-}
-
 func (e *ExcelTool) StreamWriteBodyWithMerge(sw *excelize.StreamWriter, d interface{}, mergeConditionIndex int, mergeCols []string) error {
 	// 判断d的数据类型
 	switch reflect.TypeOf(d).Kind() {
 	case reflect.Slice, reflect.Array:
 		// 是切片或者数组
-		values := reflect.ValueOf(d)
-		// 创建一个二维数组的数据集，用来存放最终数据集
-		data := make([][]interface{}, values.Len())
-		for i := 0; i < values.Len(); i++ {
-			// 取出切片中的每个结构体，利用反射获取值
-			record := values.Index(i).Elem()
-			if record.Kind() == reflect.Struct {
-				// 创建一个切片来表示一行数据
-				row := make([]interface{}, record.NumField())
-				for j := 0; j < record.NumField(); j++ {
-					// 遍历结构体中的字段，取出字段值
-					field := record.Field(j)
-					cellValue, err := e.formatValue(field)
-					if err != nil {
-						return err
-					}
-					row[j] = excelize.Cell{
-						StyleID: 0,
-						Formula: "",
-						Value:   cellValue,
-					}
-				}
-				// 将每一行数据保存到二维数组中
-				data[i] = row
-			}
-		}
-
 		row := 2
 		if e.remark != "" {
 			row++
 		}
 
+		// 创建一个二维数组的数据集，用来存放最终数据集
+		var data [][]interface{}
+		err := eachRecord(d, func(record reflect.Value) error {
+			// 按字段的 excel 标签取出一行数据
+			cells, err := e.rowCells(record, nil)
+			if err != nil {
+				return fmt.Errorf("row %d, %w", row+len(data), err)
+			}
+			// 将每一行数据保存到二维数组中
+			data = append(data, cells)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
 		var mergeStarted bool
 		var mergeStartedRow, mergeEndedRow int
 		for i := range data {
@@ -289,14 +318,21 @@ func (e *ExcelTool) StreamWriteBodyWithMerge(sw *excelize.StreamWriter, d interf
 	return nil
 }
 
+// Model 设置工作表的模型，模型实现 Sheeter 时切换到模型的工作表，返回该工作表
 func (e *ExcelTool) Model(m interface{}) *ExcelTool {
+	if sheeter, ok := m.(Sheeter); ok && sheeter.SheetName() != e.sheet {
+		if s := e.Sheet(sheeter.SheetName()); s != nil {
+			return s.Model(m)
+		}
+	}
+
 	e.model = m
-	tp := reflect.ValueOf(e.model).Type().Elem() // 获得结构体的反射Type
-	numField := tp.NumField()                    // 获取结构体的字段数量
-	for i := 0; i < numField; i++ {
-		field := tp.Field(i)                 // 获取字段
-		tag := field.Tag.Get("excel")        // 获取tag中的ex值
-		e.TagCol[tag] = indexToColumnName(i) // 这是tag对应的列名
+	offset := 0
+	if e.link != "" {
+		offset++
+	}
+	for i, f := range parseFields(reflect.TypeOf(e.model)) {
+		e.TagCol[f.setting.Head] = indexToColumnName(i + offset) // 这是tag中的列名对应的列
 	}
 
 	return e
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/xuri/excelize/v2"
+	"path/filepath"
 	"testing"
 )
 
@@ -49,7 +50,7 @@ func TestExcelTool_SaveAs(t *testing.T) {
 			{Name: "张三", Age: 18},
 		}).
 		Flush()
-	err := tool.SaveAs("test.xlsx")
+	err := tool.SaveAs(filepath.Join(t.TempDir(), "test.xlsx"))
 	if err != nil {
 		t.Error(err)
 		return
@@ -70,7 +71,7 @@ func TestExcelTool_StreamWriteBodyWithMerge(t *testing.T) {
 		}).
 		MergeCols("id", "姓名").
 		Flush()
-	err := tool.SaveAs("test.xlsx")
+	err := tool.SaveAs(filepath.Join(t.TempDir(), "test.xlsx"))
 	if err != nil {
 		t.Error(err)
 		return
@@ -128,7 +129,7 @@ func TestExcelTool_SetDropList(t *testing.T) {
 	}
 
 	// 将文件流保存到磁盘
-	if err := ex.SaveAs("test.xlsx"); err != nil {
+	if err := ex.SaveAs(filepath.Join(t.TempDir(), "test.xlsx")); err != nil {
 		fmt.Println(err)
 		return
 	}
@@ -165,7 +166,7 @@ func TestExcelTool_SetDropListPro(t *testing.T) {
 	assert.Equal(t, nil, err)
 
 	// 将文件流保存到磁盘
-	if err := tool.SaveAs("test.xlsx"); err != nil {
+	if err := tool.SaveAs(filepath.Join(t.TempDir(), "test.xlsx")); err != nil {
 		fmt.Println(err)
 		return
 	}
@@ -187,7 +188,7 @@ func TestExcelTool_Remark(t *testing.T) {
 	assert.Equal(t, nil, err)
 
 	// 将文件流保存到磁盘
-	if err := tool.SaveAs("test.xlsx"); err != nil {
+	if err := tool.SaveAs(filepath.Join(t.TempDir(), "test.xlsx")); err != nil {
 		t.Log(err)
 		return
 	}
@@ -211,7 +212,7 @@ func TestExcelTool_FormatBool(t *testing.T) {
 	assert.Equal(t, nil, err)
 
 	// 将文件流保存到磁盘
-	if err := tool.SaveAs("test.xlsx"); err != nil {
+	if err := tool.SaveAs(filepath.Join(t.TempDir(), "test.xlsx")); err != nil {
 		t.Log(err)
 		return
 	}
@@ -0,0 +1,156 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrHeaderNotFound 工作表中没有与模型匹配的标题行
+var ErrHeaderNotFound = errors.New("header row not found")
+
+// Row 流式解析的一行数据
+type Row[T any] struct {
+	Num  int   // 行号，从1开始，与excel中的行号一致
+	Data T     // 解析的数据
+	Err  error // 该行的解析错误，例如必填项为空、格式错误，为空时 Data 有效
+}
+
+// RowReader 使用 excelize 的 Rows 流式解析工作表，逐行返回结构体，不会一次加载全部数据
+// 标题行为第一个包含模型列名的行，之前的备注等行会被跳过，空行会被跳过
+// 有 sheet 标签的切片字段不会解析，需要时使用 ParseSheet
+//
+//	r, err := excel.OpenRowReader[DemoExcelTpl]("./uploads/demo.xlsx", "")
+//	if err != nil {
+//		return err
+//	}
+//	defer r.Close()
+//	for r.Next() {
+//		row := r.Row()
+//		if row.Err != nil {
+//			// 记录第 row.Num 行的错误
+//			continue
+//		}
+//		// 处理 row.Data
+//	}
+//	return r.Error()
+type RowReader[T any] struct {
+	file    *excelize.File // OpenRowReader 打开的文件，Close 时关闭
+	rows    *excelize.Rows
+	fields  []field
+	columns []int
+	num     int
+	row     Row[T]
+	err     error
+}
+
+// OpenRowReader 打开文件并流式解析工作表，Close 时关闭文件
+// sheet 为空时使用模型的 SheetName，没有时使用第一个工作表
+func OpenRowReader[T any](name string, sheet string) (*RowReader[T], error) {
+	f, err := excelize.OpenFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("open excel file failed: %w", err)
+	}
+	r, err := NewRowReader[T](f, sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.file = f
+	return r, nil
+}
+
+// NewRowReader 流式解析已打开文件中的工作表，读取到标题行为止
+// sheet 为空时使用模型的 SheetName，没有时使用第一个工作表
+func NewRowReader[T any](f *excelize.File, sheet string) (*RowReader[T], error) {
+	var structType T
+	if sheet == "" {
+		sheet = sheetName(reflect.TypeOf(structType))
+	}
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("no sheets found in excel file")
+		}
+		sheet = sheets[0]
+	}
+
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("get rows failed: %w", err)
+	}
+	r := &RowReader[T]{rows: rows, fields: parseFields(reflect.TypeOf(structType))}
+	if err = r.readHeader(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// readHeader 读取到第一个包含模型列名的行作为标题行
+func (r *RowReader[T]) readHeader() error {
+	for r.rows.Next() {
+		r.num++
+		header, err := r.rows.Columns()
+		if err != nil {
+			return fmt.Errorf("row %d: %w", r.num, err)
+		}
+		if !isHeader(header, r.fields) {
+			continue
+		}
+		r.columns, err = headerColumns(header, r.fields)
+		return err
+	}
+	if err := r.rows.Error(); err != nil {
+		return err
+	}
+	return ErrHeaderNotFound
+}
+
+// Next 读取下一个非空行，没有更多数据或读取失败时返回 false，读取失败见 Error
+func (r *RowReader[T]) Next() bool {
+	for r.err == nil && r.rows.Next() {
+		r.num++
+		columns, err := r.rows.Columns()
+		if err != nil {
+			r.err = fmt.Errorf("row %d: %w", r.num, err)
+			return false
+		}
+		if isBlankRow(columns) {
+			continue
+		}
+
+		r.row = Row[T]{Num: r.num}
+		if err = setRecord(reflect.ValueOf(&r.row.Data).Elem(), r.fields, r.columns, columns); err != nil {
+			r.row.Err = err
+		}
+		return true
+	}
+	if r.err == nil {
+		r.err = r.rows.Error()
+	}
+	return false
+}
+
+// Row 当前行，解析失败时 Err 不为空
+func (r *RowReader[T]) Row() Row[T] {
+	return r.row
+}
+
+// Error 读取文件的错误，单行的解析错误见 Row.Err
+func (r *RowReader[T]) Error() error {
+	return r.err
+}
+
+// Close 关闭读取，OpenRowReader 打开的文件同时关闭
+func (r *RowReader[T]) Close() error {
+	err := r.rows.Close()
+	if r.file != nil {
+		if closeErr := r.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package excel
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type readerData struct {
+	Name string `excel:"姓名;required"`
+	Age  int    `excel:"年龄"`
+}
+
+func TestRowReader(t *testing.T) {
+	f := excelize.NewFile()
+	rows := map[string][]interface{}{
+		"A1": {"填写说明:姓名为必填项"},
+		"A2": {"姓名", "年龄"},
+		"A3": {"张三", 18},
+		"A5": {"", 20},
+		"A6": {"李四", "abc"},
+		"A8": {"王五", 30},
+	}
+	for cell, row := range rows {
+		assert.NoError(t, f.SetSheetRow("Sheet1", cell, &row))
+	}
+	name := filepath.Join(t.TempDir(), "reader.xlsx")
+	assert.NoError(t, f.SaveAs(name))
+
+	r, err := OpenRowReader[readerData](name, "")
+	assert.NoError(t, err)
+	defer r.Close()
+	var list []Row[readerData]
+	for r.Next() {
+		list = append(list, r.Row())
+	}
+	assert.NoError(t, r.Error())
+	assert.Len(t, list, 4)
+	assert.Equal(t, Row[readerData]{Num: 3, Data: readerData{Name: "张三", Age: 18}}, list[0])
+	assert.Equal(t, 5, list[1].Num)
+	assert.ErrorIs(t, list[1].Err, ErrRequired)
+	assert.Equal(t, 6, list[2].Num)
+	assert.ErrorContains(t, list[2].Err, "invalid integer value")
+	assert.Equal(t, Row[readerData]{Num: 8, Data: readerData{Name: "王五", Age: 30}}, list[3])
+
+	_, err = OpenRowReader[sheetOrder](name, "")
+	assert.ErrorIs(t, err, ErrHeaderNotFound)
+}
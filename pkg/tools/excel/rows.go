@@ -0,0 +1,310 @@
+package excel
+
+import (
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// field 结构体中有 excel 标签的字段
+type field struct {
+	index   []int
+	setting Setting
+	elem    reflect.Type // 子工作表的结构体类型
+}
+
+// structFields 结构体中有 excel 标签的字段
+type structFields struct {
+	columns []field // 列，inline 的结构体展开为带前缀的列
+	sheets  []field // 写入子工作表的切片字段
+	key     int     // 子工作表关联的列，标签中有 key 的列，默认第一列
+}
+
+var fieldsCache sync.Map // reflect.Type -> *structFields
+
+// parseFields 解析结构体中有 excel 标签的列
+func parseFields(t reflect.Type) []field {
+	return parseStruct(t).columns
+}
+
+// parseStruct 解析结构体中有 excel 标签的字段，嵌入的结构体会递归解析，结果按类型缓存
+func parseStruct(t reflect.Type) *structFields {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if v, ok := fieldsCache.Load(t); ok {
+		return v.(*structFields)
+	}
+
+	sf := &structFields{}
+	sf.appendFields(t, nil, "")
+
+	fieldsCache.Store(t, sf)
+	return sf
+}
+
+// appendFields 添加结构体 t 的字段，index 为 t 在顶层结构体中的索引，prefix 为 inline 的列名前缀
+func (sf *structFields) appendFields(t reflect.Type, index []int, prefix string) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		tag := f.Tag.Get("excel")
+		if tag == "" || tag == "-" || f.Anonymous || !f.IsExported() {
+			continue
+		}
+		setting := parseFieldTag(Setting{}, tag)
+		if setting.Head == "" && !setting.Inline {
+			setting.Head = f.Name
+		}
+		fieldIndex := append(append([]int{}, index...), f.Index...)
+
+		switch {
+		case setting.Inline:
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			sf.appendFields(ft, fieldIndex, prefix+setting.Head)
+		case setting.Sheet != "":
+			elem := f.Type
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Slice && elem.Kind() != reflect.Array {
+				panic("excel: field " + f.Name + " with sheet must be a slice")
+			}
+			elem = elem.Elem()
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			sf.sheets = append(sf.sheets, field{index: fieldIndex, setting: setting, elem: elem})
+		default:
+			setting.Head = prefix + setting.Head
+			if setting.Key {
+				sf.key = len(sf.columns)
+			}
+			sf.columns = append(sf.columns, field{index: fieldIndex, setting: setting})
+		}
+	}
+}
+
+// eachRecord 遍历结构体或结构体指针的切片
+func eachRecord(list interface{}, fn func(record reflect.Value) error) error {
+	values := reflect.Indirect(reflect.ValueOf(list))
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		return errors.New("resolution of this data type is not supported")
+	}
+	for i := 0; i < values.Len(); i++ {
+		record := values.Index(i)
+		for record.Kind() == reflect.Ptr || record.Kind() == reflect.Interface {
+			record = record.Elem()
+		}
+		if record.Kind() != reflect.Struct {
+			return errors.New("resolution of this data type is not supported")
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cellValue 单元格的值，空指针为 nil，时间按 layout 格式化，bool 按 formatBool 格式化
+func cellValue(v reflect.Value, layout string, formatBool map[bool]string) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	value := v.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		// 实现 driver.Valuer 的自定义类型，例如 model.LocalTime
+		res, err := valuer.Value()
+		if err != nil || res == nil {
+			return nil
+		}
+		value = res
+	}
+	switch val := value.(type) {
+	case time.Time:
+		if val.IsZero() {
+			return nil
+		}
+		return val.Format(layout)
+	case []byte:
+		return string(val)
+	case bool:
+		if s, ok := formatBool[val]; ok {
+			return s
+		}
+		return val
+	case fmt.Stringer:
+		return val.String()
+	}
+	return value
+}
+
+// WriteRows 分批写入数据，list 为结构体或结构体指针的切片，只写入有 excel 标签的字段
+// 第一次调用时写入备注和标题，可以多次调用，全部写入后调用 Flush
+// 有 sheet 标签的切片字段同时写入子工作表
+func (e *ExcelTool) WriteRows(list interface{}) error {
+	return e.writeRows(list, nil)
+}
+
+// writeRows 分批写入数据，子工作表第一列写入关联的上级 link
+func (e *ExcelTool) writeRows(list interface{}, link interface{}) error {
+	if e.row == 0 {
+		if err := e.writeHead(); err != nil {
+			return err
+		}
+	}
+
+	return eachRecord(list, func(record reflect.Value) error {
+		row, err := e.rowCells(record, link)
+		if err != nil {
+			return fmt.Errorf("row %d, %w", e.row, err)
+		}
+		axis, err := excelize.CoordinatesToCellName(1, e.row)
+		if err != nil {
+			return err
+		}
+		if err = e.sw.SetRow(axis, row, excelize.RowOpts{Height: 16}); err != nil {
+			return err
+		}
+		e.row++
+		return nil
+	})
+}
+
+// rowCells 一行数据的单元格，子工作表第一列为 link，同时写入该行的子工作表
+func (e *ExcelTool) rowCells(record reflect.Value, link interface{}) ([]interface{}, error) {
+	sf := parseStruct(reflect.TypeOf(e.model))
+	cells, err := recordCells(record, sf.columns, e.formatBool)
+	if err != nil {
+		return nil, err
+	}
+	if err = e.writeChildren(record, sf, cells); err != nil {
+		return nil, err
+	}
+	if e.link != "" {
+		cells = append([]interface{}{excelize.Cell{Value: link}}, cells...)
+	}
+	return cells, nil
+}
+
+// recordCells 一行数据的单元格，按字段的 excel 标签转换
+func recordCells(record reflect.Value, fields []field, formatBool map[bool]string) ([]interface{}, error) {
+	row := make([]interface{}, len(fields))
+	for i, f := range fields {
+		var value interface{}
+		if fv, err := record.FieldByIndexErr(f.index); err == nil {
+			if value, err = f.setting.value(fv, formatBool); err != nil {
+				return nil, err
+			}
+		} else if f.setting.Required {
+			// 嵌入的结构体指针为空
+			return nil, fmt.Errorf("column %s: %w", f.setting.Head, ErrRequired)
+		}
+		row[i] = excelize.Cell{Value: value}
+	}
+	return row, nil
+}
+
+// Write 写出文件，需要先调用 Flush
+func (e *ExcelTool) Write(w io.Writer) error {
+	return e.file.Write(w)
+}
+
+// CSVWriter 按结构体的 excel 标签流式写入csv
+// 输出带BOM的UTF-8，excel打开时中文不会乱码
+//
+//	w := excel.NewCSVWriter(file, &domain.Demo{})
+//	w.WriteRows(list)
+//	w.Flush()
+type CSVWriter struct {
+	out        io.Writer
+	w          *csv.Writer
+	model      interface{}
+	formatBool map[bool]string
+	started    bool
+}
+
+// NewCSVWriter model 为结构体指针，按其中的 excel 标签生成标题
+func NewCSVWriter(w io.Writer, model interface{}) *CSVWriter {
+	return &CSVWriter{
+		out:        w,
+		w:          csv.NewWriter(w),
+		model:      model,
+		formatBool: map[bool]string{true: "是", false: "否"},
+	}
+}
+
+// WriteRows 分批写入数据，第一次调用时写入标题
+func (c *CSVWriter) WriteRows(list interface{}) error {
+	fields := parseFields(reflect.TypeOf(c.model))
+	if err := c.writeHead(fields); err != nil {
+		return err
+	}
+	return eachRecord(list, func(record reflect.Value) error {
+		cells, err := recordCells(record, fields, c.formatBool)
+		if err != nil {
+			return err
+		}
+		row := make([]string, len(cells))
+		for i, cell := range cells {
+			value := cell.(excelize.Cell).Value
+			if value == nil {
+				continue
+			}
+			row[i] = fmt.Sprint(value)
+			if _, ok := value.(string); ok {
+				row[i] = escapeFormula(row[i])
+			}
+		}
+		return c.w.Write(row)
+	})
+}
+
+// Flush 写出缓冲的数据，没有数据时只写入标题
+func (c *CSVWriter) Flush() error {
+	if err := c.writeHead(parseFields(reflect.TypeOf(c.model))); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) writeHead(fields []field) error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	head := make([]string, len(fields))
+	for i, f := range fields {
+		head[i] = f.setting.Head
+	}
+	// BOM 直接写入底层的 writer，csv.Writer 会给以 BOM 开头的字段加引号
+	if _, err := c.out.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	return c.w.Write(head)
+}
+
+// escapeFormula 以 = + - @ 开头的文本在excel中会作为公式执行，加上单引号作为文本
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package excel
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type rowsBase struct {
+	ID        uint       `excel:"ID"`
+	CreatedAt *time.Time `excel:"创建时间"`
+}
+
+type rowsData struct {
+	rowsBase
+	Name     string `excel:"姓名"`
+	IsAdult  bool   `excel:"是否成年"`
+	Password string
+}
+
+func TestExcelTool_WriteRows(t *testing.T) {
+	created := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	tool := NewExcelTool("Sheet1").Model(&rowsData{})
+	assert.NoError(t, tool.WriteRows([]rowsData{{rowsBase: rowsBase{ID: 1, CreatedAt: &created}, Name: "张三", IsAdult: true}}))
+	assert.NoError(t, tool.WriteRows([]*rowsData{{rowsBase: rowsBase{ID: 2}, Name: "李四"}}))
+	assert.NoError(t, tool.Flush())
+
+	var buf bytes.Buffer
+	assert.NoError(t, tool.Write(&buf))
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	rows, err := f.GetRows("Sheet1")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"ID", "创建时间", "姓名", "是否成年"},
+		{"1", "2026-10-19 08:30:00", "张三", "是"},
+		{"2", "", "李四", "否"},
+	}, rows)
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, &rowsData{})
+	assert.NoError(t, w.WriteRows([]rowsData{{rowsBase: rowsBase{ID: 1}, Name: "=1+1", IsAdult: true}}))
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "\xEF\xBB\xBFID,创建时间,姓名,是否成年\n1,,'=1+1,是\n", buf.String())
+
+	// 没有数据时只有标题
+	buf.Reset()
+	w = NewCSVWriter(&buf, &rowsData{})
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "\xEF\xBB\xBFID,创建时间,姓名,是否成年\n", buf.String())
+}
@@ -0,0 +1,266 @@
+package excel
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrRequired required 的字段为零值或单元格为空
+var ErrRequired = errors.New("required field is empty")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayouts type 对应的默认时间格式，没有指定 type 的时间字段按 datetime 处理
+var timeLayouts = map[string]string{
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02 15:04:05",
+	"time":     "15:04:05",
+}
+
+// timeFormats 解析时间时依次尝试的格式
+var timeFormats = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	time.RFC3339,
+	time.RFC3339Nano,
+}
+
+// layout 时间格式，format 优先
+func (s Setting) layout() string {
+	if s.Format != "" && s.Type != "bool" {
+		return s.Format
+	}
+	if layout, ok := timeLayouts[s.Type]; ok {
+		return layout
+	}
+	return timeLayouts["datetime"]
+}
+
+// boolFormat bool 的文本，format 为 真,假 格式时优先，否则使用 formatBool
+func (s Setting) boolFormat(formatBool map[bool]string) map[bool]string {
+	if s.Type == "bool" && s.Format != "" {
+		if t, f, ok := strings.Cut(s.Format, ","); ok {
+			return map[bool]string{true: t, false: f}
+		}
+	}
+	return formatBool
+}
+
+// value 写入单元格的值，按 type、format、select 转换
+// required 的零值返回 ErrRequired，omitempty 的零值为空
+func (s Setting) value(v reflect.Value, formatBool map[bool]string) (interface{}, error) {
+	if isZero(v) {
+		if s.Required {
+			return nil, fmt.Errorf("column %s: %w", s.Head, ErrRequired)
+		}
+		if s.OmitEmpty {
+			return nil, nil
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		// 枚举按值转换为文本，不在枚举中的值原样写入
+		value := cellValue(v, s.layout(), nil)
+		if label, ok := s.Enum[fmt.Sprint(value)]; ok {
+			return label, nil
+		}
+		return value, nil
+	}
+
+	value := cellValue(v, s.layout(), s.boolFormat(formatBool))
+	if s.Type == "string" && value != nil {
+		// 长数字、手机号等按文本写入，避免被excel转换为科学计数法
+		return fmt.Sprint(value), nil
+	}
+	return value, nil
+}
+
+// setValue 解析单元格的值到字段，value 为空时不处理
+// select 的列只能是选项中的值，枚举的文本转换为值
+func (s Setting) setValue(field reflect.Value, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	if len(s.Select) > 0 {
+		if key, ok := s.enumKey(value); ok {
+			value = key
+		} else if !slices.Contains(s.Select, value) {
+			return fmt.Errorf("invalid option %q, must be one of %s", value, strings.Join(s.Select, ","))
+		}
+	}
+
+	// 处理指针类型
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	if isTimeType(field.Type()) {
+		t, err := s.parseTime(value)
+		if err != nil {
+			return err
+		}
+		if field.Type() == timeType {
+			field.Set(reflect.ValueOf(t))
+		} else {
+			// 嵌入 time.Time 的自定义类型，例如 model.LocalTime
+			field.Field(0).Set(reflect.ValueOf(t))
+		}
+		return nil
+	}
+
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	// 根据字段类型进行转换
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := s.parseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer value: %w", err)
+		}
+		field.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer value: %w", err)
+		}
+		field.SetUint(uintVal)
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid float value: %w", err)
+		}
+		field.SetFloat(floatVal)
+	default:
+		return fmt.Errorf("unsupported field type: %v", field.Type())
+	}
+
+	return nil
+}
+
+// enumKey 枚举文本对应的值
+func (s Setting) enumKey(label string) (string, bool) {
+	for key, l := range s.Enum {
+		if l == label || key == label {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// parseTime 先按 format 解析，再依次尝试常用格式，数字按excel的日期序列号解析
+func (s Setting) parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(s.layout(), value); err == nil {
+		return t, nil
+	}
+	for _, format := range timeFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+	return time.Time{}, fmt.Errorf("invalid time format: %s", value)
+}
+
+// parseBool 支持 format 中的文本、是/否 和 strconv.ParseBool 支持的格式
+func (s Setting) parseBool(value string) (bool, error) {
+	for b, label := range s.boolFormat(map[bool]string{true: "是", false: "否"}) {
+		if value == label {
+			return b, nil
+		}
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid bool value: %s", value)
+	}
+	return b, nil
+}
+
+// isTimeType time.Time 或嵌入 time.Time 的自定义类型
+func isTimeType(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	return t.Kind() == reflect.Struct && t.NumField() > 0 && t.Field(0).Anonymous && t.Field(0).Type == timeType
+}
+
+// isZero 空指针或零值
+func isZero(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return !v.IsValid() || v.IsZero()
+}
+
+// headStyles 标题单元格的样式，设置了 color 的列填充底色
+func headStyles(f *excelize.File, settings []Setting) ([]int, error) {
+	styles := make([]int, len(settings))
+	colors := make(map[string]int)
+	for i, s := range settings {
+		if s.Color == "" {
+			continue
+		}
+		if _, ok := colors[s.Color]; !ok {
+			style, err := f.NewStyle(&excelize.Style{
+				Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{s.Color}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			colors[s.Color] = style
+		}
+		styles[i] = colors[s.Color]
+	}
+	return styles, nil
+}
+
+// addSelects 给设置了 select 的列添加下拉列表，从 row 行开始
+func addSelects(f *excelize.File, sheet string, settings []Setting, row int) error {
+	for i, s := range settings {
+		if len(s.Select) == 0 {
+			continue
+		}
+		col := indexToColumnName(i)
+		dv := excelize.NewDataValidation(true)
+		dv.SetSqref(fmt.Sprintf("%s%d:%s1048576", col, row, col))
+		if err := dv.SetDropList(s.Select); err != nil {
+			return err
+		}
+		if err := f.AddDataValidation(sheet, dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
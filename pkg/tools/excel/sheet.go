@@ -0,0 +1,255 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheeter 模型实现 SheetName 时，ExcelTool.Model 写入、ParseExcelToSlice 解析该工作表
+//
+//	func (Summary) SheetName() string { return "汇总" }
+//
+//	tool := excel.NewExcelTool("汇总")
+//	tool.Model(&Summary{}).WriteRows(summaries)
+//	tool.Model(&Failure{}).WriteRows(failures) // 写入 Failure 的工作表
+//	tool.Flush()
+type Sheeter interface {
+	SheetName() string
+}
+
+// workbook 同一文件中的工作表，按创建顺序写入
+type workbook struct {
+	sheets []*ExcelTool
+}
+
+// Sheet 返回同一文件中的工作表，不存在时创建，创建失败时返回 nil
+func (e *ExcelTool) Sheet(sheet string) *ExcelTool {
+	for _, s := range e.book.sheets {
+		if s.sheet == sheet {
+			return s
+		}
+	}
+
+	if _, err := e.file.NewSheet(sheet); err != nil {
+		return nil
+	}
+	sw, err := e.file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil
+	}
+	s := &ExcelTool{
+		sheet:      sheet,
+		file:       e.file,
+		sw:         sw,
+		TagCol:     make(map[string]string),
+		formatBool: e.formatBool,
+		book:       e.book,
+	}
+	e.book.sheets = append(e.book.sheets, s)
+	return s
+}
+
+// createChildren 创建有 sheet 标签的切片字段的子工作表，子工作表第一列为上级的 key 列
+func (e *ExcelTool) createChildren() error {
+	sf := parseStruct(reflect.TypeOf(e.model))
+	if len(sf.sheets) == 0 {
+		return nil
+	}
+	if len(sf.columns) == 0 {
+		return errors.New("sheet requires a key column")
+	}
+	for _, f := range sf.sheets {
+		child := e.Sheet(f.setting.Sheet)
+		if child == nil {
+			return fmt.Errorf("create sheet %s failed", f.setting.Sheet)
+		}
+		if child.model == nil {
+			child.link = sf.columns[sf.key].setting.Head
+			child.Model(reflect.New(f.elem).Interface())
+		}
+	}
+	return nil
+}
+
+// writeChildren 写入一行数据中 sheet 标签的切片字段，cells 为该行的单元格
+func (e *ExcelTool) writeChildren(record reflect.Value, sf *structFields, cells []interface{}) error {
+	for _, f := range sf.sheets {
+		fv, err := record.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		fv = reflect.Indirect(fv)
+		if !fv.IsValid() || fv.Len() == 0 {
+			continue
+		}
+		child := e.Sheet(f.setting.Sheet)
+		if child == nil {
+			return fmt.Errorf("create sheet %s failed", f.setting.Sheet)
+		}
+		link := cells[sf.key].(excelize.Cell).Value
+		if err = child.writeRows(fv.Interface(), link); err != nil {
+			return fmt.Errorf("sheet %s, %w", f.setting.Sheet, err)
+		}
+	}
+	return nil
+}
+
+// ParseSheet 解析已打开文件中的工作表，用于一个文件中有多个工作表时按模型分别解析
+// sheet 为空时使用模型的 SheetName，没有时使用第一个工作表
+// 有 sheet 标签的切片字段从子工作表解析，按第一列与上级的 key 列关联
+func ParseSheet[T any](f *excelize.File, sheet string) ([]T, error) {
+	var structType T
+	t := reflect.TypeOf(structType)
+	if sheet == "" {
+		sheet = sheetName(t)
+	}
+	if sheet == "" {
+		// 获取第一个工作表
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("no sheets found in excel file")
+		}
+		sheet = sheets[0]
+	}
+
+	// 获取所有行
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("get rows failed: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, errors.New("excel file is empty or has no data rows")
+	}
+
+	slice, _, err := parseRows(f, t, rows, "")
+	if err != nil {
+		return nil, err
+	}
+	return slice.Interface().([]T), nil
+}
+
+// parseRows 解析工作表的全部行，返回 []t 和每行 link 列的值
+func parseRows(f *excelize.File, t reflect.Type, rows [][]string, link string) (reflect.Value, []string, error) {
+	slice := reflect.MakeSlice(reflect.SliceOf(t), 0, len(rows))
+	if len(rows) == 0 {
+		return slice, nil, nil
+	}
+
+	sf := parseStruct(t)
+	// 标题行为第一个包含模型列名的行，之前的备注等行跳过
+	head := 0
+	for head < len(rows) && !isHeader(rows[head], sf.columns) {
+		head++
+	}
+	if head == len(rows) {
+		return slice, nil, ErrHeaderNotFound
+	}
+	columns, err := headerColumns(rows[head], sf.columns)
+	if err != nil {
+		return slice, nil, err
+	}
+	linkCol := -1
+	if link != "" {
+		linkCol = indexOf(rows[head], link)
+		if linkCol < 0 {
+			return slice, nil, fmt.Errorf("column %s: %w", link, ErrRequired)
+		}
+	}
+
+	var links, keys []string
+	for i, row := range rows[head+1:] {
+		if isBlankRow(row) {
+			continue
+		}
+		newVal := reflect.New(t).Elem()
+		if err := setRecord(newVal, sf.columns, columns, row); err != nil {
+			return slice, nil, fmt.Errorf("row %d, %w", head+i+2, err)
+		}
+		slice = reflect.Append(slice, newVal)
+		links = append(links, cellAt(row, linkCol))
+		if len(columns) > 0 {
+			keys = append(keys, cellAt(row, columns[sf.key]))
+		}
+	}
+
+	// 子工作表按关联列追加到上级
+	for _, field := range sf.sheets {
+		if len(keys) == 0 {
+			break
+		}
+		childRows, err := f.GetRows(field.setting.Sheet)
+		if err != nil {
+			// 没有子工作表
+			continue
+		}
+		children, childLinks, err := parseRows(f, field.elem, childRows, sf.columns[sf.key].setting.Head)
+		if err != nil {
+			return slice, nil, fmt.Errorf("sheet %s, %w", field.setting.Sheet, err)
+		}
+		for i, key := range keys {
+			target := fieldByIndex(slice.Index(i), field.index)
+			if target.Kind() == reflect.Ptr {
+				if target.IsNil() {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+				target = target.Elem()
+			}
+			for j, childLink := range childLinks {
+				if childLink != key {
+					continue
+				}
+				child := children.Index(j)
+				if target.Type().Elem().Kind() == reflect.Ptr {
+					child = child.Addr()
+				}
+				target.Set(reflect.Append(target, child))
+			}
+		}
+	}
+
+	return slice, links, nil
+}
+
+// sheetName 模型实现 Sheeter 时的工作表
+func sheetName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	// 指针的方法集包含值接收者的方法
+	if sheeter, ok := reflect.New(t).Interface().(Sheeter); ok {
+		return sheeter.SheetName()
+	}
+	return ""
+}
+
+// isHeader 行中有模型的列名
+func isHeader(row []string, fields []field) bool {
+	for _, f := range fields {
+		if indexOf(row, f.setting.Head) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// indexOf 标题所在的列索引，没有时为-1
+func indexOf(header []string, head string) int {
+	for i, h := range header {
+		if strings.TrimSpace(h) == head {
+			return i
+		}
+	}
+	return -1
+}
+
+// cellAt 单元格的值，列不存在时为空
+func cellAt(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[col])
+}
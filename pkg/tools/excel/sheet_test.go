@@ -0,0 +1,95 @@
+package excel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type sheetAddress struct {
+	Province string `excel:"省份"`
+	City     string `excel:"城市"`
+}
+
+type sheetItem struct {
+	Name  string `excel:"商品;required"`
+	Count int    `excel:"数量"`
+}
+
+type sheetOrder struct {
+	No      string        `excel:"订单号;key"`
+	ID      uint          `excel:"ID"`
+	Address sheetAddress  `excel:"head:收货;inline"`
+	Contact *sheetAddress `excel:"head:联系-;inline"`
+	Items   []*sheetItem  `excel:"sheet:明细"`
+}
+
+type sheetSummary struct {
+	Total int `excel:"总数"`
+}
+
+func (sheetSummary) SheetName() string { return "汇总" }
+
+type sheetFailure struct {
+	Row    int    `excel:"行号"`
+	Reason string `excel:"原因"`
+}
+
+func (*sheetFailure) SheetName() string { return "错误" }
+
+func TestExcelTool_Sheet(t *testing.T) {
+	tool := NewExcelTool("订单").Model(&sheetOrder{})
+	assert.NoError(t, tool.WriteRows([]sheetOrder{
+		{No: "A001", ID: 1, Address: sheetAddress{Province: "浙江", City: "杭州"}, Items: []*sheetItem{{Name: "苹果", Count: 2}, {Name: "梨", Count: 1}}},
+		{No: "A002", ID: 2, Contact: &sheetAddress{City: "上海"}},
+		{No: "A003", ID: 3, Items: []*sheetItem{{Name: "桃", Count: 5}}},
+	}))
+	assert.NoError(t, tool.Model(&sheetSummary{}).WriteRows([]sheetSummary{{Total: 3}}))
+	assert.NoError(t, tool.Model(&sheetFailure{}).WriteRows([]*sheetFailure{{Row: 4, Reason: "重复"}}))
+	assert.NoError(t, tool.Flush())
+	buf, err := tool.WriteToBuffer()
+	assert.NoError(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"订单", "明细", "汇总", "错误"}, f.GetSheetList())
+	rows, err := f.GetRows("订单")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"订单号", "ID", "收货省份", "收货城市", "联系-省份", "联系-城市"},
+		{"A001", "1", "浙江", "杭州"},
+		{"A002", "2", "", "", "", "上海"},
+		{"A003", "3"},
+	}, rows)
+	rows, err = f.GetRows("明细")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"订单号", "商品", "数量"},
+		{"A001", "苹果", "2"},
+		{"A001", "梨", "1"},
+		{"A003", "桃", "5"},
+	}, rows)
+
+	orders, err := ParseSheet[sheetOrder](f, "订单")
+	assert.NoError(t, err)
+	assert.Equal(t, []sheetOrder{
+		{No: "A001", ID: 1, Address: sheetAddress{Province: "浙江", City: "杭州"}, Items: []*sheetItem{{Name: "苹果", Count: 2}, {Name: "梨", Count: 1}}},
+		{No: "A002", ID: 2, Contact: &sheetAddress{City: "上海"}},
+		{No: "A003", ID: 3, Items: []*sheetItem{{Name: "桃", Count: 5}}},
+	}, orders)
+	summaries, err := ParseSheet[sheetSummary](f, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []sheetSummary{{Total: 3}}, summaries)
+	failures, err := ParseExcelToSlice[sheetFailure](bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, []sheetFailure{{Row: 4, Reason: "重复"}}, failures)
+
+	// 子工作表的错误
+	assert.NoError(t, f.SetCellValue("明细", "B3", ""))
+	_, err = ParseSheet[sheetOrder](f, "订单")
+	assert.ErrorIs(t, err, ErrRequired)
+	assert.ErrorContains(t, err, "sheet 明细, row 3")
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+const hiddenMask = "******"
+
+// HideJSONFields 将json中指定字段(忽略大小写，包括嵌套对象和数组中的字段)的值替换为 ******
+// 非json内容原样返回
+func HideJSONFields(body []byte, fields []string) []byte {
+	if len(body) == 0 || len(fields) == 0 {
+		return body
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return body
+	}
+
+	set := hiddenFieldSet(fields)
+	res, err := json.Marshal(hideFields(data, set))
+	if err != nil {
+		return body
+	}
+	return res
+}
+
+// HideFormFields 将表单或者query中指定字段(忽略大小写)的值替换为 ******
+func HideFormFields(values url.Values, fields []string) url.Values {
+	if len(values) == 0 || len(fields) == 0 {
+		return values
+	}
+
+	set := hiddenFieldSet(fields)
+	res := make(url.Values, len(values))
+	for k, v := range values {
+		if _, ok := set[strings.ToLower(k)]; ok {
+			res[k] = []string{hiddenMask}
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
+
+func hiddenFieldSet(fields []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		set[strings.ToLower(f)] = struct{}{}
+	}
+	return set
+}
+
+func hideFields(data interface{}, set map[string]struct{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if _, ok := set[strings.ToLower(k)]; ok {
+				v[k] = hiddenMask
+				continue
+			}
+			v[k] = hideFields(val, set)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = hideFields(val, set)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHideJSONFields(t *testing.T) {
+	body := []byte(`{"account":"admin","Password":"123456","users":[{"password":"abc","nick_name":"a"}]}`)
+	result := HideJSONFields(body, []string{"password"})
+	assert.JSONEq(t, `{"account":"admin","Password":"******","users":[{"password":"******","nick_name":"a"}]}`, string(result))
+
+	// 非json原样返回
+	assert.Equal(t, "password=1", string(HideJSONFields([]byte("password=1"), []string{"password"})))
+}
+
+func TestHideFormFields(t *testing.T) {
+	values := url.Values{"token": {"xxx"}, "name": {"a"}}
+	result := HideFormFields(values, []string{"Token"})
+	assert.Equal(t, "name=a&token=%2A%2A%2A%2A%2A%2A", result.Encode())
+	assert.Equal(t, "xxx", values.Get("token"))
+}
@@ -0,0 +1,150 @@
+package ipdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	LocationUnknown  = "未知"
+	LocationIntranet = "内网IP"
+)
+
+var ErrInvalidIP = errors.New("invalid ipv4 address")
+
+type segment struct {
+	start    uint32
+	end      uint32
+	location string
+}
+
+// DB 本地IP库，按IP段查询大致归属地
+// 文件格式与 ip2region 的原始txt数据一致，每行一个IP段：
+// 起始IP|结束IP|国家|区域|省份|城市|运营商，值为0的列会被忽略
+type DB struct {
+	segments []segment
+}
+
+// Open 从文件加载IP库
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load 从reader加载IP库
+func Load(r io.Reader) (*DB, error) {
+	var (
+		segments []segment
+		line     int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		cols := strings.Split(text, "|")
+		if len(cols) < 3 {
+			return nil, fmt.Errorf("ipdb line %d: invalid segment", line)
+		}
+		start, err := ipToUint32(cols[0])
+		if err != nil {
+			return nil, fmt.Errorf("ipdb line %d: %w", line, err)
+		}
+		end, err := ipToUint32(cols[1])
+		if err != nil {
+			return nil, fmt.Errorf("ipdb line %d: %w", line, err)
+		}
+		if start > end {
+			return nil, fmt.Errorf("ipdb line %d: start ip greater than end ip", line)
+		}
+
+		segments = append(segments, segment{start: start, end: end, location: location(cols[2:])})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].start < segments[j].start
+	})
+
+	return &DB{segments: segments}, nil
+}
+
+// Search 查询IP归属地
+// 内网IP返回 LocationIntranet，未收录或无法解析的IP返回 LocationUnknown
+func (d *DB) Search(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return LocationUnknown
+	}
+	if parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsLinkLocalUnicast() {
+		return LocationIntranet
+	}
+	if d == nil {
+		return LocationUnknown
+	}
+
+	v4 := parsed.To4()
+	if v4 == nil {
+		return LocationUnknown
+	}
+	n := binary.BigEndian.Uint32(v4)
+
+	// 找到第一个 end >= n 的IP段
+	i := sort.Search(len(d.segments), func(i int) bool {
+		return d.segments[i].end >= n
+	})
+	if i < len(d.segments) && d.segments[i].start <= n {
+		return d.segments[i].location
+	}
+
+	return LocationUnknown
+}
+
+func location(cols []string) string {
+	parts := make([]string, 0, len(cols))
+	for _, col := range cols {
+		col = strings.TrimSpace(col)
+		if col == "" || col == "0" {
+			continue
+		}
+		// 去除相邻的重复值，例如 中国|0|上海|上海市
+		if len(parts) > 0 && strings.HasPrefix(col, parts[len(parts)-1]) {
+			parts[len(parts)-1] = col
+			continue
+		}
+		parts = append(parts, col)
+	}
+	if len(parts) == 0 {
+		return LocationUnknown
+	}
+	return strings.Join(parts, " ")
+}
+
+func ipToUint32(ip string) (uint32, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return 0, ErrInvalidIP
+	}
+	v4 := parsed.To4()
+	if v4 == nil {
+		return 0, ErrInvalidIP
+	}
+	return binary.BigEndian.Uint32(v4), nil
+}
@@ -0,0 +1,49 @@
+package ipdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testData = `# 测试数据
+1.0.1.0|1.0.3.255|中国|0|福建省|福州市|电信
+8.8.8.0|8.8.8.255|美国|0|0|0|Level3
+36.56.0.0|36.63.255.255|中国|0|上海|上海市|电信
+`
+
+func TestDB_Search(t *testing.T) {
+	db, err := Load(strings.NewReader(testData))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"1.0.2.1", "中国 福建省 福州市 电信"},
+		{"8.8.8.8", "美国 Level3"},
+		{"36.60.1.1", "中国 上海市 电信"},
+		{"9.9.9.9", LocationUnknown},
+		{"192.168.1.1", LocationIntranet},
+		{"127.0.0.1", LocationIntranet},
+		{"abc", LocationUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, db.Search(tt.ip), tt.ip)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(strings.NewReader("1.0.0.1|abc|中国"))
+	assert.ErrorIs(t, err, ErrInvalidIP)
+
+	_, err = Load(strings.NewReader("1.0.0.9|1.0.0.1|中国"))
+	assert.Error(t, err)
+}
+
+func TestDB_SearchNil(t *testing.T) {
+	var db *DB
+	assert.Equal(t, LocationUnknown, db.Search("8.8.8.8"))
+	assert.Equal(t, LocationIntranet, db.Search("10.0.0.1"))
+}
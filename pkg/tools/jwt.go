@@ -7,9 +7,11 @@ import (
 )
 
 const (
-	UserIdKey = "userId"
-	RoleIdKey = "roleId"
-	ExpKey    = "exp" // 过期时间key
+	UserIdKey     = "userId"
+	RoleIdKey     = "roleId"
+	TenantKey     = "tenantId"   // 租户id，0为平台用户
+	SuperAdminKey = "superAdmin" // 超级管理员，可以跨租户操作、管理租户
+	ExpKey        = "exp"        // 过期时间key
 )
 
 var (
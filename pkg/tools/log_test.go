@@ -7,6 +7,8 @@ import (
 )
 
 func TestRotation(t *testing.T) {
+	// 日志写入 ./logs，在临时目录中运行避免写入包目录
+	t.Chdir(t.TempDir())
 	logger := NewCustomTimeBasedLogger()
 
 	for i := 0; i < 100; i++ {
@@ -0,0 +1,22 @@
+package str
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const passwordLetters = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword 使用crypto/rand生成 num 位随机密码(去除了容易混淆的字符)
+func GeneratePassword(num int) (string, error) {
+	b := make([]byte, num)
+	max := big.NewInt(int64(len(passwordLetters)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordLetters[n.Int64()]
+	}
+	return string(b), nil
+}
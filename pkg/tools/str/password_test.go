@@ -0,0 +1,21 @@
+package str
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	pwd1, err := GeneratePassword(12)
+	assert.NoError(t, err)
+	pwd2, err := GeneratePassword(12)
+	assert.NoError(t, err)
+
+	assert.Len(t, pwd1, 12)
+	assert.NotEqual(t, pwd1, pwd2)
+	for _, r := range pwd1 {
+		assert.True(t, strings.ContainsRune(passwordLetters, r))
+	}
+}
@@ -0,0 +1,87 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+const Unknown = "Unknown"
+
+// UserAgent 解析后的客户端信息
+type UserAgent struct {
+	Browser string // 浏览器及主版本，例如 Chrome 120
+	OS      string // 操作系统，例如 Windows 10
+}
+
+type rule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// 顺序敏感：Edge、Opera 等基于Chromium的浏览器同时包含 Chrome 标识，需要放在前面
+var browserRules = []rule{
+	{"WeChat", regexp.MustCompile(`MicroMessenger/(\d+)`)},
+	{"DingTalk", regexp.MustCompile(`DingTalk/(\d+)`)},
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/(\d+)`)},
+	{"QQBrowser", regexp.MustCompile(`QQBrowser/(\d+)`)},
+	{"UCBrowser", regexp.MustCompile(`UCBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+)[\d.]* (?:Mobile/\w+ )?Safari/`)},
+	{"IE", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)(\d+)`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/(\d+)`)},
+	{"curl", regexp.MustCompile(`curl/(\d+)`)},
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+var (
+	windowsRe = regexp.MustCompile(`Windows NT (\d+\.\d+)`)
+	androidRe = regexp.MustCompile(`Android (\d+(?:\.\d+)?)`)
+	iosRe     = regexp.MustCompile(`(?:iPhone|CPU) OS (\d+)`)
+	macRe     = regexp.MustCompile(`Mac OS X (\d+)[_.](\d+)`)
+)
+
+// Parse 解析User-Agent，只识别常见的浏览器和操作系统，无法识别时返回 Unknown
+func Parse(ua string) UserAgent {
+	return UserAgent{Browser: browser(ua), OS: operatingSystem(ua)}
+}
+
+func browser(ua string) string {
+	for _, r := range browserRules {
+		if m := r.re.FindStringSubmatch(ua); m != nil {
+			return r.name + " " + m[1]
+		}
+	}
+	return Unknown
+}
+
+func operatingSystem(ua string) string {
+	if m := windowsRe.FindStringSubmatch(ua); m != nil {
+		if v, ok := windowsVersions[m[1]]; ok {
+			return "Windows " + v
+		}
+		return "Windows"
+	}
+	if m := androidRe.FindStringSubmatch(ua); m != nil {
+		return "Android " + m[1]
+	}
+	if m := iosRe.FindStringSubmatch(ua); m != nil && !strings.Contains(ua, "Macintosh") {
+		return "iOS " + m[1]
+	}
+	if m := macRe.FindStringSubmatch(ua); m != nil {
+		return "macOS " + m[1] + "." + m[2]
+	}
+	if strings.Contains(ua, "Linux") {
+		return "Linux"
+	}
+	return Unknown
+}
@@ -26,7 +26,7 @@ func RunServer() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	r := gin.New()
-	r.Use(middleware.GinLogger(), middleware.GinRecovery(true))
+	r.Use(middleware.Trace(), middleware.GinLogger(), middleware.GinRecovery(true), middleware.Audit())
 
 	// 设置 swagger 访问路由
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -51,22 +51,33 @@ func RunServer() {
 
 	// 设置路由组
 	public := r.Group("")
-	private := r.Group("", middleware.JwtAuth(), middleware.CasbinHandler())
+	private := r.Group("", middleware.JwtAuth(), middleware.Tenant(), middleware.CasbinHandler())
+	// 平台管理，只允许超级管理员访问
+	platform := r.Group("", middleware.JwtAuth(), middleware.SuperAdmin(), middleware.CasbinHandler())
+	// 平台数据只保存在共享库，租户的独立数据库只保存业务数据
+	shared := private.Group("", middleware.SharedDB())
 
 	// 注册路由
 	// 热更新日志级别 debug info warn error
 	r.PUT("/logs-lvl", gin.WrapH(logger.ChangeLevelHandlerFunc()))
-	routers.DemoRouterRegister(public)
-	routers.FileRouterRegister(r)
-	routers.SystemRouterRegister(public)
+	routers.DemoRouterRegister(private)
+	routers.FileRouterRegister(private)
+	routers.SystemRouterRegister(public, platform)
 	routers.SysUserRouterRegister(public)
-	routers.SysRoleRouterRegister(public)
-	routers.SysCasbinRouterRegister(public)
-	routers.SysApiRouterRegister(private)
-	routers.SysMenuRouterRegister(private)
-	routers.DataImportRouterRegister(public)
+	routers.SysRoleRouterRegister(platform)
+	routers.SysCasbinRouterRegister(platform)
+	routers.SysApiRouterRegister(shared)
+	routers.SysMenuRouterRegister(shared)
+	routers.SysLoginLogRouterRegister(shared)
+	routers.SysOperationLogRouterRegister(shared)
+	routers.SysChangeHistoryRouterRegister(private)
+	routers.RecycleBinRouterRegister(private)
+	routers.DataImportRouterRegister(private)
 	routers.NoPageRouterRegister(private)
-	routers.SystemFileRouterRegister(public)
+	routers.AggregateRouterRegister(private)
+	routers.DataExportRouterRegister(private)
+	routers.SystemFileRouterRegister(private)
+	routers.SysTenantRouterRegister(platform)
 
 	log.Printf("[GIN-QuickStart] 接口文档地址：http://localhost:%v/swagger/index.html\n", conf.Conf.ServerPort)
 
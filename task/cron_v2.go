@@ -83,7 +83,9 @@ func V2Init() {
 
 	v2 := NewTaskV2(scheduler, WithMaxRetry(defaultMaxRetry))
 
-	v2.Register("@every 30m", v2.NewTask(constants.TaskTest, nil)) // 每隔30分钟同步一次
+	v2.Register("@every 30m", v2.NewTask(constants.TaskTest, nil))           // 每隔30分钟同步一次
+	v2.Register("0 3 * * *", v2.NewTask(constants.TaskLoginLogCleanup, nil)) // 每天凌晨3点清理过期登录日志
+	v2.Register("0 4 * * *", v2.NewTask(constants.TaskRecycleBinPurge, nil)) // 每天凌晨4点清理回收站
 	//v2.Register("@every 1s", v2.NewTask(constants.TaskMonitor, nil)) // 每隔1s监控一次
 
 	if err := scheduler.Run(); err != nil {
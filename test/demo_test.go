@@ -46,45 +46,45 @@ func TestCreateDemo(t *testing.T) {
 
 	var demoTestData = []domain.Demo{
 		{
-			Model: model.Model{ID: 1},
-			Name:  "Alice",
-			Age:   25,
+			AuditModel: model.AuditModel{Model: model.Model{ID: 1}},
+			Name:       "Alice",
+			Age:        25,
 			BirthDay: &model.LocalTime{
 				Time: time.Date(1998, 4, 15, 0, 0, 0, 0, time.UTC),
 			},
 			Tags: datatypes.JSONSlice[string]{"golang", "backend", "gin"},
 		},
 		{
-			Model: model.Model{ID: 2},
-			Name:  "Bob",
-			Age:   30,
+			AuditModel: model.AuditModel{Model: model.Model{ID: 2}},
+			Name:       "Bob",
+			Age:        30,
 			BirthDay: &model.LocalTime{
 				Time: time.Date(1993, 7, 8, 0, 0, 0, 0, time.UTC),
 			},
 			Tags: datatypes.JSONSlice[string]{"frontend", "react", "typescript"},
 		},
 		{
-			Model: model.Model{ID: 3},
-			Name:  "Carol",
-			Age:   22,
+			AuditModel: model.AuditModel{Model: model.Model{ID: 3}},
+			Name:       "Carol",
+			Age:        22,
 			BirthDay: &model.LocalTime{
 				Time: time.Date(2001, 1, 20, 0, 0, 0, 0, time.UTC),
 			},
 			Tags: datatypes.JSONSlice[string]{"design", "ui", "ux"},
 		},
 		{
-			Model: model.Model{ID: 4},
-			Name:  "David",
-			Age:   28,
+			AuditModel: model.AuditModel{Model: model.Model{ID: 4}},
+			Name:       "David",
+			Age:        28,
 			BirthDay: &model.LocalTime{
 				Time: time.Date(1996, 11, 5, 0, 0, 0, 0, time.UTC),
 			},
 			Tags: datatypes.JSONSlice[string]{"golang", "docker", "kubernetes"},
 		},
 		{
-			Model: model.Model{ID: 5},
-			Name:  "Eve",
-			Age:   35,
+			AuditModel: model.AuditModel{Model: model.Model{ID: 5}},
+			Name:       "Eve",
+			Age:        35,
 			BirthDay: &model.LocalTime{
 				Time: time.Date(1989, 6, 30, 0, 0, 0, 0, time.UTC),
 			},